@@ -5,7 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
 	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 )
 
 type ApiCallHandler struct {
@@ -19,12 +21,37 @@ func NewApiCallHandler(apiCallUsecase usecase.IApiCallUsecase) *ApiCallHandler {
 }
 
 func (apiCallHandler *ApiCallHandler) Get(c *gin.Context) {
-	apiCalls, errObject := apiCallHandler.apiCallUsecase.Get()
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	//bind query
+	var query domain.ApiCallQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	// time series of counts when bucketed
+	if query.Bucket != "" {
+		series, err := apiCallHandler.apiCallUsecase.GetSeries(c.Request.Context(), query)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		util.SuccessResponse(c, http.StatusOK, "successfully get api call series", series)
+		return
+	}
+
+	apiCalls, err := apiCallHandler.apiCallUsecase.Get(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	util.SuccessResponse(c, http.StatusOK, "successfully get all api call", apiCalls)
 }
+
+func (apiCallHandler *ApiCallHandler) GetRecorderStats(c *gin.Context) {
+	stats := apiCallHandler.apiCallUsecase.GetRecorderStats()
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get api call recorder stats", stats)
+}
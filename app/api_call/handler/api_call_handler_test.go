@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	api_call_mock "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/mock"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,23 +26,27 @@ var apiCallHandler = NewApiCallHandler(&apiCallUsecaseMock)
 func TestGet(t *testing.T) {
 	t.Run("should can get api calls", func(t *testing.T) {
 		// call mock
-		mockResponse := make(map[string]map[string]int, 0)
-		mockResponse["endpoint1"] = map[string]int{
-			"count":             1,
-			"unique_user_agent": 1,
+		mockResponse := map[string]domain.ApiCallStats{
+			"endpoint1": {
+				Count:           1,
+				UniqueUserAgent: 1,
+				ServerErrorRate: 0.5,
+				LatencyP95:      12.5,
+			},
+			"endpoint2": {
+				Count:           2,
+				UniqueUserAgent: 1,
+			},
 		}
-		mockResponse["endpoint2"] = map[string]int{
-			"count":             2,
-			"unique_user_agent": 1,
-		}
-		mockCall := apiCallUsecaseMock.Mock.On("Get").Return(mockResponse, nil)
+		mockCall := apiCallUsecaseMock.Mock.On("Get", domain.ApiCallQuery{GroupBy: domain.ApiCallGroupByPath}).Return(mockResponse, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
 		engine.GET("/api/api-calls", apiCallHandler.Get)
 
 		response := httptest.NewRecorder()
-		request, err := http.NewRequest("GET", "/api/api-calls", nil)
+		request, err := http.NewRequest("GET", "/api/api-calls?group_by=path", nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -62,27 +69,26 @@ func TestGet(t *testing.T) {
 		assert.Equal(t, len(mockResponse), len(apiCallData), "length should be equal")
 
 		endpoint1Data := apiCallData["endpoint1"].(map[string]any)
-		assert.Equal(t, float64(mockResponse["endpoint1"]["count"]), endpoint1Data["count"], "endpoint1 count should be equal")
-		assert.Equal(t, float64(mockResponse["endpoint1"]["unique_user_agent"]), endpoint1Data["unique_user_agent"], "endpoint1 unique user agent should be equal")
+		assert.Equal(t, float64(mockResponse["endpoint1"].Count), endpoint1Data["count"], "endpoint1 count should be equal")
+		assert.Equal(t, float64(mockResponse["endpoint1"].UniqueUserAgent), endpoint1Data["unique_user_agent"], "endpoint1 unique user agent should be equal")
+		assert.Equal(t, mockResponse["endpoint1"].ServerErrorRate, endpoint1Data["server_error_rate"], "endpoint1 server error rate should be equal")
+		assert.Equal(t, mockResponse["endpoint1"].LatencyP95, endpoint1Data["latency_p95_ms"], "endpoint1 p95 latency should be equal")
 
 		endpoint2Data := apiCallData["endpoint2"].(map[string]any)
-		assert.Equal(t, float64(mockResponse["endpoint2"]["count"]), endpoint2Data["count"], "endpoint2 count should be equal")
-		assert.Equal(t, float64(mockResponse["endpoint2"]["unique_user_agent"]), endpoint2Data["unique_user_agent"], "endpoint2 unique user agent should be equal")
+		assert.Equal(t, float64(mockResponse["endpoint2"].Count), endpoint2Data["count"], "endpoint2 count should be equal")
+		assert.Equal(t, float64(mockResponse["endpoint2"].UniqueUserAgent), endpoint2Data["unique_user_agent"], "endpoint2 unique user agent should be equal")
 
 		mockCall.Unset()
 	})
 
 	t.Run("should reject when usecase call return error", func(t *testing.T) {
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := apiCallUsecaseMock.Mock.On("Get").Return(nil, errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := apiCallUsecaseMock.Mock.On("Get", domain.ApiCallQuery{}).Return(nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
 		engine.GET("/api/api-calls", apiCallHandler.Get)
 
 		response := httptest.NewRecorder()
@@ -101,11 +107,135 @@ func TestGet(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
 
 		mockCall.Unset()
 	})
+
+	t.Run("should reject when group by invalid", func(t *testing.T) {
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.GET("/api/api-calls", apiCallHandler.Get)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/api-calls?group_by=user", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+	})
+
+	t.Run("should can get api call series", func(t *testing.T) {
+		// call mock
+		bucketStart := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		mockResponse := map[string][]domain.ApiCallPoint{
+			"GET /api/farms": {
+				{Time: bucketStart, Count: 3},
+			},
+		}
+		query := domain.ApiCallQuery{Method: "GET", Bucket: "hour"}
+		mockCall := apiCallUsecaseMock.Mock.On("GetSeries", query).Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.GET("/api/api-calls", apiCallHandler.Get)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/api-calls?method=GET&bucket=hour", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully get api call series", responseBody["message"], "message should be equal")
+
+		seriesData := responseBody["data"].(map[string]any)["GET /api/farms"].([]any)
+		assert.Equal(t, 1, len(seriesData), "length should be equal")
+
+		pointData := seriesData[0].(map[string]any)
+		assert.Equal(t, "2024-01-01T10:00:00Z", pointData["time"], "time should be equal")
+		assert.Equal(t, float64(3), pointData["count"], "count should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when bucket invalid", func(t *testing.T) {
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.GET("/api/api-calls", apiCallHandler.Get)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/api-calls?bucket=week", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+	})
+}
+
+func TestGetRecorderStats(t *testing.T) {
+	t.Run("should can get recorder stats", func(t *testing.T) {
+		// call mock
+		mockResponse := domain.ApiCallRecorderStats{
+			Enqueued: 10,
+			Dropped:  2,
+			Flushed:  7,
+			Pending:  1,
+		}
+		mockCall := apiCallUsecaseMock.Mock.On("GetRecorderStats").Return(mockResponse)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.GET("/api/api-calls/recorder", apiCallHandler.GetRecorderStats)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/api-calls/recorder", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully get api call recorder stats", responseBody["message"], "message should be equal")
+
+		statsData := responseBody["data"].(map[string]any)
+		assert.Equal(t, float64(mockResponse.Dropped), statsData["dropped"], "dropped should be equal")
+		assert.Equal(t, float64(mockResponse.Flushed), statsData["flushed"], "flushed should be equal")
+
+		mockCall.Unset()
+	})
 }
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ApiCallRecorderMock struct {
+	Mock mock.Mock
+}
+
+func (apiCallRecorderMock *ApiCallRecorderMock) Record(apiCall domain.ApiCall) bool {
+	args := apiCallRecorderMock.Mock.Called(apiCall)
+
+	return args[0].(bool)
+}
+
+func (apiCallRecorderMock *ApiCallRecorderMock) Stats() domain.ApiCallRecorderStats {
+	args := apiCallRecorderMock.Mock.Called()
+
+	return args[0].(domain.ApiCallRecorderStats)
+}
+
+func (apiCallRecorderMock *ApiCallRecorderMock) Health() error {
+	args := apiCallRecorderMock.Mock.Called()
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (apiCallRecorderMock *ApiCallRecorderMock) Close(ctx context.Context) error {
+	args := apiCallRecorderMock.Mock.Called(ctx)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package mock
 
 import (
+	"context"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,7 +12,17 @@ type ApiCallRepositoryMock struct {
 	Mock mock.Mock
 }
 
-func (apiCallRepositoryMock *ApiCallRepositoryMock) GetApiCalls(apiCalls *[]domain.ApiCallResponse) error {
+func (apiCallRepositoryMock *ApiCallRepositoryMock) GetApiCalls(ctx context.Context, apiCalls *[]domain.ApiCallResponse, query domain.ApiCallQuery) error {
+	args := apiCallRepositoryMock.Mock.Called(apiCalls, query)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (apiCallRepositoryMock *ApiCallRepositoryMock) CreateApiCalls(ctx context.Context, apiCalls []domain.ApiCall) error {
 	args := apiCallRepositoryMock.Mock.Called(apiCalls)
 
 	if args[0] != nil {
@@ -18,3 +31,33 @@ func (apiCallRepositoryMock *ApiCallRepositoryMock) GetApiCalls(apiCalls *[]doma
 
 	return nil
 }
+
+func (apiCallRepositoryMock *ApiCallRepositoryMock) GetApiCallBuckets(ctx context.Context, buckets *[]domain.ApiCallBucket, query domain.ApiCallQuery) error {
+	args := apiCallRepositoryMock.Mock.Called(buckets, query)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (apiCallRepositoryMock *ApiCallRepositoryMock) RollupApiCalls(ctx context.Context, before time.Time) (int64, error) {
+	args := apiCallRepositoryMock.Mock.Called(before)
+
+	if args[1] != nil {
+		return 0, args[1].(error)
+	}
+
+	return args[0].(int64), nil
+}
+
+func (apiCallRepositoryMock *ApiCallRepositoryMock) RollupHourlyApiCalls(ctx context.Context, before time.Time) (int64, error) {
+	args := apiCallRepositoryMock.Mock.Called(before)
+
+	if args[1] != nil {
+		return 0, args[1].(error)
+	}
+
+	return args[0].(int64), nil
+}
@@ -1,7 +1,9 @@
 package mock
 
 import (
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,12 +11,28 @@ type ApiCallUsecaseMock struct {
 	Mock mock.Mock
 }
 
-func (apiCallUsecaseMock *ApiCallUsecaseMock) Get() (map[string]map[string]int, any) {
-	args := apiCallUsecaseMock.Mock.Called()
+func (apiCallUsecaseMock *ApiCallUsecaseMock) Get(ctx context.Context, query domain.ApiCallQuery) (map[string]domain.ApiCallStats, error) {
+	args := apiCallUsecaseMock.Mock.Called(query)
+
+	if args[1] != nil {
+		return nil, args[1].(error)
+	}
+
+	return args[0].(map[string]domain.ApiCallStats), nil
+}
+
+func (apiCallUsecaseMock *ApiCallUsecaseMock) GetSeries(ctx context.Context, query domain.ApiCallQuery) (map[string][]domain.ApiCallPoint, error) {
+	args := apiCallUsecaseMock.Mock.Called(query)
 
 	if args[1] != nil {
-		return nil, args[1].(util.ErrorObject)
+		return nil, args[1].(error)
 	}
 
-	return args[0].(map[string]map[string]int), nil
+	return args[0].(map[string][]domain.ApiCallPoint), nil
+}
+
+func (apiCallUsecaseMock *ApiCallUsecaseMock) GetRecorderStats() domain.ApiCallRecorderStats {
+	args := apiCallUsecaseMock.Mock.Called()
+
+	return args[0].(domain.ApiCallRecorderStats)
 }
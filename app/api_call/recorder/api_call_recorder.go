@@ -0,0 +1,257 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+)
+
+type IApiCallRecorder interface {
+	Record(apiCall domain.ApiCall) bool
+	Stats() domain.ApiCallRecorderStats
+	Health() error
+	Close(ctx context.Context) error
+}
+
+// Defaults for unset or invalid config values
+const (
+	DefaultBufferSize    = 1024
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+	DefaultFlushTimeout  = 10 * time.Second
+)
+
+type Config struct {
+	// records buffered before new ones are dropped
+	BufferSize int
+	// records written per insert
+	BatchSize int
+	// longest time a record waits in a partial batch
+	FlushInterval time.Duration
+	// how long Record waits for room in a full buffer, zero drops at once
+	EnqueueTimeout time.Duration
+	// longest time writing one batch may take, retries included
+	FlushTimeout time.Duration
+}
+
+// ApiCallRecorder buffers api calls in a bounded channel and writes them in
+// batches from a background worker, so requests never wait on the database
+type ApiCallRecorder struct {
+	apiCallRepository repository.IApiCallRepository
+	config            Config
+	apiCalls          chan domain.ApiCall
+	done              chan struct{}
+
+	// guards apiCalls against a send after close
+	mu       sync.RWMutex
+	isClosed bool
+
+	// error of the latest flush, nil once a flush succeeds again
+	errMu    sync.Mutex
+	flushErr error
+
+	enqueued atomic.Uint64
+	dropped  atomic.Uint64
+	flushed  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+func NewApiCallRecorder(apiCallRepository repository.IApiCallRepository, config Config) IApiCallRecorder {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = DefaultFlushTimeout
+	}
+
+	apiCallRecorder := &ApiCallRecorder{
+		apiCallRepository: apiCallRepository,
+		config:            config,
+		apiCalls:          make(chan domain.ApiCall, config.BufferSize),
+		done:              make(chan struct{}),
+	}
+
+	go apiCallRecorder.work()
+
+	return apiCallRecorder
+}
+
+// Record enqueues the api call and reports false when it was dropped
+func (apiCallRecorder *ApiCallRecorder) Record(apiCall domain.ApiCall) bool {
+	apiCallRecorder.mu.RLock()
+	defer apiCallRecorder.mu.RUnlock()
+
+	if apiCallRecorder.isClosed {
+		apiCallRecorder.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case apiCallRecorder.apiCalls <- apiCall:
+		apiCallRecorder.enqueued.Add(1)
+		return true
+	default:
+	}
+
+	// buffer is full, wait for the worker when backpressure is allowed
+	if apiCallRecorder.config.EnqueueTimeout > 0 {
+		timer := time.NewTimer(apiCallRecorder.config.EnqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case apiCallRecorder.apiCalls <- apiCall:
+			apiCallRecorder.enqueued.Add(1)
+			return true
+		case <-timer.C:
+		}
+	}
+
+	apiCallRecorder.dropped.Add(1)
+	return false
+}
+
+func (apiCallRecorder *ApiCallRecorder) Stats() domain.ApiCallRecorderStats {
+	return domain.ApiCallRecorderStats{
+		Enqueued: apiCallRecorder.enqueued.Load(),
+		Dropped:  apiCallRecorder.dropped.Load(),
+		Flushed:  apiCallRecorder.flushed.Load(),
+		Failed:   apiCallRecorder.failed.Load(),
+		Pending:  len(apiCallRecorder.apiCalls),
+	}
+}
+
+// Health fails once the recorder is closed or while its latest flush failed
+func (apiCallRecorder *ApiCallRecorder) Health() error {
+	apiCallRecorder.mu.RLock()
+	isClosed := apiCallRecorder.isClosed
+	apiCallRecorder.mu.RUnlock()
+	if isClosed {
+		return errors.New("api call recorder is closed")
+	}
+
+	apiCallRecorder.errMu.Lock()
+	defer apiCallRecorder.errMu.Unlock()
+	if apiCallRecorder.flushErr != nil {
+		return fmt.Errorf("latest flush failed: %w", apiCallRecorder.flushErr)
+	}
+
+	return nil
+}
+
+// Close stops accepting records and waits until the buffered ones are flushed
+// or ctx is done
+func (apiCallRecorder *ApiCallRecorder) Close(ctx context.Context) error {
+	apiCallRecorder.mu.Lock()
+	if !apiCallRecorder.isClosed {
+		apiCallRecorder.isClosed = true
+		close(apiCallRecorder.apiCalls)
+	}
+	apiCallRecorder.mu.Unlock()
+
+	select {
+	case <-apiCallRecorder.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (apiCallRecorder *ApiCallRecorder) work() {
+	defer close(apiCallRecorder.done)
+
+	ticker := time.NewTicker(apiCallRecorder.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.ApiCall, 0, apiCallRecorder.config.BatchSize)
+	for {
+		select {
+		case apiCall, isOpen := <-apiCallRecorder.apiCalls:
+			if !isOpen {
+				apiCallRecorder.flush(batch)
+				return
+			}
+
+			batch = append(batch, apiCall)
+			if len(batch) >= apiCallRecorder.config.BatchSize {
+				apiCallRecorder.flush(batch)
+				batch = make([]domain.ApiCall, 0, apiCallRecorder.config.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				apiCallRecorder.flush(batch)
+				batch = make([]domain.ApiCall, 0, apiCallRecorder.config.BatchSize)
+			}
+		}
+	}
+}
+
+// flush writes the batch, retrying once, then row by row so one row the
+// database rejects doesn't drop the whole batch. A hanging database fails the
+// batch after the flush timeout instead of blocking the worker.
+func (apiCallRecorder *ApiCallRecorder) flush(batch []domain.ApiCall) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiCallRecorder.config.FlushTimeout)
+	defer cancel()
+
+	err := apiCallRecorder.apiCallRepository.CreateApiCalls(ctx, batch)
+	if err != nil {
+		err = apiCallRecorder.apiCallRepository.CreateApiCalls(ctx, batch)
+	}
+
+	failed := 0
+	if err != nil {
+		failed = len(batch)
+		if len(batch) > 1 {
+			failed = apiCallRecorder.flushRows(ctx, batch)
+		}
+	}
+
+	// healthy as long as some rows still get written
+	apiCallRecorder.errMu.Lock()
+	if failed < len(batch) {
+		apiCallRecorder.flushErr = nil
+	} else {
+		apiCallRecorder.flushErr = err
+	}
+	apiCallRecorder.errMu.Unlock()
+
+	if failed > 0 {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"COUNT": failed,
+			"ERROR": err.Error(),
+		}).Error("Failed to record api calls")
+	}
+
+	apiCallRecorder.failed.Add(uint64(failed))
+	apiCallRecorder.flushed.Add(uint64(len(batch) - failed))
+}
+
+// flushRows writes the batch one row at a time and returns how many failed
+func (apiCallRecorder *ApiCallRecorder) flushRows(ctx context.Context, batch []domain.ApiCall) int {
+	failed := 0
+	for _, apiCall := range batch {
+		err := apiCallRecorder.apiCallRepository.CreateApiCalls(ctx, []domain.ApiCall{apiCall})
+		if err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
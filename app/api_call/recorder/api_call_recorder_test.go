@@ -0,0 +1,254 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api_call_mock "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var apiCall = domain.ApiCall{
+	Endpoint: "/api/farms",
+	Method:   "GET",
+	IpAdress: "127.0.0.1",
+}
+
+// mock CreateApiCalls to report every batch on flushed and wait for release
+func mockBlockingFlush(apiCallRepositoryMock *api_call_mock.ApiCallRepositoryMock) (chan []domain.ApiCall, chan struct{}) {
+	flushed := make(chan []domain.ApiCall, 10)
+	release := make(chan struct{})
+	apiCallRepositoryMock.Mock.On("CreateApiCalls", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		flushed <- args[0].([]domain.ApiCall)
+		<-release
+	})
+
+	return flushed, release
+}
+
+// hangingRepository blocks every write until its context is done, like a
+// database that stopped answering
+type hangingRepository struct {
+	api_call_mock.ApiCallRepositoryMock
+}
+
+func (hangingRepository *hangingRepository) CreateApiCalls(ctx context.Context, apiCalls []domain.ApiCall) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("should flush when batch is full", func(t *testing.T) {
+		// prepare recorder
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		flushed, release := mockBlockingFlush(&apiCallRepositoryMock)
+		close(release)
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 2, FlushInterval: time.Hour})
+
+		// record api calls
+		assert.True(t, apiCallRecorder.Record(apiCall), "api call should be enqueued")
+		assert.True(t, apiCallRecorder.Record(apiCall), "api call should be enqueued")
+
+		//test flush
+		select {
+		case batch := <-flushed:
+			assert.Len(t, batch, 2, "batch length should be equal")
+		case <-time.After(time.Second):
+			t.Fatal("batch was not flushed")
+		}
+
+		assert.Nil(t, apiCallRecorder.Close(context.Background()), "close should succeed")
+		assert.Equal(t, uint64(2), apiCallRecorder.Stats().Flushed, "flushed should be equal")
+	})
+
+	t.Run("should flush partial batch on interval", func(t *testing.T) {
+		// prepare recorder
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		flushed, release := mockBlockingFlush(&apiCallRepositoryMock)
+		close(release)
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+
+		// record api call
+		apiCallRecorder.Record(apiCall)
+
+		//test flush
+		select {
+		case batch := <-flushed:
+			assert.Len(t, batch, 1, "batch length should be equal")
+		case <-time.After(time.Second):
+			t.Fatal("batch was not flushed")
+		}
+
+		apiCallRecorder.Close(context.Background())
+	})
+
+	t.Run("should drop when buffer is full", func(t *testing.T) {
+		// prepare recorder with the worker stuck in a flush
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		flushed, release := mockBlockingFlush(&apiCallRepositoryMock)
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+		apiCallRecorder.Record(apiCall)
+		<-flushed
+
+		// record api calls
+		assert.True(t, apiCallRecorder.Record(apiCall), "api call should fill the buffer")
+		assert.False(t, apiCallRecorder.Record(apiCall), "api call should be dropped")
+
+		//test stats
+		stats := apiCallRecorder.Stats()
+		assert.Equal(t, uint64(2), stats.Enqueued, "enqueued should be equal")
+		assert.Equal(t, uint64(1), stats.Dropped, "dropped should be equal")
+		assert.Equal(t, 1, stats.Pending, "pending should be equal")
+
+		close(release)
+		assert.Nil(t, apiCallRecorder.Close(context.Background()), "close should succeed")
+		assert.Equal(t, uint64(2), apiCallRecorder.Stats().Flushed, "buffered api call should be flushed on close")
+	})
+
+	t.Run("should wait for room when enqueue timeout is set", func(t *testing.T) {
+		// prepare recorder with the worker stuck in a flush
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		flushed, release := mockBlockingFlush(&apiCallRepositoryMock)
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 1, BatchSize: 1, FlushInterval: time.Hour, EnqueueTimeout: time.Second})
+		apiCallRecorder.Record(apiCall)
+		<-flushed
+		apiCallRecorder.Record(apiCall)
+
+		// record api call once the worker catches up
+		isRecorded := make(chan bool)
+		go func() {
+			isRecorded <- apiCallRecorder.Record(apiCall)
+		}()
+		close(release)
+
+		//test record
+		assert.True(t, <-isRecorded, "api call should be enqueued")
+		assert.Equal(t, uint64(0), apiCallRecorder.Stats().Dropped, "dropped should be equal")
+
+		apiCallRecorder.Close(context.Background())
+	})
+
+	t.Run("should count failed flush", func(t *testing.T) {
+		// prepare recorder
+		infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", mock.Anything).Return(errors.New("connection refused"))
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour})
+
+		// record api calls and flush on close
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Close(context.Background())
+
+		//test stats
+		stats := apiCallRecorder.Stats()
+		assert.Equal(t, uint64(2), stats.Failed, "failed should be equal")
+		assert.Equal(t, uint64(0), stats.Flushed, "flushed should be equal")
+	})
+
+	t.Run("should retry failed batch once", func(t *testing.T) {
+		// prepare recorder
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", mock.Anything).Return(errors.New("connection reset")).Once()
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", mock.Anything).Return(nil).Once()
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour})
+
+		// record api calls and flush on close
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Close(context.Background())
+
+		//test stats
+		stats := apiCallRecorder.Stats()
+		assert.Equal(t, uint64(2), stats.Flushed, "flushed should be equal")
+		assert.Equal(t, uint64(0), stats.Failed, "failed should be equal")
+		apiCallRepositoryMock.Mock.AssertNumberOfCalls(t, "CreateApiCalls", 2)
+	})
+
+	t.Run("should write rows one by one when retry fails", func(t *testing.T) {
+		// prepare recorder
+		infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+		rejected := domain.ApiCall{Endpoint: "/api/farms/\xff", Method: "GET"}
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", []domain.ApiCall{apiCall, rejected}).Return(errors.New("invalid byte sequence"))
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", []domain.ApiCall{apiCall}).Return(nil)
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", []domain.ApiCall{rejected}).Return(errors.New("invalid byte sequence"))
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour})
+
+		// record api calls and flush on close
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Record(rejected)
+		apiCallRecorder.Close(context.Background())
+
+		//test stats
+		stats := apiCallRecorder.Stats()
+		assert.Equal(t, uint64(1), stats.Flushed, "flushed should be equal")
+		assert.Equal(t, uint64(1), stats.Failed, "failed should be equal")
+		apiCallRepositoryMock.Mock.AssertNumberOfCalls(t, "CreateApiCalls", 4)
+	})
+
+	t.Run("should fail hanging batch after flush timeout", func(t *testing.T) {
+		// prepare recorder
+		infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+		apiCallRecorder := NewApiCallRecorder(&hangingRepository{}, Config{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour, FlushTimeout: 20 * time.Millisecond})
+
+		// record api calls and flush on close
+		apiCallRecorder.Record(apiCall)
+		apiCallRecorder.Record(apiCall)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := apiCallRecorder.Close(ctx)
+
+		//test stats
+		assert.Nil(t, err, "close should not wait for the database")
+		stats := apiCallRecorder.Stats()
+		assert.Equal(t, uint64(2), stats.Failed, "failed should be equal")
+		assert.Equal(t, uint64(0), stats.Flushed, "flushed should be equal")
+	})
+
+	t.Run("should drop after close", func(t *testing.T) {
+		// prepare recorder
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{})
+		apiCallRecorder.Close(context.Background())
+
+		//test record
+		assert.False(t, apiCallRecorder.Record(apiCall), "api call should be dropped")
+		assert.Equal(t, uint64(1), apiCallRecorder.Stats().Dropped, "dropped should be equal")
+	})
+}
+
+func TestHealth(t *testing.T) {
+	t.Run("should fail while the latest flush failed", func(t *testing.T) {
+		// prepare recorder
+		infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRepositoryMock.Mock.On("CreateApiCalls", mock.Anything).Return(errors.New("connection refused"))
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{BufferSize: 10, BatchSize: 1, FlushInterval: time.Hour})
+		defer apiCallRecorder.Close(context.Background())
+
+		//test health before and after a failed flush
+		assert.Nil(t, apiCallRecorder.Health(), "error should be nil")
+
+		apiCallRecorder.Record(apiCall)
+		assert.Eventually(t, func() bool {
+			return apiCallRecorder.Stats().Failed == 1
+		}, time.Second, 10*time.Millisecond, "flush should fail")
+		assert.ErrorContains(t, apiCallRecorder.Health(), "connection refused", "error should contain the flush error")
+	})
+
+	t.Run("should fail after close", func(t *testing.T) {
+		// prepare recorder
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRecorder := NewApiCallRecorder(&apiCallRepositoryMock, Config{})
+		apiCallRecorder.Close(context.Background())
+
+		//test health
+		assert.NotNil(t, apiCallRecorder.Health(), "error should not be nil")
+	})
+}
@@ -1,12 +1,22 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
 	"gorm.io/gorm"
 )
 
 type IApiCallRepository interface {
-	GetApiCalls(apiCalls *[]domain.ApiCallResponse) error
+	GetApiCalls(ctx context.Context, apiCalls *[]domain.ApiCallResponse, query domain.ApiCallQuery) error
+	GetApiCallBuckets(ctx context.Context, buckets *[]domain.ApiCallBucket, query domain.ApiCallQuery) error
+	CreateApiCalls(ctx context.Context, apiCalls []domain.ApiCall) error
+	RollupApiCalls(ctx context.Context, before time.Time) (int64, error)
+	RollupHourlyApiCalls(ctx context.Context, before time.Time) (int64, error)
 }
 
 type ApiCallRepository struct {
@@ -19,13 +29,289 @@ func NewApiCallRepository(db *gorm.DB) IApiCallRepository {
 	}
 }
 
-func (apiCallRepository *ApiCallRepository) GetApiCalls(apiCalls *[]domain.ApiCallResponse) error {
-	query := `
-	select endpoint, method, count(ip_adress) as count, count(distinct ip_adress) as unique_user_agent 
-	from api_calls 
-	GROUP BY endpoint, method
-	`
+// Calls that matched no route collapse into one group with empty method and
+// endpoint. Calls recorded before routes were tracked have no status and fall
+// back to their raw path.
+const (
+	routeMethod   = "CASE WHEN route = '' AND status_code > 0 THEN '' ELSE method END"
+	routeEndpoint = "CASE WHEN route <> '' THEN route WHEN status_code = 0 THEN endpoint ELSE '' END"
+)
+
+// Aggregates over apiCallRows. Rollups carry no user agent or latency, so
+// unique_user_agent and the percentiles only cover calls not rolled up yet,
+// from the raw_from filled by fillRawFrom on.
+const apiCallAggregates = `sum(calls) AS count,
+	sum(CASE WHEN status_code > 0 THEN calls ELSE 0 END) AS recorded_count,
+	count(DISTINCT CASE WHEN user_agent <> '' THEN user_agent END) AS unique_user_agent,
+	count(DISTINCT ip_adress) AS unique_ip,
+	sum(CASE WHEN status_code BETWEEN 400 AND 499 THEN calls ELSE 0 END) AS client_error_count,
+	sum(CASE WHEN status_code >= 500 THEN calls ELSE 0 END) AS server_error_count`
+
+// SQLite has no percentile_cont, there the percentiles are interpolated the
+// same way from the latencies by latencyPercentiles
+const postgresLatencyPercentiles = `,
+	COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency) FILTER (WHERE status_code > 0), 0) AS latency_p50,
+	COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency) FILTER (WHERE status_code > 0), 0) AS latency_p95,
+	COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY latency) FILTER (WHERE status_code > 0), 0) AS latency_p99`
+
+// Raw calls rolled into hourly rollups, counts of a bucket already rolled up are added
+const rollupApiCalls = `INSERT INTO api_call_rollups (granularity, bucket_start, endpoint, route, method, ip_adress, status_code, count)
+	SELECT ?, %[1]s, endpoint, route, method, ip_adress, status_code, count(*)
+	FROM api_calls WHERE created_at < ?
+	GROUP BY %[1]s, endpoint, route, method, ip_adress, status_code
+	ON CONFLICT (granularity, bucket_start, endpoint, route, method, ip_adress, status_code)
+	DO UPDATE SET count = api_call_rollups.count + EXCLUDED.count`
+
+// Hourly rollups rolled into daily rollups
+const rollupHourlyApiCalls = `INSERT INTO api_call_rollups (granularity, bucket_start, endpoint, route, method, ip_adress, status_code, count)
+	SELECT ?, %[1]s, endpoint, route, method, ip_adress, status_code, sum(count)
+	FROM api_call_rollups WHERE granularity = ? AND bucket_start < ?
+	GROUP BY %[1]s, endpoint, route, method, ip_adress, status_code
+	ON CONFLICT (granularity, bucket_start, endpoint, route, method, ip_adress, status_code)
+	DO UPDATE SET count = api_call_rollups.count + EXCLUDED.count`
+
+// Truncation unit of each bucket, the query only accepts these keys
+var bucketUnits = map[string]string{
+	"minute": "minute",
+	"hour":   "hour",
+	"day":    "day",
+}
+
+func (apiCallRepository *ApiCallRepository) GetApiCalls(ctx context.Context, apiCalls *[]domain.ApiCallResponse, query domain.ApiCallQuery) error {
+	method, endpoint := groupColumns(query)
+
+	aggregates := apiCallAggregates
+	if !util.IsSQLite(apiCallRepository.db) {
+		aggregates += postgresLatencyPercentiles
+	}
+
+	err := filterApiCalls(apiCallRepository.apiCallRows(ctx), query).
+		Select(fmt.Sprintf("%s AS method, %s AS endpoint, %s", method, endpoint, aggregates)).
+		Group(method).
+		Group(endpoint).
+		Scan(apiCalls).Error
+	if err != nil {
+		return err
+	}
+
+	err = apiCallRepository.fillRawFrom(ctx, *apiCalls, query)
+	if err != nil || !util.IsSQLite(apiCallRepository.db) {
+		return err
+	}
+
+	return apiCallRepository.fillLatencyPercentiles(ctx, *apiCalls, query)
+}
+
+func (apiCallRepository *ApiCallRepository) GetApiCallBuckets(ctx context.Context, buckets *[]domain.ApiCallBucket, query domain.ApiCallQuery) error {
+	method, endpoint := groupColumns(query)
+	bucketStart := util.TruncateTime(apiCallRepository.db, bucketUnits[query.Bucket], "created_at")
+
+	var rows []struct {
+		Method      string
+		Endpoint    string
+		BucketStart util.ScannedTime
+		Count       int
+	}
+	err := filterApiCalls(apiCallRepository.apiCallRows(ctx), query).
+		Select(fmt.Sprintf("%s AS method, %s AS endpoint, %s AS bucket_start, sum(calls) AS count", method, endpoint, bucketStart)).
+		Group(method).
+		Group(endpoint).
+		Group(bucketStart).
+		Order(bucketStart).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	*buckets = make([]domain.ApiCallBucket, 0, len(rows))
+	for _, row := range rows {
+		*buckets = append(*buckets, domain.ApiCallBucket{
+			Method:      row.Method,
+			Endpoint:    row.Endpoint,
+			BucketStart: row.BucketStart.Time,
+			Count:       row.Count,
+		})
+	}
+
+	return nil
+}
+
+func (apiCallRepository *ApiCallRepository) CreateApiCalls(ctx context.Context, apiCalls []domain.ApiCall) error {
+	tx := apiCallRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Create(&apiCalls).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (apiCallRepository *ApiCallRepository) RollupApiCalls(ctx context.Context, before time.Time) (int64, error) {
+	tx := apiCallRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Exec(fmt.Sprintf(rollupApiCalls, util.TruncateTime(tx, "hour", "created_at")), domain.ApiCallRollupHour, before).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	result := tx.Where("created_at < ?", before).Delete(&domain.ApiCall{})
+	if result.Error != nil {
+		tx.Rollback()
+		return 0, result.Error
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (apiCallRepository *ApiCallRepository) RollupHourlyApiCalls(ctx context.Context, before time.Time) (int64, error) {
+	tx := apiCallRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Exec(fmt.Sprintf(rollupHourlyApiCalls, util.TruncateTime(tx, "day", "bucket_start")), domain.ApiCallRollupDay, domain.ApiCallRollupHour, before).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	result := tx.Where("granularity = ? AND bucket_start < ?", domain.ApiCallRollupHour, before).Delete(&domain.ApiCallRollup{})
+	if result.Error != nil {
+		tx.Rollback()
+		return 0, result.Error
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Raw calls and rollups as one set of rows named api_calls where each row
+// stands for calls api calls. Rollups are dated by the start of their bucket.
+func (apiCallRepository *ApiCallRepository) apiCallRows(ctx context.Context) *gorm.DB {
+	raw := apiCallRepository.db.WithContext(ctx).Model(&domain.ApiCall{}).
+		Select("endpoint, route, method, ip_adress, status_code, user_agent, latency, created_at, 1 AS calls")
+	rollups := apiCallRepository.db.WithContext(ctx).Model(&domain.ApiCallRollup{}).
+		Select("endpoint, route, method, ip_adress, status_code, '' AS user_agent, NULL AS latency, bucket_start AS created_at, count AS calls")
+
+	return apiCallRepository.db.WithContext(ctx).Table("(? UNION ALL ?) AS api_calls", raw, rollups)
+}
+
+// fillRawFrom sets the earliest raw call of every group of apiCalls, groups
+// rolled up entirely keep a nil RawFrom
+func (apiCallRepository *ApiCallRepository) fillRawFrom(ctx context.Context, apiCalls []domain.ApiCallResponse, query domain.ApiCallQuery) error {
+	method, endpoint := groupColumns(query)
+
+	var rows []struct {
+		Method   string
+		Endpoint string
+		RawFrom  util.ScannedTime
+	}
+	err := filterApiCalls(apiCallRepository.db.WithContext(ctx).Model(&domain.ApiCall{}), query).
+		Select(fmt.Sprintf("%s AS method, %s AS endpoint, min(created_at) AS raw_from", method, endpoint)).
+		Group(method).
+		Group(endpoint).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	rawFrom := make(map[[2]string]time.Time, len(rows))
+	for _, row := range rows {
+		rawFrom[[2]string{row.Method, row.Endpoint}] = row.RawFrom.Time
+	}
+
+	for i := range apiCalls {
+		from, ok := rawFrom[[2]string{apiCalls[i].Method, apiCalls[i].Endpoint}]
+		if ok {
+			apiCalls[i].RawFrom = &from
+		}
+	}
+
+	return nil
+}
+
+// fillLatencyPercentiles sets the latency percentiles of every group of
+// apiCalls from the latencies of its recorded calls
+func (apiCallRepository *ApiCallRepository) fillLatencyPercentiles(ctx context.Context, apiCalls []domain.ApiCallResponse, query domain.ApiCallQuery) error {
+	method, endpoint := groupColumns(query)
+
+	var rows []struct {
+		Method   string
+		Endpoint string
+		Latency  float64
+	}
+	err := filterApiCalls(apiCallRepository.apiCallRows(ctx), query).
+		Select(fmt.Sprintf("%s AS method, %s AS endpoint, latency", method, endpoint)).
+		Where("status_code > 0 AND latency IS NOT NULL").
+		Order("latency").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	latencies := make(map[[2]string][]float64)
+	for _, row := range rows {
+		key := [2]string{row.Method, row.Endpoint}
+		latencies[key] = append(latencies[key], row.Latency)
+	}
+
+	for i := range apiCalls {
+		sorted := latencies[[2]string{apiCalls[i].Method, apiCalls[i].Endpoint}]
+		apiCalls[i].LatencyP50 = percentile(sorted, 0.5)
+		apiCalls[i].LatencyP95 = percentile(sorted, 0.95)
+		apiCalls[i].LatencyP99 = percentile(sorted, 0.99)
+	}
+
+	return nil
+}
+
+// percentile interpolates between the closest ranks of sorted like
+// percentile_cont, zero without values
+func percentile(sorted []float64, fraction float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	position := fraction * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func groupColumns(query domain.ApiCallQuery) (string, string) {
+	if query.GroupBy == domain.ApiCallGroupByPath {
+		return "method", "endpoint"
+	}
+
+	return routeMethod, routeEndpoint
+}
+
+func filterApiCalls(db *gorm.DB, query domain.ApiCallQuery) *gorm.DB {
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at <= ?", query.To)
+	}
+	if query.Method != "" {
+		db = db.Where("method = ?", query.Method)
+	}
+	if query.Endpoint != "" {
+		db = db.Where("route = ? OR endpoint = ?", query.Endpoint, query.Endpoint)
+	}
+	if query.Ip != "" {
+		db = db.Where("ip_adress = ?", query.Ip)
+	}
 
-	err := apiCallRepository.db.Model(&domain.ApiCall{}).Raw(query).Scan(apiCalls).Error
-	return err
+	return db
 }
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database/databasetest"
+	"github.com/stretchr/testify/assert"
+)
+
+var recordedAt = time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+
+// calls of one route spread over two hours, and one call matching no route
+func newApiCalls() []domain.ApiCall {
+	call := func(statusCode int, latency float64, userAgent string, ip string, createdAt time.Time) domain.ApiCall {
+		return domain.ApiCall{
+			Endpoint:   "/api/farms/farm-1",
+			Route:      "/api/farms/:farmId",
+			Method:     "GET",
+			IpAdress:   ip,
+			StatusCode: statusCode,
+			Latency:    latency,
+			UserAgent:  userAgent,
+			CreatedAt:  createdAt,
+		}
+	}
+
+	return []domain.ApiCall{
+		call(200, 10, "agent-a", "10.0.0.1", recordedAt),
+		call(200, 20, "agent-a", "10.0.0.1", recordedAt.Add(10*time.Minute)),
+		call(404, 30, "agent-b", "10.0.0.2", recordedAt.Add(time.Hour)),
+		call(500, 40, "", "10.0.0.2", recordedAt.Add(time.Hour)),
+		{
+			Endpoint:   "/api/unknown",
+			Method:     "POST",
+			IpAdress:   "10.0.0.3",
+			StatusCode: 404,
+			Latency:    5,
+			CreatedAt:  recordedAt,
+		},
+	}
+}
+
+// totals of every route, keyed by endpoint
+func getApiCalls(t *testing.T, apiCallRepository IApiCallRepository) map[string]domain.ApiCallResponse {
+	var apiCalls []domain.ApiCallResponse
+	err := apiCallRepository.GetApiCalls(context.Background(), &apiCalls, domain.ApiCallQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byEndpoint := make(map[string]domain.ApiCallResponse, len(apiCalls))
+	for _, apiCall := range apiCalls {
+		byEndpoint[apiCall.Endpoint] = apiCall
+	}
+
+	return byEndpoint
+}
+
+func TestGetApiCalls(t *testing.T) {
+	t.Run("should aggregate calls by route", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		err := apiCallRepository.CreateApiCalls(context.Background(), newApiCalls())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// get totals
+		apiCalls := getApiCalls(t, apiCallRepository)
+
+		//test aggregates
+		assert.Len(t, apiCalls, 2, "calls should be grouped by route")
+
+		route := apiCalls["/api/farms/:farmId"]
+		assert.Equal(t, "GET", route.Method, "method should be equal")
+		assert.Equal(t, 4, route.Count, "count should be equal")
+		assert.Equal(t, 4, route.RecordedCount, "recorded count should be equal")
+		assert.Equal(t, 2, route.UniqueUserAgent, "empty user agent should not be counted")
+		assert.Equal(t, 2, route.UniqueIp, "unique ip should be equal")
+		assert.Equal(t, 1, route.ClientErrorCount, "client error count should be equal")
+		assert.Equal(t, 1, route.ServerErrorCount, "server error count should be equal")
+		assert.InDelta(t, 25, route.LatencyP50, 0.001, "p50 should be interpolated")
+		assert.InDelta(t, 38.5, route.LatencyP95, 0.001, "p95 should be interpolated")
+		assert.InDelta(t, 39.7, route.LatencyP99, 0.001, "p99 should be interpolated")
+		if assert.NotNil(t, route.RawFrom, "raw from should be set") {
+			assert.True(t, recordedAt.Equal(*route.RawFrom), "raw from should be the earliest call")
+		}
+
+		unmatched := apiCalls[""]
+		assert.Equal(t, "", unmatched.Method, "unmatched calls should have no method")
+		assert.Equal(t, 1, unmatched.Count, "count should be equal")
+	})
+
+	t.Run("should filter calls by time and method", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		err := apiCallRepository.CreateApiCalls(context.Background(), newApiCalls())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// get totals of the first hour
+		var apiCalls []domain.ApiCallResponse
+		err = apiCallRepository.GetApiCalls(context.Background(), &apiCalls, domain.ApiCallQuery{
+			GroupBy: domain.ApiCallGroupByPath,
+			From:    recordedAt,
+			To:      recordedAt.Add(30 * time.Minute),
+			Method:  "GET",
+		})
+
+		//test filters
+		assert.Nil(t, err, "error should be nil")
+		if assert.Len(t, apiCalls, 1) {
+			assert.Equal(t, "/api/farms/farm-1", apiCalls[0].Endpoint, "calls should be grouped by path")
+			assert.Equal(t, 2, apiCalls[0].Count, "count should be equal")
+		}
+	})
+}
+
+func TestGetApiCallBuckets(t *testing.T) {
+	t.Run("should count calls per hour", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		err := apiCallRepository.CreateApiCalls(context.Background(), newApiCalls())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// get hourly buckets of the route
+		var buckets []domain.ApiCallBucket
+		err = apiCallRepository.GetApiCallBuckets(context.Background(), &buckets, domain.ApiCallQuery{
+			Endpoint: "/api/farms/:farmId",
+			Bucket:   "hour",
+		})
+
+		//test buckets
+		assert.Nil(t, err, "error should be nil")
+		if assert.Len(t, buckets, 2) {
+			hour := recordedAt.Truncate(time.Hour)
+			assert.True(t, hour.Equal(buckets[0].BucketStart), "bucket should start at the hour")
+			assert.Equal(t, 2, buckets[0].Count, "count should be equal")
+			assert.True(t, hour.Add(time.Hour).Equal(buckets[1].BucketStart), "bucket should start at the next hour")
+			assert.Equal(t, 2, buckets[1].Count, "count should be equal")
+		}
+	})
+}
+
+func TestRollupApiCalls(t *testing.T) {
+	t.Run("should keep totals when rolling calls up", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		err := apiCallRepository.CreateApiCalls(context.Background(), newApiCalls())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// roll up the calls of the first hour
+		rolledUp, err := apiCallRepository.RollupApiCalls(context.Background(), recordedAt.Add(time.Hour))
+		apiCalls := getApiCalls(t, apiCallRepository)
+
+		//test rollup
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(3), rolledUp, "calls of the first hour should be rolled up")
+
+		var raw int64
+		db.Model(&domain.ApiCall{}).Count(&raw)
+		assert.Equal(t, int64(2), raw, "later calls should be kept")
+		assert.Equal(t, 4, apiCalls["/api/farms/:farmId"].Count, "count should include rollups")
+		assert.Equal(t, 1, apiCalls["/api/farms/:farmId"].ServerErrorCount, "server errors should be kept")
+		assert.Equal(t, 1, apiCalls[""].Count, "unmatched count should include rollups")
+		if assert.NotNil(t, apiCalls["/api/farms/:farmId"].RawFrom, "raw from should be set") {
+			assert.True(t, recordedAt.Add(time.Hour).Equal(*apiCalls["/api/farms/:farmId"].RawFrom), "raw from should be the earliest call kept")
+		}
+		assert.Nil(t, apiCalls[""].RawFrom, "raw from should be nil once every call is rolled up")
+	})
+
+	t.Run("should add to a bucket already rolled up", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		calls := newApiCalls()
+		err := apiCallRepository.CreateApiCalls(context.Background(), calls[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = apiCallRepository.RollupApiCalls(context.Background(), recordedAt.Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// roll up another call of the same bucket
+		err = apiCallRepository.CreateApiCalls(context.Background(), calls[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = apiCallRepository.RollupApiCalls(context.Background(), recordedAt.Add(time.Minute))
+
+		//test rollup
+		assert.Nil(t, err, "error should be nil")
+
+		var rollups []domain.ApiCallRollup
+		db.Find(&rollups)
+		if assert.Len(t, rollups, 1) {
+			assert.Equal(t, 2, rollups[0].Count, "count should be added")
+		}
+	})
+
+	t.Run("should keep totals when rolling hours up", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		apiCallRepository := NewApiCallRepository(db)
+		err := apiCallRepository.CreateApiCalls(context.Background(), newApiCalls())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = apiCallRepository.RollupApiCalls(context.Background(), recordedAt.Add(2*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// roll up every hour
+		rolledUp, err := apiCallRepository.RollupHourlyApiCalls(context.Background(), recordedAt.Add(24*time.Hour))
+		apiCalls := getApiCalls(t, apiCallRepository)
+
+		//test rollup
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(4), rolledUp, "every hourly rollup should be rolled up")
+
+		var rollups []domain.ApiCallRollup
+		db.Find(&rollups)
+		for _, rollup := range rollups {
+			assert.Equal(t, domain.ApiCallRollupDay, rollup.Granularity, "only daily rollups should be kept")
+			assert.True(t, recordedAt.Truncate(24*time.Hour).Equal(rollup.BucketStart), "bucket should start at the day")
+		}
+		assert.Equal(t, 4, apiCalls["/api/farms/:farmId"].Count, "count should include rollups")
+		assert.Equal(t, 1, apiCalls[""].Count, "unmatched count should include rollups")
+	})
+}
@@ -0,0 +1,172 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+)
+
+type IApiCallRetention interface {
+	Run(ctx context.Context, now time.Time) error
+	Health() error
+	Close(ctx context.Context) error
+}
+
+// Defaults for unset or invalid config values
+const (
+	DefaultInterval  = time.Hour
+	DefaultRawAge    = 7 * 24 * time.Hour
+	DefaultHourlyAge = 90 * 24 * time.Hour
+	DefaultTimeout   = 10 * time.Minute
+)
+
+type Config struct {
+	// time between two runs
+	Interval time.Duration
+	// age after which raw api calls are rolled into hourly rollups
+	RawAge time.Duration
+	// age after which hourly rollups are rolled into daily rollups, at least RawAge
+	HourlyAge time.Duration
+	// longest time a periodic run may take
+	Timeout time.Duration
+}
+
+// ApiCallRetention periodically rolls old raw api calls into hourly rollups
+// and old hourly rollups into daily ones, deleting what it rolled up
+type ApiCallRetention struct {
+	apiCallRepository repository.IApiCallRepository
+	config            Config
+	stop              chan struct{}
+	done              chan struct{}
+	closeOnce         sync.Once
+
+	// error of the latest periodic run, nil once a run succeeds again
+	errMu  sync.Mutex
+	runErr error
+}
+
+func NewApiCallRetention(apiCallRepository repository.IApiCallRepository, config Config) IApiCallRetention {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.RawAge <= 0 {
+		config.RawAge = DefaultRawAge
+	}
+	if config.HourlyAge <= 0 {
+		config.HourlyAge = DefaultHourlyAge
+	}
+	if config.HourlyAge < config.RawAge {
+		config.HourlyAge = config.RawAge
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	apiCallRetention := &ApiCallRetention{
+		apiCallRepository: apiCallRepository,
+		config:            config,
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+
+	go apiCallRetention.work()
+
+	return apiCallRetention
+}
+
+// Run rolls up everything older than the configured ages at now. Cutoffs are
+// whole hours so a raw hour is rolled up at once.
+func (apiCallRetention *ApiCallRetention) Run(ctx context.Context, now time.Time) error {
+	rawCutoff := now.Add(-apiCallRetention.config.RawAge).Truncate(time.Hour)
+	rolledUp, err := apiCallRetention.apiCallRepository.RollupApiCalls(ctx, rawCutoff)
+	if err != nil {
+		return err
+	}
+
+	hourlyCutoff := now.Add(-apiCallRetention.config.HourlyAge).Truncate(time.Hour)
+	hourlyRolledUp, err := apiCallRetention.apiCallRepository.RollupHourlyApiCalls(ctx, hourlyCutoff)
+	if err != nil {
+		return err
+	}
+
+	if rolledUp > 0 || hourlyRolledUp > 0 {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"API_CALLS":      rolledUp,
+			"HOURLY_ROLLUPS": hourlyRolledUp,
+		}).Info("Rolled up api calls")
+	}
+
+	return nil
+}
+
+// Health fails once the retention is closed or while its latest periodic run failed
+func (apiCallRetention *ApiCallRetention) Health() error {
+	select {
+	case <-apiCallRetention.done:
+		return errors.New("api call retention is closed")
+	default:
+	}
+
+	apiCallRetention.errMu.Lock()
+	defer apiCallRetention.errMu.Unlock()
+	if apiCallRetention.runErr != nil {
+		return fmt.Errorf("latest run failed: %w", apiCallRetention.runErr)
+	}
+
+	return nil
+}
+
+// Close stops the periodic runs and waits for a running one or until ctx is done
+func (apiCallRetention *ApiCallRetention) Close(ctx context.Context) error {
+	apiCallRetention.closeOnce.Do(func() {
+		close(apiCallRetention.stop)
+	})
+
+	select {
+	case <-apiCallRetention.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run rolls up once, bounded by the configured timeout, and keeps its error
+// for Health
+func (apiCallRetention *ApiCallRetention) run(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiCallRetention.config.Timeout)
+	defer cancel()
+
+	err := apiCallRetention.Run(ctx, now)
+
+	apiCallRetention.errMu.Lock()
+	apiCallRetention.runErr = err
+	apiCallRetention.errMu.Unlock()
+
+	if err != nil {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"ERROR": err.Error(),
+		}).Error("Failed to roll up api calls")
+	}
+}
+
+func (apiCallRetention *ApiCallRetention) work() {
+	defer close(apiCallRetention.done)
+
+	ticker := time.NewTicker(apiCallRetention.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-apiCallRetention.stop:
+			return
+		case now := <-ticker.C:
+			apiCallRetention.run(now)
+		}
+	}
+}
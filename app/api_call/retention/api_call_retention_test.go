@@ -0,0 +1,92 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api_call_mock "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2024, 3, 10, 15, 42, 0, 0, time.UTC)
+
+func TestRun(t *testing.T) {
+	infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+
+	t.Run("should roll up at whole hour cutoffs", func(t *testing.T) {
+		// prepare retention
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		rawCutoff := time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC)
+		hourlyCutoff := time.Date(2024, 2, 9, 15, 0, 0, 0, time.UTC)
+		apiCallRepositoryMock.Mock.On("RollupApiCalls", rawCutoff).Return(int64(120), nil)
+		apiCallRepositoryMock.Mock.On("RollupHourlyApiCalls", hourlyCutoff).Return(int64(4), nil)
+		apiCallRetention := NewApiCallRetention(&apiCallRepositoryMock, Config{RawAge: 7 * 24 * time.Hour, HourlyAge: 30 * 24 * time.Hour})
+		defer apiCallRetention.Close(context.Background())
+
+		// run retention
+		err := apiCallRetention.Run(context.Background(), now)
+
+		//test run
+		assert.Nil(t, err, "error should be nil")
+		apiCallRepositoryMock.Mock.AssertExpectations(t)
+	})
+
+	t.Run("should keep hourly rollups at least as long as raw calls", func(t *testing.T) {
+		// prepare retention
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		cutoff := time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC)
+		apiCallRepositoryMock.Mock.On("RollupApiCalls", cutoff).Return(int64(0), nil)
+		apiCallRepositoryMock.Mock.On("RollupHourlyApiCalls", cutoff).Return(int64(0), nil)
+		apiCallRetention := NewApiCallRetention(&apiCallRepositoryMock, Config{RawAge: 24 * time.Hour, HourlyAge: time.Hour})
+		defer apiCallRetention.Close(context.Background())
+
+		// run retention
+		err := apiCallRetention.Run(context.Background(), now)
+
+		//test run
+		assert.Nil(t, err, "error should be nil")
+		apiCallRepositoryMock.Mock.AssertExpectations(t)
+	})
+
+	t.Run("should stop when failed to roll up api calls", func(t *testing.T) {
+		// prepare retention
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		apiCallRepositoryMock.Mock.On("RollupApiCalls", mock.Anything).Return(nil, errors.New("connection refused"))
+		apiCallRetention := NewApiCallRetention(&apiCallRepositoryMock, Config{})
+		defer apiCallRetention.Close(context.Background())
+
+		// run retention
+		err := apiCallRetention.Run(context.Background(), now)
+
+		//test run
+		assert.Equal(t, errors.New("connection refused"), err, "error should be equal")
+		apiCallRepositoryMock.Mock.AssertNotCalled(t, "RollupHourlyApiCalls", mock.Anything)
+	})
+}
+
+func TestWork(t *testing.T) {
+	t.Run("should run on interval until closed", func(t *testing.T) {
+		// prepare retention
+		apiCallRepositoryMock := api_call_mock.ApiCallRepositoryMock{}
+		runs := make(chan struct{}, 10)
+		apiCallRepositoryMock.Mock.On("RollupApiCalls", mock.Anything).Return(int64(0), nil)
+		apiCallRepositoryMock.Mock.On("RollupHourlyApiCalls", mock.Anything).Return(int64(0), nil).Run(func(args mock.Arguments) {
+			runs <- struct{}{}
+		})
+		apiCallRetention := NewApiCallRetention(&apiCallRepositoryMock, Config{Interval: 10 * time.Millisecond})
+
+		//test run
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("retention did not run")
+		}
+
+		assert.Nil(t, apiCallRetention.Close(context.Background()), "close should succeed")
+		assert.Nil(t, apiCallRetention.Close(context.Background()), "second close should succeed")
+	})
+}
@@ -1,56 +1,134 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"math"
+	"strings"
 
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/recorder"
 	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/repository"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 )
 
 type IApiCallUsecase interface {
-	Get() (map[string]map[string]int, any)
+	Get(ctx context.Context, query domain.ApiCallQuery) (map[string]domain.ApiCallStats, error)
+	GetSeries(ctx context.Context, query domain.ApiCallQuery) (map[string][]domain.ApiCallPoint, error)
+	GetRecorderStats() domain.ApiCallRecorderStats
 }
 
 type ApiCallUsecase struct {
 	apiCallRepository repository.IApiCallRepository
+	apiCallRecorder   recorder.IApiCallRecorder
 }
 
-func NewApiCallUsecase(apiCallRepository repository.IApiCallRepository) IApiCallUsecase {
+func NewApiCallUsecase(apiCallRepository repository.IApiCallRepository, apiCallRecorder recorder.IApiCallRecorder) IApiCallUsecase {
 	return &ApiCallUsecase{
 		apiCallRepository: apiCallRepository,
+		apiCallRecorder:   apiCallRecorder,
 	}
 }
 
-func (apiCallUsecase *ApiCallUsecase) Get() (map[string]map[string]int, any) {
+func (apiCallUsecase *ApiCallUsecase) Get(ctx context.Context, query domain.ApiCallQuery) (map[string]domain.ApiCallStats, error) {
+	// check time window
+	query, err := normalizeQuery(query, "failed to get api calls")
+	if err != nil {
+		return map[string]domain.ApiCallStats{}, err
+	}
+
 	var apiCalls []domain.ApiCallResponse
-	err := apiCallUsecase.apiCallRepository.GetApiCalls(&apiCalls)
+	err = apiCallUsecase.apiCallRepository.GetApiCalls(ctx, &apiCalls, query)
 	if err != nil {
-		return map[string]map[string]int{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to get api calls",
-		}
+		return map[string]domain.ApiCallStats{}, apperror.Internal("failed to get api calls", err)
 	}
 
 	if len(apiCalls) == 0 {
-		return map[string]map[string]int{}, util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     errors.New("api call not found"),
-			Message: "failed to get api calls",
-		}
+		return map[string]domain.ApiCallStats{}, apperror.NotFound(apperror.CodeApiCallNotFound, "failed to get api calls", errors.New("api call not found"))
 	}
 
-	apiResponse := make(map[string]map[string]int, 0)
+	apiResponse := make(map[string]domain.ApiCallStats, 0)
 	for _, v := range apiCalls {
-		endpointAndMethod := fmt.Sprintf("%s %s", v.Method, v.Endpoint)
-		apiResponse[endpointAndMethod] = map[string]int{
-			"count":             v.Count,
-			"unique_user_agent": v.UniqueUserAgent,
+		apiResponse[endpointKey(v.Method, v.Endpoint)] = domain.ApiCallStats{
+			Count:           v.Count,
+			UniqueUserAgent: v.UniqueUserAgent,
+			UniqueIp:        v.UniqueIp,
+			ClientErrorRate: rate(v.ClientErrorCount, v.RecordedCount),
+			ServerErrorRate: rate(v.ServerErrorCount, v.RecordedCount),
+			LatencyP50:      roundLatency(v.LatencyP50),
+			LatencyP95:      roundLatency(v.LatencyP95),
+			LatencyP99:      roundLatency(v.LatencyP99),
+			RawFrom:         v.RawFrom,
 		}
 	}
 
 	return apiResponse, nil
 }
+
+func (apiCallUsecase *ApiCallUsecase) GetSeries(ctx context.Context, query domain.ApiCallQuery) (map[string][]domain.ApiCallPoint, error) {
+	// check time window
+	query, err := normalizeQuery(query, "failed to get api call series")
+	if err != nil {
+		return map[string][]domain.ApiCallPoint{}, err
+	}
+
+	var buckets []domain.ApiCallBucket
+	err = apiCallUsecase.apiCallRepository.GetApiCallBuckets(ctx, &buckets, query)
+	if err != nil {
+		return map[string][]domain.ApiCallPoint{}, apperror.Internal("failed to get api call series", err)
+	}
+
+	if len(buckets) == 0 {
+		return map[string][]domain.ApiCallPoint{}, apperror.NotFound(apperror.CodeApiCallNotFound, "failed to get api call series", errors.New("api call not found"))
+	}
+
+	// buckets are ordered by time, so every series stays ordered
+	series := make(map[string][]domain.ApiCallPoint, 0)
+	for _, v := range buckets {
+		key := endpointKey(v.Method, v.Endpoint)
+		series[key] = append(series[key], domain.ApiCallPoint{
+			Time:  v.BucketStart,
+			Count: v.Count,
+		})
+	}
+
+	return series, nil
+}
+
+func (apiCallUsecase *ApiCallUsecase) GetRecorderStats() domain.ApiCallRecorderStats {
+	return apiCallUsecase.apiCallRecorder.Stats()
+}
+
+// fraction of part in total with four decimals, zero without calls
+func rate(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+// keep microsecond precision of recorded latencies
+func roundLatency(latency float64) float64 {
+	return math.Round(latency*1000) / 1000
+}
+
+// reject a window ending before it starts, methods are recorded upper case
+func normalizeQuery(query domain.ApiCallQuery, message string) (domain.ApiCallQuery, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return query, apperror.Validation(apperror.CodeInvalidQuery, message, errors.New("to is before from"))
+	}
+
+	query.Method = strings.ToUpper(query.Method)
+	return query, nil
+}
+
+// key of an endpoint and method, calls that matched no route share one key
+func endpointKey(method string, endpoint string) string {
+	if endpoint == "" {
+		return domain.ApiCallUnmatched
+	}
+
+	return fmt.Sprintf("%s %s", method, endpoint)
+}
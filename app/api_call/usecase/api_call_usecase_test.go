@@ -1,14 +1,14 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"net/http"
 	"testing"
+	"time"
 
 	api_call_repo_mock "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/mock"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,65 +17,151 @@ var apiCallRepositoryMock = api_call_repo_mock.ApiCallRepositoryMock{
 	Mock: mock.Mock{},
 }
 
-var apiCallUsecase = NewApiCallUsecase(&apiCallRepositoryMock)
+var apiCallRecorderMock = api_call_repo_mock.ApiCallRecorderMock{
+	Mock: mock.Mock{},
+}
+
+var apiCallUsecase = NewApiCallUsecase(&apiCallRepositoryMock, &apiCallRecorderMock)
 
 func TestGet(t *testing.T) {
 	t.Run("should return success", func(t *testing.T) {
 		// call mock
+		rawFrom := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
 		apiCallResponse := []domain.ApiCallResponse{
 			{
-				Endpoint:        "endpoint1",
-				Method:          "method1",
-				Count:           1,
-				UniqueUserAgent: 1,
+				Endpoint:         "endpoint1",
+				Method:           "method1",
+				Count:            10,
+				RecordedCount:    8,
+				UniqueUserAgent:  1,
+				UniqueIp:         2,
+				ClientErrorCount: 2,
+				ServerErrorCount: 1,
+				LatencyP50:       1.25,
+				LatencyP95:       10.5,
+				LatencyP99:       42.0004,
+				RawFrom:          &rawFrom,
 			},
 			{
 				Endpoint:        "endpoint2",
 				Method:          "method2",
 				Count:           1,
 				UniqueUserAgent: 2,
+				UniqueIp:        1,
 			},
 		}
 
 		var apiCalls []domain.ApiCallResponse
-		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls).Return(nil).Run(func(args mock.Arguments) {
+		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls, domain.ApiCallQuery{}).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*[]domain.ApiCallResponse)
 			*arg = append(*arg, apiCallResponse...)
 		})
 
 		// call usecase
-		successResponse, errorResponse := apiCallUsecase.Get()
+		successResponse, errorResponse := apiCallUsecase.Get(context.Background(), domain.ApiCallQuery{})
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
 
-		expectedResponse := make(map[string]map[string]int, 0)
-		for _, v := range apiCallResponse {
-			endpointAndMethod := fmt.Sprintf("%s %s", v.Method, v.Endpoint)
-			expectedResponse[endpointAndMethod] = map[string]int{
-				"count":             v.Count,
-				"unique_user_agent": v.UniqueUserAgent,
-			}
+		expectedResponse := map[string]domain.ApiCallStats{
+			"method1 endpoint1": {
+				Count:           10,
+				UniqueUserAgent: 1,
+				UniqueIp:        2,
+				ClientErrorRate: 0.25,
+				ServerErrorRate: 0.125,
+				LatencyP50:      1.25,
+				LatencyP95:      10.5,
+				LatencyP99:      42,
+				RawFrom:         &rawFrom,
+			},
+			"method2 endpoint2": {
+				Count:           1,
+				UniqueUserAgent: 2,
+				UniqueIp:        1,
+			},
 		}
 
-		assert.Equal(t, expectedResponse["endpoint1 method1"], successResponse["endpoint1 method1"], "endpoint1 method1 should be equal")
-		assert.Equal(t, expectedResponse["endpoint2 method2"], successResponse["endpoint2 method2"], "endpoint2 method2 should be equal")
+		assert.Equal(t, expectedResponse, successResponse, "api call stats should be equal")
 
 		getApiCallsMock.Unset()
 	})
 
+	t.Run("should group unmatched calls", func(t *testing.T) {
+		// call mock
+		query := domain.ApiCallQuery{GroupBy: domain.ApiCallGroupByRoute}
+
+		var apiCalls []domain.ApiCallResponse
+		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls, query).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.ApiCallResponse)
+			*arg = []domain.ApiCallResponse{
+				{Endpoint: "/api/farms/:farmId", Method: "GET", Count: 3, RecordedCount: 3},
+				{Count: 2, RecordedCount: 2, ClientErrorCount: 2},
+			}
+		})
+
+		// call usecase
+		successResponse, errorResponse := apiCallUsecase.Get(context.Background(), query)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, 3, successResponse["GET /api/farms/:farmId"].Count, "route count should be equal")
+		assert.Equal(t, 2, successResponse[domain.ApiCallUnmatched].Count, "unmatched count should be equal")
+		assert.Equal(t, float64(1), successResponse[domain.ApiCallUnmatched].ClientErrorRate, "unmatched client error rate should be equal")
+
+		getApiCallsMock.Unset()
+	})
+
+	t.Run("should filter by upper case method", func(t *testing.T) {
+		// call mock
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		query := domain.ApiCallQuery{From: from, Method: "get", Ip: "127.0.0.1"}
+		expectedQuery := domain.ApiCallQuery{From: from, Method: "GET", Ip: "127.0.0.1"}
+
+		var apiCalls []domain.ApiCallResponse
+		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls, expectedQuery).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.ApiCallResponse)
+			*arg = []domain.ApiCallResponse{{Endpoint: "/api/farms", Method: "GET", Count: 4}}
+		})
+
+		// call usecase
+		successResponse, errorResponse := apiCallUsecase.Get(context.Background(), query)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, 4, successResponse["GET /api/farms"].Count, "count should be equal")
+
+		getApiCallsMock.Unset()
+	})
+
+	t.Run("should return error when to is before from", func(t *testing.T) {
+		// call usecase
+		query := domain.ApiCallQuery{
+			From: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		_, errorResponse := apiCallUsecase.Get(context.Background(), query)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidQuery, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to get api calls", errObject.Message, "message should be equal")
+	})
+
 	t.Run("should return error when api call is not found", func(t *testing.T) {
 		// call mock
 		var apiCalls []domain.ApiCallResponse
-		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls).Return(nil)
+		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls, domain.ApiCallQuery{}).Return(nil)
 
 		// call usecase
-		_, errorResponse := apiCallUsecase.Get()
+		_, errorResponse := apiCallUsecase.Get(context.Background(), domain.ApiCallQuery{})
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusNotFound, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
 		assert.Equal(t, errors.New("api call not found"), errObject.Err, "error should be equal")
 		assert.Equal(t, "failed to get api calls", errObject.Message, "message should be equal")
 
@@ -85,18 +171,113 @@ func TestGet(t *testing.T) {
 	t.Run("should return error when failed to get api calls", func(t *testing.T) {
 		// call mock
 		var apiCalls []domain.ApiCallResponse
-		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls).Return(errors.New("testError"))
+		getApiCallsMock := apiCallRepositoryMock.Mock.On("GetApiCalls", &apiCalls, domain.ApiCallQuery{}).Return(errors.New("testError"))
 
 		// call usecase
-		_, errorResponse := apiCallUsecase.Get()
+		_, errorResponse := apiCallUsecase.Get(context.Background(), domain.ApiCallQuery{})
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
 		assert.Equal(t, "failed to get api calls", errObject.Message, "message should be equal")
 
 		getApiCallsMock.Unset()
 	})
 }
+
+func TestGetSeries(t *testing.T) {
+	t.Run("should return series per endpoint", func(t *testing.T) {
+		// call mock
+		query := domain.ApiCallQuery{Bucket: "hour"}
+		firstHour := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		secondHour := firstHour.Add(time.Hour)
+
+		var buckets []domain.ApiCallBucket
+		getBucketsMock := apiCallRepositoryMock.Mock.On("GetApiCallBuckets", &buckets, query).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.ApiCallBucket)
+			*arg = []domain.ApiCallBucket{
+				{Method: "GET", Endpoint: "/api/farms", BucketStart: firstHour, Count: 3},
+				{BucketStart: firstHour, Count: 1},
+				{Method: "GET", Endpoint: "/api/farms", BucketStart: secondHour, Count: 5},
+			}
+		})
+
+		// call usecase
+		successResponse, errorResponse := apiCallUsecase.GetSeries(context.Background(), query)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+
+		expectedResponse := map[string][]domain.ApiCallPoint{
+			"GET /api/farms": {
+				{Time: firstHour, Count: 3},
+				{Time: secondHour, Count: 5},
+			},
+			domain.ApiCallUnmatched: {
+				{Time: firstHour, Count: 1},
+			},
+		}
+		assert.Equal(t, expectedResponse, successResponse, "series should be equal")
+
+		getBucketsMock.Unset()
+	})
+
+	t.Run("should return error when api call is not found", func(t *testing.T) {
+		// call mock
+		query := domain.ApiCallQuery{Bucket: "day"}
+
+		var buckets []domain.ApiCallBucket
+		getBucketsMock := apiCallRepositoryMock.Mock.On("GetApiCallBuckets", &buckets, query).Return(nil)
+
+		// call usecase
+		_, errorResponse := apiCallUsecase.GetSeries(context.Background(), query)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to get api call series", errObject.Message, "message should be equal")
+
+		getBucketsMock.Unset()
+	})
+
+	t.Run("should return error when failed to get buckets", func(t *testing.T) {
+		// call mock
+		query := domain.ApiCallQuery{Bucket: "minute"}
+
+		var buckets []domain.ApiCallBucket
+		getBucketsMock := apiCallRepositoryMock.Mock.On("GetApiCallBuckets", &buckets, query).Return(errors.New("testError"))
+
+		// call usecase
+		_, errorResponse := apiCallUsecase.GetSeries(context.Background(), query)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
+
+		getBucketsMock.Unset()
+	})
+}
+
+func TestGetRecorderStats(t *testing.T) {
+	t.Run("should return recorder stats", func(t *testing.T) {
+		// call mock
+		stats := domain.ApiCallRecorderStats{
+			Enqueued: 3,
+			Flushed:  3,
+		}
+		statsMock := apiCallRecorderMock.Mock.On("Stats").Return(stats)
+
+		// call usecase
+		successResponse := apiCallUsecase.GetRecorderStats()
+
+		//test response
+		assert.Equal(t, stats, successResponse, "stats should be equal")
+
+		statsMock.Unset()
+	})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/cycle/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+type CycleHandler struct {
+	cycleUsecase usecase.ICycleUsecase
+}
+
+func NewCycleHandler(cycleUsecase usecase.ICycleUsecase) *CycleHandler {
+	return &CycleHandler{
+		cycleUsecase: cycleUsecase,
+	}
+}
+
+func (cycleHandler *CycleHandler) Start(c *gin.Context) {
+	//bind request
+	var request domain.CycleBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//start cycle
+	cycle, err := cycleHandler.cycleUsecase.Start(c.Request.Context(), middleware.GetAuthUser(c), request, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully start cycle", cycle)
+}
+
+func (cycleHandler *CycleHandler) GetByPond(c *gin.Context) {
+	//bind param
+	pondId := c.Param("pondId")
+
+	//get cycles
+	cycles, err := cycleHandler.cycleUsecase.GetByPond(c.Request.Context(), middleware.GetAuthUser(c), pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get cycles", cycles)
+}
+
+func (cycleHandler *CycleHandler) Close(c *gin.Context) {
+	//bind request
+	var request domain.CloseCycleBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+	cycleId := c.Param("cycleId")
+
+	//close cycle
+	cycle, err := cycleHandler.cycleUsecase.Close(c.Request.Context(), middleware.GetAuthUser(c), request, pondId, cycleId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully close cycle", cycle)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	cycle_mock "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var cycleUsecaseMock = cycle_mock.CycleUsecaseMock{
+	Mock: mock.Mock{},
+}
+
+var cycleHandler = NewCycleHandler(&cycleUsecaseMock)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
+func TestStart(t *testing.T) {
+	t.Run("should can start cycle", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockResponse := domain.Cycle{
+			ID:      "cycleID",
+			PondID:  "pondID",
+			Species: requestBody.Species,
+			Status:  domain.CycleStatusActive,
+		}
+		mockCall := cycleUsecaseMock.Mock.On("Start", authUser, requestBody, "pondID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/cycles", cycleHandler.Start)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/cycles", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusCreated, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully start cycle", responseBody["message"], "message should be equal")
+
+		cycleData := responseBody["data"].(map[string]any)
+		assert.Equal(t, mockResponse.ID, cycleData["id"], "cycle id should be equal")
+		assert.Equal(t, string(mockResponse.Status), cycleData["status"], "status should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when request invalid", func(t *testing.T) {
+		// prepare request body
+		requestBodyJson := []byte(`{"species":"vannamei","stock_count":0}`)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/cycles", cycleHandler.Start)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/cycles", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, "failed to bind request", responseBody["message"], "message should be equal")
+	})
+
+	t.Run("should reject when pond has active cycle", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		errObject := apperror.Conflict(apperror.CodeActiveCycleExists, "failed to start cycle", errors.New("pond already has an active cycle"))
+		mockCall := cycleUsecaseMock.Mock.On("Start", authUser, requestBody, "pondID").Return(nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/cycles", cycleHandler.Start)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/cycles", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusConflict, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeActiveCycleExists, responseBody["code"], "code should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestGetByPond(t *testing.T) {
+	t.Run("should can get cycles", func(t *testing.T) {
+		// call mock
+		mockResponse := []domain.Cycle{
+			{ID: "cycleID", PondID: "pondID", Status: domain.CycleStatusActive},
+		}
+		mockCall := cycleUsecaseMock.Mock.On("GetByPond", authUser, "pondID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/ponds/:pondId/cycles", cycleHandler.GetByPond)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/ponds/pondID/cycles", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Len(t, responseBody["data"], 1, "data length should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestClose(t *testing.T) {
+	t.Run("should can close cycle", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.CloseCycleBind{
+			HarvestedAt:    time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC),
+			HarvestCount:   80000,
+			HarvestBiomass: 1600,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockResponse := domain.Cycle{
+			ID:             "cycleID",
+			PondID:         "pondID",
+			Status:         domain.CycleStatusClosed,
+			HarvestedAt:    &requestBody.HarvestedAt,
+			HarvestBiomass: requestBody.HarvestBiomass,
+		}
+		mockCall := cycleUsecaseMock.Mock.On("Close", authUser, requestBody, "pondID", "cycleID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/cycles/:cycleId/close", cycleHandler.Close)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/cycles/cycleID/close", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully close cycle", responseBody["message"], "message should be equal")
+
+		cycleData := responseBody["data"].(map[string]any)
+		assert.Equal(t, string(domain.CycleStatusClosed), cycleData["status"], "status should be equal")
+		assert.Equal(t, requestBody.HarvestBiomass, cycleData["harvest_biomass_kg"], "harvest biomass should be equal")
+
+		mockCall.Unset()
+	})
+}
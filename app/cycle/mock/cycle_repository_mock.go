@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type CycleRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (cycleRepositoryMock *CycleRepositoryMock) FindCycleByCondition(ctx context.Context, cycle any, condition string, values ...any) error {
+	args := cycleRepositoryMock.Mock.Called(append([]any{cycle, condition}, values...)...)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (cycleRepositoryMock *CycleRepositoryMock) CreateCycle(ctx context.Context, cycle *domain.Cycle) error {
+	args := cycleRepositoryMock.Mock.Called(cycle)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (cycleRepositoryMock *CycleRepositoryMock) CloseCycle(ctx context.Context, cycle *domain.Cycle) error {
+	args := cycleRepositoryMock.Mock.Called(cycle)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (cycleRepositoryMock *CycleRepositoryMock) FindCycles(ctx context.Context, cycles *[]domain.Cycle, pondId string) error {
+	args := cycleRepositoryMock.Mock.Called(cycles, pondId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
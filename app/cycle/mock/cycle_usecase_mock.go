@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type CycleUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (cycleUsecaseMock *CycleUsecaseMock) Start(ctx context.Context, authUser domain.AuthUser, request domain.CycleBind, pondId string) (domain.Cycle, error) {
+	args := cycleUsecaseMock.Mock.Called(authUser, request, pondId)
+
+	if args[1] != nil {
+		return domain.Cycle{}, args[1].(error)
+	}
+
+	return args[0].(domain.Cycle), nil
+}
+
+func (cycleUsecaseMock *CycleUsecaseMock) GetByPond(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.Cycle, error) {
+	args := cycleUsecaseMock.Mock.Called(authUser, pondId)
+
+	if args[1] != nil {
+		return nil, args[1].(error)
+	}
+
+	return args[0].([]domain.Cycle), nil
+}
+
+func (cycleUsecaseMock *CycleUsecaseMock) Close(ctx context.Context, authUser domain.AuthUser, request domain.CloseCycleBind, pondId string, cycleId string) (domain.Cycle, error) {
+	args := cycleUsecaseMock.Mock.Called(authUser, request, pondId, cycleId)
+
+	if args[1] != nil {
+		return domain.Cycle{}, args[1].(error)
+	}
+
+	return args[0].(domain.Cycle), nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"gorm.io/gorm"
+)
+
+type ICycleRepository interface {
+	FindCycleByCondition(ctx context.Context, cycle any, condition string, values ...any) error
+	CreateCycle(ctx context.Context, cycle *domain.Cycle) error
+	CloseCycle(ctx context.Context, cycle *domain.Cycle) error
+	FindCycles(ctx context.Context, cycles *[]domain.Cycle, pondId string) error
+}
+
+type CycleRepository struct {
+	db *gorm.DB
+}
+
+func NewCycleRepository(db *gorm.DB) ICycleRepository {
+	return &CycleRepository{
+		db: db,
+	}
+}
+
+func (cycleRepository *CycleRepository) FindCycleByCondition(ctx context.Context, cycle any, condition string, values ...any) error {
+	err := cycleRepository.db.WithContext(ctx).Model(&domain.Cycle{}).Where(condition, values...).First(cycle).Error
+	return err
+}
+
+func (cycleRepository *CycleRepository) CreateCycle(ctx context.Context, cycle *domain.Cycle) error {
+	tx := cycleRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Create(cycle).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// CloseCycle saves the harvest of the cycle if it is still active,
+// ErrCycleClosed when it was closed in the meantime
+func (cycleRepository *CycleRepository) CloseCycle(ctx context.Context, cycle *domain.Cycle) error {
+	tx := cycleRepository.db.WithContext(ctx).Begin()
+
+	result := tx.Model(cycle).Select("status", "harvested_at", "harvest_count", "harvest_biomass", "updated_at").Where("status = ?", domain.CycleStatusActive).Updates(cycle)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrCycleClosed
+	}
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	return tx.Commit().Error
+}
+
+func (cycleRepository *CycleRepository) FindCycles(ctx context.Context, cycles *[]domain.Cycle, pondId string) error {
+	err := cycleRepository.db.WithContext(ctx).Model(&domain.Cycle{}).Where("pond_id = ?", pondId).Order("stocked_at desc").Find(cycles).Error
+	return err
+}
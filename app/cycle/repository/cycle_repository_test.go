@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database/databasetest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var stockedAt = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// pond of a new farm of organization
+func createPond(t *testing.T, db *gorm.DB, organizationId string, name string) domain.Pond {
+	farm := domain.Farm{
+		OrganizationID: organizationId,
+		Name:           name,
+	}
+	err := db.Create(&farm).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pond := domain.Pond{
+		FarmID: farm.ID,
+		Name:   name,
+		Type:   domain.PondTypeEarthen,
+		Shape:  domain.PondShapeRectangular,
+	}
+	err = db.Create(&pond).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pond
+}
+
+func createCycle(t *testing.T, db *gorm.DB, pondId string, stockedAt time.Time) domain.Cycle {
+	cycle := domain.Cycle{
+		PondID:        pondId,
+		Species:       "vannamei",
+		StockedAt:     stockedAt,
+		StockCount:    100000,
+		AverageWeight: 0.01,
+		Status:        domain.CycleStatusActive,
+	}
+	err := NewCycleRepository(db).CreateCycle(context.Background(), &cycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cycle
+}
+
+func TestCreateCycle(t *testing.T) {
+	t.Run("should reject a second active cycle of a pond", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		pond := createPond(t, db, organization.ID, "test_pond")
+		otherPond := createPond(t, db, organization.ID, "other_pond")
+		createCycle(t, db, pond.ID, stockedAt)
+		cycleRepository := NewCycleRepository(db)
+
+		// start another cycle in both ponds
+		duplicateErr := cycleRepository.CreateCycle(context.Background(), &domain.Cycle{PondID: pond.ID, Species: "vannamei", StockedAt: stockedAt, Status: domain.CycleStatusActive})
+		otherErr := cycleRepository.CreateCycle(context.Background(), &domain.Cycle{PondID: otherPond.ID, Species: "vannamei", StockedAt: stockedAt, Status: domain.CycleStatusActive})
+
+		//test uniqueness
+		assert.ErrorIs(t, duplicateErr, gorm.ErrDuplicatedKey, "second active cycle should fail")
+		assert.Nil(t, otherErr, "active cycle of another pond should succeed")
+	})
+}
+
+func TestCloseCycle(t *testing.T) {
+	t.Run("should save the harvest of an active cycle", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		pond := createPond(t, db, organization.ID, "test_pond")
+		cycle := createCycle(t, db, pond.ID, stockedAt)
+
+		// close cycle
+		harvestedAt := stockedAt.AddDate(0, 0, 100)
+		cycle.Status = domain.CycleStatusClosed
+		cycle.HarvestedAt = &harvestedAt
+		cycle.HarvestCount = 80000
+		cycle.HarvestBiomass = 1600
+		err := NewCycleRepository(db).CloseCycle(context.Background(), &cycle)
+
+		//test stored cycle
+		assert.Nil(t, err, "error should be nil")
+		var stored domain.Cycle
+		err = db.First(&stored, "id = ?", cycle.ID).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, domain.CycleStatusClosed, stored.Status, "status should be closed")
+		assert.Equal(t, int64(80000), stored.HarvestCount, "harvest count should be equal")
+		assert.Equal(t, 1600.0, stored.HarvestBiomass, "harvest biomass should be equal")
+	})
+
+	t.Run("should keep the first harvest when the cycle is closed twice", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		pond := createPond(t, db, organization.ID, "test_pond")
+		cycle := createCycle(t, db, pond.ID, stockedAt)
+		cycleRepository := NewCycleRepository(db)
+
+		// close the cycle from two reads of it
+		first, second := cycle, cycle
+		harvestedAt := stockedAt.AddDate(0, 0, 100)
+		first.Status, second.Status = domain.CycleStatusClosed, domain.CycleStatusClosed
+		first.HarvestedAt, second.HarvestedAt = &harvestedAt, &harvestedAt
+		first.HarvestBiomass, second.HarvestBiomass = 1600, 900
+		err := cycleRepository.CloseCycle(context.Background(), &first)
+		secondErr := cycleRepository.CloseCycle(context.Background(), &second)
+
+		//test guard
+		assert.Nil(t, err, "error should be nil")
+		assert.ErrorIs(t, secondErr, domain.ErrCycleClosed, "second close should fail")
+		var stored domain.Cycle
+		err = db.First(&stored, "id = ?", cycle.ID).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, 1600.0, stored.HarvestBiomass, "first harvest should be kept")
+	})
+}
+
+func TestFindCycles(t *testing.T) {
+	t.Run("should list cycles of the pond from the latest stocking", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		pond := createPond(t, db, organization.ID, "test_pond")
+		otherPond := createPond(t, db, organization.ID, "other_pond")
+		cycleRepository := NewCycleRepository(db)
+		first := createCycle(t, db, pond.ID, stockedAt)
+		first.Status = domain.CycleStatusClosed
+		err := cycleRepository.CloseCycle(context.Background(), &first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second := createCycle(t, db, pond.ID, stockedAt.AddDate(0, 4, 0))
+		createCycle(t, db, otherPond.ID, stockedAt)
+
+		// list cycles
+		var cycles []domain.Cycle
+		err = cycleRepository.FindCycles(context.Background(), &cycles, pond.ID)
+
+		//test listing
+		assert.Nil(t, err, "error should be nil")
+		if assert.Len(t, cycles, 2, "cycles of other ponds should not be listed") {
+			assert.Equal(t, second.ID, cycles[0].ID, "latest stocking should be first")
+			assert.Equal(t, first.ID, cycles[1].ID, "earliest stocking should be last")
+		}
+	})
+}
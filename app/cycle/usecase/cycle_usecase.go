@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	cycle_repository "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/repository"
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	pond_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/pond/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ICycleUsecase interface {
+	Start(ctx context.Context, authUser domain.AuthUser, request domain.CycleBind, pondId string) (domain.Cycle, error)
+	GetByPond(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.Cycle, error)
+	Close(ctx context.Context, authUser domain.AuthUser, request domain.CloseCycleBind, pondId string, cycleId string) (domain.Cycle, error)
+}
+
+type CycleUsecase struct {
+	cycleRepository cycle_repository.ICycleRepository
+	pondRepository  pond_repository.IPondRepository
+	farmRepository  farm_repository.IFarmRepository
+}
+
+func NewCycleUsecase(cycleRepository cycle_repository.ICycleRepository, pondRepository pond_repository.IPondRepository, farmRepository farm_repository.IFarmRepository) ICycleUsecase {
+	return &CycleUsecase{
+		cycleRepository: cycleRepository,
+		pondRepository:  pondRepository,
+		farmRepository:  farmRepository,
+	}
+}
+
+func (cycleUsecase *CycleUsecase) Start(ctx context.Context, authUser domain.AuthUser, request domain.CycleBind, pondId string) (domain.Cycle, error) {
+	// check if user can log data of pond
+	_, err := pond_usecase.AuthorizePond(ctx, cycleUsecase.pondRepository, cycleUsecase.farmRepository, authUser, pondId, domain.PermissionLogData, "failed to start cycle")
+	if err != nil {
+		return domain.Cycle{}, err
+	}
+
+	// check for active cycle
+	isCycleExist := cycleUsecase.cycleRepository.FindCycleByCondition(ctx, &domain.Cycle{}, "pond_id = ? AND status = ?", pondId, domain.CycleStatusActive)
+	if isCycleExist == nil {
+		return domain.Cycle{}, apperror.Conflict(apperror.CodeActiveCycleExists, "failed to start cycle", errors.New("pond already has an active cycle"))
+	}
+	if !errors.Is(isCycleExist, gorm.ErrRecordNotFound) {
+		return domain.Cycle{}, apperror.Internal("failed to start cycle", isCycleExist)
+	}
+
+	// start cycle
+	cycle := domain.Cycle{
+		PondID:        pondId,
+		Species:       request.Species,
+		StockedAt:     request.StockedAt,
+		StockCount:    request.StockCount,
+		AverageWeight: request.AverageWeight,
+		Status:        domain.CycleStatusActive,
+	}
+	err = cycleUsecase.cycleRepository.CreateCycle(ctx, &cycle)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// another cycle was started since the check
+		return domain.Cycle{}, apperror.Conflict(apperror.CodeActiveCycleExists, "failed to start cycle", errors.New("pond already has an active cycle"))
+	}
+	if err != nil {
+		return domain.Cycle{}, apperror.Internal("failed to start cycle", err)
+	}
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"POND_ID":  pondId,
+		"CYCLE_ID": cycle.ID,
+	}).Info("Cycle started")
+
+	return cycle, nil
+}
+
+func (cycleUsecase *CycleUsecase) GetByPond(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.Cycle, error) {
+	// check if user can view pond
+	_, err := pond_usecase.AuthorizePond(ctx, cycleUsecase.pondRepository, cycleUsecase.farmRepository, authUser, pondId, domain.PermissionView, "failed to get cycles")
+	if err != nil {
+		return nil, err
+	}
+
+	// get cycles
+	var cycles []domain.Cycle
+	err = cycleUsecase.cycleRepository.FindCycles(ctx, &cycles, pondId)
+	if err != nil {
+		return nil, apperror.Internal("failed to get cycles", err)
+	}
+
+	// check if cycle exist
+	if len(cycles) == 0 {
+		return nil, apperror.NotFound(apperror.CodeCycleNotFound, "failed to get cycles", errors.New("cycle not found"))
+	}
+
+	return cycles, nil
+}
+
+func (cycleUsecase *CycleUsecase) Close(ctx context.Context, authUser domain.AuthUser, request domain.CloseCycleBind, pondId string, cycleId string) (domain.Cycle, error) {
+	// check if user can log data of pond
+	_, err := pond_usecase.AuthorizePond(ctx, cycleUsecase.pondRepository, cycleUsecase.farmRepository, authUser, pondId, domain.PermissionLogData, "failed to close cycle")
+	if err != nil {
+		return domain.Cycle{}, err
+	}
+
+	// check if cycle exist
+	var cycle domain.Cycle
+	isCycleExist := cycleUsecase.cycleRepository.FindCycleByCondition(ctx, &cycle, "id = ? AND pond_id = ?", cycleId, pondId)
+	if errors.Is(isCycleExist, gorm.ErrRecordNotFound) {
+		return domain.Cycle{}, apperror.NotFound(apperror.CodeCycleNotFound, "failed to close cycle", errors.New("cycle not found"))
+	}
+	if isCycleExist != nil {
+		return domain.Cycle{}, apperror.Internal("failed to close cycle", isCycleExist)
+	}
+
+	if cycle.Status != domain.CycleStatusActive {
+		return domain.Cycle{}, apperror.Conflict(apperror.CodeCycleClosed, "failed to close cycle", errors.New("cycle is already closed"))
+	}
+
+	if request.HarvestedAt.Before(cycle.StockedAt) {
+		return domain.Cycle{}, apperror.Validation(apperror.CodeInvalidHarvestDate, "failed to close cycle", errors.New("harvest date is before stocking date"))
+	}
+
+	// close cycle
+	cycle.Status = domain.CycleStatusClosed
+	cycle.HarvestedAt = &request.HarvestedAt
+	cycle.HarvestCount = request.HarvestCount
+	cycle.HarvestBiomass = request.HarvestBiomass
+	err = cycleUsecase.cycleRepository.CloseCycle(ctx, &cycle)
+	if errors.Is(err, domain.ErrCycleClosed) {
+		// the cycle was closed since it was read
+		return domain.Cycle{}, apperror.Conflict(apperror.CodeCycleClosed, "failed to close cycle", errors.New("cycle is already closed"))
+	}
+	if err != nil {
+		return domain.Cycle{}, apperror.Internal("failed to close cycle", err)
+	}
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"POND_ID":  pondId,
+		"CYCLE_ID": cycle.ID,
+	}).Info("Cycle closed")
+
+	return cycle, nil
+}
@@ -0,0 +1,359 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cycle_mock "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/mock"
+	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var cycleRepository = cycle_mock.CycleRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var pondRepository = pond_mock.PondRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var farmRepository = farm_mock.FarmRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var cycleUsecase = NewCycleUsecase(&cycleRepository, &pondRepository, &farmRepository)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// mock pond of farmID where authUser has role
+func mockPondAccess(pondId string, role domain.Role) (*mock.Call, *mock.Call) {
+	pondCall := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "id = ?", pondId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.Pond)
+		arg.ID = pondId
+		arg.FarmID = "farmID"
+	})
+	memberCall := farmRepository.Mock.On("FindMember", &domain.FarmMember{}, "farmID", authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.Role = role
+	})
+
+	return pondCall, memberCall
+}
+
+func TestStart(t *testing.T) {
+	stockedAt := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should return success", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     stockedAt,
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		// call mock
+		cycle := domain.Cycle{
+			PondID:        "pondID",
+			Species:       request.Species,
+			StockedAt:     request.StockedAt,
+			StockCount:    request.StockCount,
+			AverageWeight: request.AverageWeight,
+			Status:        domain.CycleStatusActive,
+		}
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		findActiveMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", "pondID", domain.CycleStatusActive).Return(gorm.ErrRecordNotFound)
+		createCycleMock := cycleRepository.Mock.On("CreateCycle", &cycle).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Cycle)
+			arg.ID = "cycleID"
+		})
+
+		// call usecase
+		successResponse, errorResponse := cycleUsecase.Start(context.Background(), authUser, request, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, "cycleID", successResponse.ID, "cycle id should be equal")
+		assert.Equal(t, domain.CycleStatusActive, successResponse.Status, "status should be active")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findActiveMock.Unset()
+		createCycleMock.Unset()
+	})
+
+	t.Run("should return error when cycle is started concurrently", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     stockedAt,
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleManager)
+		findActiveMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", "pondID", domain.CycleStatusActive).Return(gorm.ErrRecordNotFound)
+		createCycleMock := cycleRepository.Mock.On("CreateCycle", mock.Anything).Return(gorm.ErrDuplicatedKey)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Start(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeActiveCycleExists, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findActiveMock.Unset()
+		createCycleMock.Unset()
+	})
+
+	t.Run("should return error when pond has active cycle", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     stockedAt,
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleManager)
+		findActiveMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", "pondID", domain.CycleStatusActive).Return(nil)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Start(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeActiveCycleExists, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to start cycle", errObject.Message, "message should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findActiveMock.Unset()
+	})
+
+	t.Run("should return error when user is auditor", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CycleBind{
+			Species:       "vannamei",
+			StockedAt:     stockedAt,
+			StockCount:    100000,
+			AverageWeight: 0.01,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Start(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when pond is not found", func(t *testing.T) {
+		// call mock
+		pondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "id = ?", "pondID").Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Start(context.Background(), authUser, domain.CycleBind{}, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodePondNotFound, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+	})
+
+	t.Run("should return internal error when pond lookup fails", func(t *testing.T) {
+		// call mock
+		pondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "id = ?", "pondID").Return(errors.New("connection refused"))
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Start(context.Background(), authUser, domain.CycleBind{}, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+	})
+}
+
+func TestGetByPond(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		// call mock
+		cyclesResponse := []domain.Cycle{
+			{ID: "cycleID2", PondID: "pondID", Status: domain.CycleStatusActive},
+			{ID: "cycleID1", PondID: "pondID", Status: domain.CycleStatusClosed},
+		}
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		var cycles []domain.Cycle
+		findCyclesMock := cycleRepository.Mock.On("FindCycles", &cycles, "pondID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.Cycle)
+			*arg = append(*arg, cyclesResponse...)
+		})
+
+		// call usecase
+		successResponse, errorResponse := cycleUsecase.GetByPond(context.Background(), authUser, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, cyclesResponse, successResponse, "cycles should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCyclesMock.Unset()
+	})
+
+	t.Run("should return error when cycle is not found", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		var cycles []domain.Cycle
+		findCyclesMock := cycleRepository.Mock.On("FindCycles", &cycles, "pondID").Return(nil)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.GetByPond(context.Background(), authUser, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeCycleNotFound, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCyclesMock.Unset()
+	})
+}
+
+func TestClose(t *testing.T) {
+	stockedAt := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	activeCycle := func(args mock.Arguments) {
+		arg := args[0].(*domain.Cycle)
+		arg.ID = "cycleID"
+		arg.PondID = "pondID"
+		arg.StockedAt = stockedAt
+		arg.Status = domain.CycleStatusActive
+	}
+
+	t.Run("should return success", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CloseCycleBind{
+			HarvestedAt:    stockedAt.AddDate(0, 0, 100),
+			HarvestCount:   80000,
+			HarvestBiomass: 1600,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		findCycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "cycleID", "pondID").Return(nil).Run(activeCycle)
+		closeCycleMock := cycleRepository.Mock.On("CloseCycle", mock.Anything).Return(nil)
+
+		// call usecase
+		successResponse, errorResponse := cycleUsecase.Close(context.Background(), authUser, request, "pondID", "cycleID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, domain.CycleStatusClosed, successResponse.Status, "status should be closed")
+		assert.Equal(t, request.HarvestedAt, *successResponse.HarvestedAt, "harvest date should be equal")
+		assert.Equal(t, request.HarvestBiomass, successResponse.HarvestBiomass, "harvest biomass should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCycleMock.Unset()
+		closeCycleMock.Unset()
+	})
+
+	t.Run("should return error when cycle is closed", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		findCycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "cycleID", "pondID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Cycle)
+			arg.Status = domain.CycleStatusClosed
+		})
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Close(context.Background(), authUser, domain.CloseCycleBind{HarvestedAt: stockedAt}, "pondID", "cycleID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeCycleClosed, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCycleMock.Unset()
+	})
+
+	t.Run("should return error when cycle is closed concurrently", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CloseCycleBind{
+			HarvestedAt:    stockedAt.AddDate(0, 0, 100),
+			HarvestCount:   80000,
+			HarvestBiomass: 1600,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		findCycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "cycleID", "pondID").Return(nil).Run(activeCycle)
+		closeCycleMock := cycleRepository.Mock.On("CloseCycle", mock.Anything).Return(domain.ErrCycleClosed)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Close(context.Background(), authUser, request, "pondID", "cycleID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeCycleClosed, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCycleMock.Unset()
+		closeCycleMock.Unset()
+	})
+
+	t.Run("should return error when harvest is before stocking", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.CloseCycleBind{
+			HarvestedAt: stockedAt.AddDate(0, 0, -1),
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		findCycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "cycleID", "pondID").Return(nil).Run(activeCycle)
+
+		// call usecase
+		_, errorResponse := cycleUsecase.Close(context.Background(), authUser, request, "pondID", "cycleID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidHarvestDate, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findCycleMock.Unset()
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
 	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 )
 
 type FarmHandler struct {
@@ -24,15 +26,14 @@ func (farmHandler *FarmHandler) Create(c *gin.Context) {
 	var request domain.FarmBind
 	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		util.FailResponse(c, http.StatusBadRequest, "failed to bind input", err)
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
 		return
 	}
 
 	//create new farm
-	farm, errObject := farmHandler.farmUsecase.Create(request)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	farm, err := farmHandler.farmUsecase.Create(c.Request.Context(), middleware.GetAuthUser(c), request)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -44,33 +45,41 @@ func (farmHandler *FarmHandler) Update(c *gin.Context) {
 	var request domain.FarmBind
 	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		util.FailResponse(c, http.StatusBadRequest, "failed to bind input", err)
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
 		return
 	}
 
 	farmId := c.Param("farmId")
 
 	//update farm
-	farm, errObject := farmHandler.farmUsecase.Update(request, farmId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	farm, etag, err := farmHandler.farmUsecase.Update(c.Request.Context(), middleware.GetAuthUser(c), request, farmId, c.GetHeader("If-Match"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", etag)
 	util.SuccessResponse(c, http.StatusOK, "successfully update farm", farm)
 }
 
 func (farmHandler *FarmHandler) Get(c *gin.Context) {
+	//bind query
+	var query domain.FarmQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
 	//get farms
-	farms, errObject := farmHandler.farmUsecase.Get()
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	farms, pagination, err := farmHandler.farmUsecase.Get(c.Request.Context(), middleware.GetAuthUser(c), query)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	util.SuccessResponse(c, http.StatusOK, "successfully get all farm", farms)
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get all farm", farms, pagination)
 }
 
 func (farmHandler *FarmHandler) GetFarmById(c *gin.Context) {
@@ -78,10 +87,13 @@ func (farmHandler *FarmHandler) GetFarmById(c *gin.Context) {
 	farmId := c.Param("farmId")
 
 	//get farm by id
-	farm, errObject := farmHandler.farmUsecase.GetFarmById(farmId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	farm, err := farmHandler.farmUsecase.GetFarmById(c.Request.Context(), middleware.GetAuthUser(c), farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if util.NotModified(c, farm.ETag()) {
 		return
 	}
 
@@ -93,12 +105,83 @@ func (farmHandler *FarmHandler) Delete(c *gin.Context) {
 	farmId := c.Param("farmId")
 
 	//delete farm
-	errObject := farmHandler.farmUsecase.Delete(farmId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	err := farmHandler.farmUsecase.Delete(c.Request.Context(), middleware.GetAuthUser(c), farmId, c.GetHeader("If-Match"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	util.SuccessResponse(c, http.StatusOK, "successfully delete farm", nil)
 }
+
+func (farmHandler *FarmHandler) GetMembers(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+
+	//get farm members
+	members, err := farmHandler.farmUsecase.GetMembers(c.Request.Context(), middleware.GetAuthUser(c), farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get farm members", members)
+}
+
+func (farmHandler *FarmHandler) AddMember(c *gin.Context) {
+	//bind and validate data
+	var request domain.FarmMemberBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	farmId := c.Param("farmId")
+
+	//add farm member
+	member, err := farmHandler.farmUsecase.AddMember(c.Request.Context(), middleware.GetAuthUser(c), request, farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully add farm member", member)
+}
+
+func (farmHandler *FarmHandler) UpdateMember(c *gin.Context) {
+	//bind and validate data
+	var request domain.FarmMemberRoleBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	farmId := c.Param("farmId")
+	userId := c.Param("userId")
+
+	//update farm member
+	member, err := farmHandler.farmUsecase.UpdateMember(c.Request.Context(), middleware.GetAuthUser(c), request, farmId, userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully update farm member", member)
+}
+
+func (farmHandler *FarmHandler) RemoveMember(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+	userId := c.Param("userId")
+
+	//remove farm member
+	err := farmHandler.farmUsecase.RemoveMember(c.Request.Context(), middleware.GetAuthUser(c), farmId, userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully remove farm member", nil)
+}
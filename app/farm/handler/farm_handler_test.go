@@ -12,7 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +24,17 @@ var farmUsecaseMock = farm_mock.FarmUsecaseMock{
 
 var farmHandler = NewFarmHandler(&farmUsecaseMock)
 
+var authUser = domain.AuthUser{
+	ID:    "userId",
+	Email: "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
 func TestCreateFarm(t *testing.T) {
 	t.Run("should create farm", func(t *testing.T) {
 		//prepare request body
@@ -41,10 +53,12 @@ func TestCreateFarm(t *testing.T) {
 			Name: requestBody.Name,
 		}
 
-		mockCall := farmUsecaseMock.Mock.On("Create", requestBody).Return(mockCallResponse, nil)
+		mockCall := farmUsecaseMock.Mock.On("Create", authUser, requestBody).Return(mockCallResponse, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/farms", farmHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -75,6 +89,8 @@ func TestCreateFarm(t *testing.T) {
 	t.Run("should reject when request invalid", func(t *testing.T) {
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/farms", farmHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -94,6 +110,7 @@ func TestCreateFarm(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
+		assert.Equal(t, apperror.CodeInvalidRequest, responseBody["code"], "code should be equal")
 		assert.Equal(t, "failed to bind input", responseBody["message"], " message should be equal")
 	})
 
@@ -109,16 +126,14 @@ func TestCreateFarm(t *testing.T) {
 		}
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Message: "testMessage",
-			Err:     errors.New("testError"),
-		}
+		errObject := apperror.Internal("testMessage", errors.New("testError"))
 
-		mockCall := farmUsecaseMock.Mock.On("Create", requestBody).Return(nil, errObject)
+		mockCall := farmUsecaseMock.Mock.On("Create", authUser, requestBody).Return(nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/farms", farmHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -136,13 +151,58 @@ func TestCreateFarm(t *testing.T) {
 			t.Fatal(err.Error())
 		}
 
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], " message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], " error should be equal")
 
 		mockCall.Unset()
 	})
+
+	t.Run("should map typed usecase error to response", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.FarmBind{
+			Name: "testName",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		errObject := apperror.Conflict(apperror.CodeFarmNameTaken, "failed to create farm", errors.New("farm name is already used"))
+		mockCall := farmUsecaseMock.Mock.On("Create", authUser, requestBody).Return(nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/farms", farmHandler.Create)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/farms", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusConflict, response.Code, "status code should be equal")
+		assert.Equal(t, "error", responseBody["status"], "status should be equal")
+		assert.Equal(t, apperror.CodeFarmNameTaken, responseBody["code"], "code should be equal")
+		assert.Equal(t, "failed to create farm", responseBody["message"], "message should be equal")
+		assert.Equal(t, "farm name is already used", responseBody["error"], "error should be equal")
+
+		mockCall.Unset()
+	})
 }
 
 func TestUpdateFarm(t *testing.T) {
@@ -163,10 +223,12 @@ func TestUpdateFarm(t *testing.T) {
 			Name: requestBody.Name,
 		}
 
-		mockCall := farmUsecaseMock.Mock.On("Update", requestBody).Return(mockCallResponse, nil)
+		mockCall := farmUsecaseMock.Mock.On("Update", authUser, requestBody, "").Return(mockCallResponse, `"testEtag"`, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/farms/:farmId", farmHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -190,6 +252,7 @@ func TestUpdateFarm(t *testing.T) {
 		assert.Equal(t, "successfully update farm", responseBody["message"], "message should be equal")
 		assert.Equal(t, mockCallResponse.ID, farmData["id"], "farm id should be equal")
 		assert.Equal(t, mockCallResponse.Name, farmData["name"], "farm name should be equal")
+		assert.Equal(t, `"testEtag"`, response.Header().Get("ETag"), "etag should be of the updated farm")
 
 		mockCall.Unset()
 	})
@@ -197,6 +260,8 @@ func TestUpdateFarm(t *testing.T) {
 	t.Run("should reject when request invalid", func(t *testing.T) {
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/farms/:farmId", farmHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -231,16 +296,14 @@ func TestUpdateFarm(t *testing.T) {
 		}
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Message: "testMessage",
-			Err:     errors.New("testError"),
-		}
+		errObject := apperror.Internal("testMessage", errors.New("testError"))
 
-		mockCall := farmUsecaseMock.Mock.On("Update", responseBody).Return(nil, errObject)
+		mockCall := farmUsecaseMock.Mock.On("Update", authUser, responseBody, "").Return(nil, nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/farms/:farmId", farmHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -258,7 +321,7 @@ func TestUpdateFarm(t *testing.T) {
 			t.Fatal(err.Error())
 		}
 
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseData["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseData["message"], " message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseData["error"], " error should be equal")
@@ -285,14 +348,21 @@ func TestGetFarm(t *testing.T) {
 			},
 		}
 
-		mockCall := farmUsecaseMock.Mock.On("Get").Return(mockCallResponse, nil)
+		query := domain.FarmQuery{
+			PageQuery: domain.PageQuery{Page: 2, PageSize: 3, Sort: "-created_at"},
+		}
+		mockPagination := domain.Pagination{Page: 2, PageSize: 3, Total: 9, TotalPages: 3}
+
+		mockCall := farmUsecaseMock.Mock.On("Get", authUser, query).Return(mockCallResponse, mockPagination, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/farms", farmHandler.Get)
 
 		response := httptest.NewRecorder()
-		request, err := http.NewRequest("GET", "/api/farms", nil)
+		request, err := http.NewRequest("GET", "/api/farms?page=2&page_size=3&sort=-created_at", nil)
 		if err != nil {
 			t.Fatal(err.Error())
 		}
@@ -317,21 +387,24 @@ func TestGetFarm(t *testing.T) {
 			assert.Equal(t, mockCallResponse[i].ID, v["id"], "id should be equal")
 		}
 
+		meta := responseBody["meta"].(map[string]any)
+		assert.Equal(t, float64(mockPagination.Total), meta["total"], "total should be equal")
+		assert.Equal(t, "/api/farms?page=3&page_size=3&sort=-created_at", meta["next"], "next link should be equal")
+		assert.Equal(t, "/api/farms?page=1&page_size=3&sort=-created_at", meta["prev"], "prev link should be equal")
+
 		mockCall.Unset()
 	})
 
 	t.Run("should reject when usecase call return error", func(t *testing.T) {
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Message: "testMessage",
-			Err:     errors.New("testError"),
-		}
+		errObject := apperror.Internal("testMessage", errors.New("testError"))
 
-		mockCall := farmUsecaseMock.Mock.On("Get").Return(nil, errObject)
+		mockCall := farmUsecaseMock.Mock.On("Get", authUser, domain.FarmQuery{}).Return(nil, nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/farms", farmHandler.Get)
 
 		response := httptest.NewRecorder()
@@ -349,13 +422,39 @@ func TestGetFarm(t *testing.T) {
 			t.Fatal(err.Error())
 		}
 
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 
 		mockCall.Unset()
 	})
+	t.Run("should reject when query invalid", func(t *testing.T) {
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/farms", farmHandler.Get)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/farms?page_size=1000", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, "error", responseBody["status"], "status should be equal")
+		assert.Equal(t, "failed to bind input", responseBody["message"], "message should be equal")
+	})
 }
 
 func TestGetFarmById(t *testing.T) {
@@ -378,10 +477,12 @@ func TestGetFarmById(t *testing.T) {
 			},
 		}
 
-		mockCall := farmUsecaseMock.Mock.On("GetFarmById", mockCallResponse.ID).Return(mockCallResponse, nil)
+		mockCall := farmUsecaseMock.Mock.On("GetFarmById", authUser, mockCallResponse.ID).Return(mockCallResponse, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/farms/:farmId", farmHandler.GetFarmById)
 
 		response := httptest.NewRecorder()
@@ -405,6 +506,7 @@ func TestGetFarmById(t *testing.T) {
 		assert.Equal(t, "successfully get farm by id", responseBody["message"], "message should be equal")
 		assert.Equal(t, mockCallResponse.ID, farmData["id"], "farm id should be equal")
 		assert.Equal(t, mockCallResponse.Name, farmData["name"], "farm name should be equal")
+		assert.Equal(t, mockCallResponse.ETag(), response.Header().Get("ETag"), "etag should be equal")
 
 		pondsData := farmData["ponds"].([]interface{})
 
@@ -417,17 +519,43 @@ func TestGetFarmById(t *testing.T) {
 		mockCall.Unset()
 	})
 
-	t.Run("should reject when usecase call return error", func(t *testing.T) {
+	t.Run("should answer not modified when if none match lists etag", func(t *testing.T) {
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Message: "testMessage",
-			Err:     errors.New("testError"),
+		mockCallResponse := domain.FarmApi{ID: "testID", Name: "farm1", Version: 3}
+		mockCall := farmUsecaseMock.Mock.On("GetFarmById", authUser, mockCallResponse.ID).Return(mockCallResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/farms/:farmId", farmHandler.GetFarmById)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/farms/testID", nil)
+		if err != nil {
+			t.Fatal(err.Error())
 		}
-		mockCall := farmUsecaseMock.Mock.On("GetFarmById", "testID").Return(nil, errObject)
+		request.Header.Set("If-None-Match", `"stale", W/`+mockCallResponse.ETag())
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		assert.Equal(t, http.StatusNotModified, response.Code, "status code should be equal")
+		assert.Equal(t, mockCallResponse.ETag(), response.Header().Get("ETag"), "etag should be equal")
+		assert.Empty(t, response.Body.Bytes(), "body should be empty")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when usecase call return error", func(t *testing.T) {
+		// call mock
+		errObject := apperror.Internal("testMessage", errors.New("testError"))
+		mockCall := farmUsecaseMock.Mock.On("GetFarmById", authUser, "testID").Return(nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/farms/:farmId", farmHandler.GetFarmById)
 
 		response := httptest.NewRecorder()
@@ -445,7 +573,7 @@ func TestGetFarmById(t *testing.T) {
 			t.Fatal(err.Error())
 		}
 
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
@@ -457,10 +585,12 @@ func TestGetFarmById(t *testing.T) {
 func TestDeleteFarm(t *testing.T) {
 	t.Run("should can delete farm", func(t *testing.T) {
 		// call mock
-		mockCall := farmUsecaseMock.Mock.On("Delete", "testID").Return(nil)
+		mockCall := farmUsecaseMock.Mock.On("Delete", authUser, "testID", "").Return(nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.DELETE("/api/farms/:farmId", farmHandler.Delete)
 
 		response := httptest.NewRecorder()
@@ -488,15 +618,13 @@ func TestDeleteFarm(t *testing.T) {
 
 	t.Run("should reject when usecase call return error", func(t *testing.T) {
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Message: "testMessage",
-			Err:     errors.New("testError"),
-		}
-		mockCall := farmUsecaseMock.Mock.On("Delete", "testID").Return(errObject)
+		errObject := apperror.Internal("testMessage", errors.New("testError"))
+		mockCall := farmUsecaseMock.Mock.On("Delete", authUser, "testID", "").Return(errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.DELETE("/api/farms/:farmId", farmHandler.Delete)
 
 		response := httptest.NewRecorder()
@@ -514,11 +642,170 @@ func TestDeleteFarm(t *testing.T) {
 			t.Fatal(err.Error())
 		}
 
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 
 		mockCall.Unset()
 	})
+
+	t.Run("should reject when farm changed since if match", func(t *testing.T) {
+		// call mock
+		errObject := apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to delete farm", errors.New("farm has changed"))
+		mockCall := farmUsecaseMock.Mock.On("Delete", authUser, "testID", `"stale"`).Return(errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.DELETE("/api/farms/:farmId", farmHandler.Delete)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("DELETE", "/api/farms/testID", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		request.Header.Set("If-Match", `"stale"`)
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusPreconditionFailed, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeVersionMismatch, responseBody["code"], "code should be equal")
+		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestAddFarmMember(t *testing.T) {
+	t.Run("should can add farm member", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.FarmMemberBind{
+			Email: "tech@mail.com",
+			Role:  domain.RoleTechnician,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockCallResponse := domain.FarmMember{
+			FarmID: "testID",
+			UserID: "techID",
+			Role:   domain.RoleTechnician,
+		}
+		mockCall := farmUsecaseMock.Mock.On("AddMember", authUser, requestBody, "testID").Return(mockCallResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/farms/:farmId/members", farmHandler.AddMember)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/farms/testID/members", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		memberData := responseBody["data"].(map[string]any)
+		assert.Equal(t, http.StatusCreated, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully add farm member", responseBody["message"], "message should be equal")
+		assert.Equal(t, mockCallResponse.UserID, memberData["user_id"], "user id should be equal")
+		assert.Equal(t, string(mockCallResponse.Role), memberData["role"], "role should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when role is unknown", func(t *testing.T) {
+		//prepare request body
+		requestBodyJson := []byte(`{"email":"tech@mail.com","role":"owner"}`)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/farms/:farmId/members", farmHandler.AddMember)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/farms/testID/members", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeInvalidRequest, responseBody["code"], "code should be equal")
+	})
+
+	t.Run("should reject when user can not manage farm", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.FarmMemberBind{
+			Email: "tech@mail.com",
+			Role:  domain.RoleTechnician,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		errObject := apperror.Forbidden(apperror.CodeForbidden, "failed to add farm member", errors.New("role auditor is not allowed to manage"))
+		mockCall := farmUsecaseMock.Mock.On("AddMember", authUser, requestBody, "testID").Return(nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/farms/:farmId/members", farmHandler.AddMember)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/farms/testID/members", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusForbidden, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeForbidden, responseBody["code"], "code should be equal")
+		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
+
+		mockCall.Unset()
+	})
 }
@@ -1,6 +1,9 @@
 package mock
 
 import (
+	"context"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,8 +12,18 @@ type FarmRepositoryMock struct {
 	Mock mock.Mock
 }
 
-func (farmRepoMock *FarmRepositoryMock) FindFarmByCondition(farm any, condition string, value any) error {
-	args := farmRepoMock.Mock.Called(farm, condition, value)
+func (farmRepoMock *FarmRepositoryMock) FindFarmByCondition(ctx context.Context, farm any, organizationId string, condition string, values ...any) error {
+	args := farmRepoMock.Mock.Called(append([]any{farm, organizationId, condition}, values...)...)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) CreateFarm(ctx context.Context, farm *domain.Farm, managerId string) error {
+	args := farmRepoMock.Mock.Called(farm, managerId)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -19,7 +32,7 @@ func (farmRepoMock *FarmRepositoryMock) FindFarmByCondition(farm any, condition
 	return nil
 }
 
-func (farmRepoMock *FarmRepositoryMock) CreateFarm(farm *domain.Farm) error {
+func (farmRepoMock *FarmRepositoryMock) UpdateFarm(ctx context.Context, farm *domain.Farm) error {
 	args := farmRepoMock.Mock.Called(farm)
 
 	if args[0] != nil {
@@ -29,7 +42,27 @@ func (farmRepoMock *FarmRepositoryMock) CreateFarm(farm *domain.Farm) error {
 	return nil
 }
 
-func (farmRepoMock *FarmRepositoryMock) UpdateFarm(farm *domain.Farm) error {
+func (farmRepoMock *FarmRepositoryMock) FindFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.FarmQuery) (domain.Pagination, error) {
+	args := farmRepoMock.Mock.Called(farms, organizationId, userId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) GetFarmById(ctx context.Context, farm *domain.FarmApi, organizationId string, farmId string) error {
+	args := farmRepoMock.Mock.Called(farm, organizationId, farmId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) DeleteFarm(ctx context.Context, farm *domain.Farm) error {
 	args := farmRepoMock.Mock.Called(farm)
 
 	if args[0] != nil {
@@ -39,8 +72,38 @@ func (farmRepoMock *FarmRepositoryMock) UpdateFarm(farm *domain.Farm) error {
 	return nil
 }
 
-func (farmRepoMock *FarmRepositoryMock) GetFarms(farms *[]domain.Farm) error {
-	args := farmRepoMock.Mock.Called(farms)
+func (farmRepoMock *FarmRepositoryMock) FindMember(ctx context.Context, member *domain.FarmMember, farmId string, userId string) error {
+	args := farmRepoMock.Mock.Called(member, farmId, userId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) FindMembers(ctx context.Context, members *[]domain.FarmMemberApi, farmId string) error {
+	args := farmRepoMock.Mock.Called(members, farmId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) CreateMember(ctx context.Context, member *domain.FarmMember) error {
+	args := farmRepoMock.Mock.Called(member)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) UpdateMember(ctx context.Context, member *domain.FarmMember) error {
+	args := farmRepoMock.Mock.Called(member)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -49,8 +112,8 @@ func (farmRepoMock *FarmRepositoryMock) GetFarms(farms *[]domain.Farm) error {
 	return nil
 }
 
-func (farmRepoMock *FarmRepositoryMock) GetFarmById(farm *domain.FarmApi, farmId string) error {
-	args := farmRepoMock.Mock.Called(farm, farmId)
+func (farmRepoMock *FarmRepositoryMock) DeleteMember(ctx context.Context, member *domain.FarmMember) error {
+	args := farmRepoMock.Mock.Called(member)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -59,7 +122,37 @@ func (farmRepoMock *FarmRepositoryMock) GetFarmById(farm *domain.FarmApi, farmId
 	return nil
 }
 
-func (farmRepoMock *FarmRepositoryMock) DeleteFarm(farm *domain.Farm) error {
+func (farmRepoMock *FarmRepositoryMock) CountManagers(ctx context.Context, farmId string) (int64, error) {
+	args := farmRepoMock.Mock.Called(farmId)
+
+	if args[1] != nil {
+		return 0, args[1].(error)
+	}
+
+	return args[0].(int64), nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) FindDeletedFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error) {
+	args := farmRepoMock.Mock.Called(farms, organizationId, userId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) FindDeletedFarm(ctx context.Context, farm *domain.Farm, organizationId string, farmId string) error {
+	args := farmRepoMock.Mock.Called(farm, organizationId, farmId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) RestoreFarm(ctx context.Context, farm *domain.Farm) error {
 	args := farmRepoMock.Mock.Called(farm)
 
 	if args[0] != nil {
@@ -68,3 +161,23 @@ func (farmRepoMock *FarmRepositoryMock) DeleteFarm(farm *domain.Farm) error {
 
 	return nil
 }
+
+func (farmRepoMock *FarmRepositoryMock) PurgeFarm(ctx context.Context, farm *domain.Farm) error {
+	args := farmRepoMock.Mock.Called(farm)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmRepoMock *FarmRepositoryMock) PurgeFarms(ctx context.Context, before time.Time) (int64, error) {
+	args := farmRepoMock.Mock.Called(before)
+
+	if args[1] != nil {
+		return 0, args[1].(error)
+	}
+
+	return args[0].(int64), nil
+}
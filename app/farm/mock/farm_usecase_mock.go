@@ -1,8 +1,9 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -10,51 +11,91 @@ type FarmUsecaseMock struct {
 	Mock mock.Mock
 }
 
-func (farmUsecaseMock *FarmUsecaseMock) Create(request domain.FarmBind) (domain.Farm, any) {
-	args := farmUsecaseMock.Mock.Called(request)
+func (farmUsecaseMock *FarmUsecaseMock) Create(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind) (domain.Farm, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, request)
 
 	if args[1] != nil {
-		return domain.Farm{}, args[1].(util.ErrorObject)
+		return domain.Farm{}, args[1].(error)
 	}
 
 	return args[0].(domain.Farm), nil
 }
 
-func (farmUsecaseMock *FarmUsecaseMock) Update(request domain.FarmBind, farmId string) (domain.Farm, any) {
-	args := farmUsecaseMock.Mock.Called(request)
+func (farmUsecaseMock *FarmUsecaseMock) Update(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind, farmId string, ifMatch string) (domain.Farm, string, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, request, ifMatch)
+
+	if args[2] != nil {
+		return domain.Farm{}, "", args[2].(error)
+	}
+
+	return args[0].(domain.Farm), args[1].(string), nil
+}
+
+func (farmUsecaseMock *FarmUsecaseMock) Get(ctx context.Context, authUser domain.AuthUser, query domain.FarmQuery) ([]domain.Farm, domain.Pagination, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, query)
+
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
+	}
+
+	return args[0].([]domain.Farm), args[1].(domain.Pagination), nil
+}
+
+func (farmUsecaseMock *FarmUsecaseMock) GetFarmById(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.FarmApi, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, farmId)
 
 	if args[1] != nil {
-		return domain.Farm{}, args[1].(util.ErrorObject)
+		return domain.FarmApi{}, args[1].(error)
 	}
 
-	return args[0].(domain.Farm), nil
+	return args[0].(domain.FarmApi), nil
 }
 
-func (farmUsecaseMock *FarmUsecaseMock) Get() ([]domain.Farm, any) {
-	args := farmUsecaseMock.Mock.Called()
+func (farmUsecaseMock *FarmUsecaseMock) Delete(ctx context.Context, authUser domain.AuthUser, farmId string, ifMatch string) error {
+	args := farmUsecaseMock.Mock.Called(authUser, farmId, ifMatch)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (farmUsecaseMock *FarmUsecaseMock) GetMembers(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.FarmMemberApi, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, farmId)
 
 	if args[1] != nil {
-		return nil, args[1].(util.ErrorObject)
+		return nil, args[1].(error)
 	}
 
-	return args[0].([]domain.Farm), nil
+	return args[0].([]domain.FarmMemberApi), nil
 }
 
-func (farmUsecaseMock *FarmUsecaseMock) GetFarmById(farmId string) (domain.FarmApi, any) {
-	args := farmUsecaseMock.Mock.Called(farmId)
+func (farmUsecaseMock *FarmUsecaseMock) AddMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberBind, farmId string) (domain.FarmMember, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, request, farmId)
 
 	if args[1] != nil {
-		return domain.FarmApi{}, args[1].(util.ErrorObject)
+		return domain.FarmMember{}, args[1].(error)
 	}
 
-	return args[0].(domain.FarmApi), nil
+	return args[0].(domain.FarmMember), nil
+}
+
+func (farmUsecaseMock *FarmUsecaseMock) UpdateMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberRoleBind, farmId string, userId string) (domain.FarmMember, error) {
+	args := farmUsecaseMock.Mock.Called(authUser, request, farmId, userId)
+
+	if args[1] != nil {
+		return domain.FarmMember{}, args[1].(error)
+	}
+
+	return args[0].(domain.FarmMember), nil
 }
 
-func (farmUsecaseMock *FarmUsecaseMock) Delete(farmId string) any {
-	args := farmUsecaseMock.Mock.Called(farmId)
+func (farmUsecaseMock *FarmUsecaseMock) RemoveMember(ctx context.Context, authUser domain.AuthUser, farmId string, userId string) error {
+	args := farmUsecaseMock.Mock.Called(authUser, farmId, userId)
 
 	if args[0] != nil {
-		return args[0].(util.ErrorObject)
+		return args[0].(error)
 	}
 
 	return nil
@@ -1,17 +1,32 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
 	"gorm.io/gorm"
 )
 
 type IFarmRepository interface {
-	FindFarmByCondition(farm any, condition string, value any) error
-	CreateFarm(farm *domain.Farm) error
-	UpdateFarm(farm *domain.Farm) error
-	GetFarms(farms *[]domain.Farm) error
-	GetFarmById(farm *domain.FarmApi, farmId string) error
-	DeleteFarm(farm *domain.Farm) error
+	FindFarmByCondition(ctx context.Context, farm any, organizationId string, condition string, values ...any) error
+	CreateFarm(ctx context.Context, farm *domain.Farm, managerId string) error
+	UpdateFarm(ctx context.Context, farm *domain.Farm) error
+	FindFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.FarmQuery) (domain.Pagination, error)
+	GetFarmById(ctx context.Context, farm *domain.FarmApi, organizationId string, farmId string) error
+	DeleteFarm(ctx context.Context, farm *domain.Farm) error
+	FindMember(ctx context.Context, member *domain.FarmMember, farmId string, userId string) error
+	FindMembers(ctx context.Context, members *[]domain.FarmMemberApi, farmId string) error
+	CreateMember(ctx context.Context, member *domain.FarmMember) error
+	UpdateMember(ctx context.Context, member *domain.FarmMember) error
+	DeleteMember(ctx context.Context, member *domain.FarmMember) error
+	CountManagers(ctx context.Context, farmId string) (int64, error)
+	FindDeletedFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error)
+	FindDeletedFarm(ctx context.Context, farm *domain.Farm, organizationId string, farmId string) error
+	RestoreFarm(ctx context.Context, farm *domain.Farm) error
+	PurgeFarm(ctx context.Context, farm *domain.Farm) error
+	PurgeFarms(ctx context.Context, before time.Time) (int64, error)
 }
 
 type FarmRepository struct {
@@ -24,13 +39,13 @@ func NewFarmRepository(db *gorm.DB) IFarmRepository {
 	}
 }
 
-func (farmRepo *FarmRepository) FindFarmByCondition(farm any, condition string, value any) error {
-	err := farmRepo.db.Model(&domain.Farm{}).First(farm, condition, value).Error
+func (farmRepo *FarmRepository) FindFarmByCondition(ctx context.Context, farm any, organizationId string, condition string, values ...any) error {
+	err := farmRepo.db.WithContext(ctx).Model(&domain.Farm{}).Where("organization_id = ?", organizationId).Where(condition, values...).First(farm).Error
 	return err
 }
 
-func (farmRepo *FarmRepository) CreateFarm(farm *domain.Farm) error {
-	tx := farmRepo.db.Begin()
+func (farmRepo *FarmRepository) CreateFarm(ctx context.Context, farm *domain.Farm, managerId string) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
 
 	err := tx.Create(farm).Error
 	if err != nil {
@@ -38,54 +53,185 @@ func (farmRepo *FarmRepository) CreateFarm(farm *domain.Farm) error {
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	// creator manages the farm
+	member := domain.FarmMember{
+		FarmID: farm.ID,
+		UserID: managerId,
+		Role:   domain.RoleManager,
+	}
+	err = tx.Create(&member).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateFarm saves the farm if it is still at the version it was read at and
+// bumps the version, ErrVersionMismatch when it changed in the meantime
+func (farmRepo *FarmRepository) UpdateFarm(ctx context.Context, farm *domain.Farm) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	version := farm.Version
+	farm.Version++
+	result := tx.Model(farm).Select("name", "version", "updated_at").Where("version = ?", version).Updates(farm)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrVersionMismatch
+	}
+	if result.Error != nil {
+		tx.Rollback()
+		farm.Version = version
+		return result.Error
+	}
+
+	return tx.Commit().Error
 }
 
-func (farmRepo *FarmRepository) UpdateFarm(farm *domain.Farm) error {
-	tx := farmRepo.db.Begin()
+func (farmRepo *FarmRepository) FindFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.FarmQuery) (domain.Pagination, error) {
+	memberFarms := farmRepo.db.WithContext(ctx).Model(&domain.FarmMember{}).Select("farm_id").Where("user_id = ?", userId)
+	db := farmRepo.db.WithContext(ctx).Model(&domain.Farm{}).Where("farms.organization_id = ? AND farms.id IN (?)", organizationId, memberFarms)
+	db = util.FilterList(db, "farms", query.ListFilter)
 
-	err := tx.Save(farm).Error
+	return util.Paginate(db, "farms", query.PageQuery, domain.ListSortFields, farms)
+}
+
+func (farmRepo *FarmRepository) GetFarmById(ctx context.Context, farm *domain.FarmApi, organizationId string, farmId string) error {
+	err := farmRepo.db.WithContext(ctx).Model(&domain.Farm{}).Preload("Ponds").Where("organization_id = ?", organizationId).First(farm, "id = ?", farmId).Error
+	return err
+}
+
+// DeleteFarm soft deletes the farm and its ponds at the same time, so restoring
+// the farm restores the ponds deleted with it and not those deleted before.
+// ErrVersionMismatch when the farm changed since it was read.
+func (farmRepo *FarmRepository) DeleteFarm(ctx context.Context, farm *domain.Farm) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	deletedAt := tx.NowFunc()
+	result := tx.Model(farm).Where("version = ?", farm.Version).UpdateColumn("deleted_at", deletedAt)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrVersionMismatch
+	}
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	err := tx.Model(&domain.Pond{}).Where("farm_id = ?", farm.ID).UpdateColumn("deleted_at", deletedAt).Error
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit().Error
 }
 
-func (farmRepo *FarmRepository) GetFarms(farms *[]domain.Farm) error {
-	err := farmRepo.db.Find(farms).Error
+func (farmRepo *FarmRepository) FindMember(ctx context.Context, member *domain.FarmMember, farmId string, userId string) error {
+	err := farmRepo.db.WithContext(ctx).First(member, "farm_id = ? AND user_id = ?", farmId, userId).Error
 	return err
 }
 
-func (farmRepo *FarmRepository) GetFarmById(farm *domain.FarmApi, farmId string) error {
-	err := farmRepo.db.Model(&domain.Farm{}).Preload("Ponds").First(farm, "id = ?", farmId).Error
+func (farmRepo *FarmRepository) FindMembers(ctx context.Context, members *[]domain.FarmMemberApi, farmId string) error {
+	err := farmRepo.db.WithContext(ctx).Model(&domain.FarmMember{}).
+		Select("farm_members.farm_id, farm_members.user_id, users.name, users.email, farm_members.role, farm_members.created_at, farm_members.updated_at").
+		Joins("JOIN users ON users.id = farm_members.user_id AND users.deleted_at IS NULL").
+		Where("farm_members.farm_id = ?", farmId).
+		Order("farm_members.created_at asc").
+		Scan(members).Error
 	return err
 }
 
-func (farmRepo *FarmRepository) DeleteFarm(farm *domain.Farm) error {
-	tx := farmRepo.db.Begin()
+func (farmRepo *FarmRepository) CreateMember(ctx context.Context, member *domain.FarmMember) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
 
-	var ponds []domain.Pond
-	tx.Model(&domain.Pond{}).Find(&ponds, "farm_id = ?", farm.ID)
-	if len(ponds) != 0 {
-		for _, pond := range ponds {
-			err := tx.Delete(&pond).Error
-			if err != nil {
-				tx.Rollback()
-				return err
-			}
-		}
+	err := tx.Create(member).Error
+	if err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	err := tx.Delete(farm).Error
+	return tx.Commit().Error
+}
+
+func (farmRepo *FarmRepository) UpdateMember(ctx context.Context, member *domain.FarmMember) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	err := tx.Save(member).Error
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit().Error
+}
+
+func (farmRepo *FarmRepository) DeleteMember(ctx context.Context, member *domain.FarmMember) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	err := tx.Delete(member).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (farmRepo *FarmRepository) CountManagers(ctx context.Context, farmId string) (int64, error) {
+	var count int64
+	err := farmRepo.db.WithContext(ctx).Model(&domain.FarmMember{}).Where("farm_id = ? AND role = ?", farmId, domain.RoleManager).Count(&count).Error
+	return count, err
+}
+
+func (farmRepo *FarmRepository) FindDeletedFarms(ctx context.Context, farms *[]domain.Farm, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error) {
+	memberFarms := farmRepo.db.WithContext(ctx).Model(&domain.FarmMember{}).Select("farm_id").Where("user_id = ?", userId)
+	db := farmRepo.db.WithContext(ctx).Unscoped().Model(&domain.Farm{}).
+		Where("farms.organization_id = ? AND farms.deleted_at IS NOT NULL AND farms.id IN (?)", organizationId, memberFarms)
+
+	return util.Paginate(db, "farms", query.PageQuery, domain.TrashSortFields, farms)
+}
+
+func (farmRepo *FarmRepository) FindDeletedFarm(ctx context.Context, farm *domain.Farm, organizationId string, farmId string) error {
+	err := farmRepo.db.WithContext(ctx).Unscoped().Where("organization_id = ? AND deleted_at IS NOT NULL", organizationId).First(farm, "id = ?", farmId).Error
+	return err
+}
+
+// RestoreFarm restores the farm and the ponds deleted with it
+func (farmRepo *FarmRepository) RestoreFarm(ctx context.Context, farm *domain.Farm) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	deletedAt := tx.Unscoped().Model(&domain.Farm{}).Select("deleted_at").Where("id = ?", farm.ID)
+	err := tx.Unscoped().Model(&domain.Pond{}).Where("farm_id = ? AND deleted_at = (?)", farm.ID, deletedAt).UpdateColumn("deleted_at", nil).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Unscoped().Model(farm).UpdateColumn("deleted_at", nil).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgeFarm permanently deletes the farm, its ponds and everything recorded
+// on them go with it through the foreign keys
+func (farmRepo *FarmRepository) PurgeFarm(ctx context.Context, farm *domain.Farm) error {
+	tx := farmRepo.db.WithContext(ctx).Begin()
+
+	err := tx.Unscoped().Delete(farm).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgeFarms permanently deletes the farms deleted before the cutoff
+func (farmRepo *FarmRepository) PurgeFarms(ctx context.Context, before time.Time) (int64, error) {
+	result := farmRepo.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&domain.Farm{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,545 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database/databasetest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// farm of organization managed by manager with one pond per pond name
+func createFarm(t *testing.T, db *gorm.DB, organizationId string, managerId string, name string, pondNames ...string) domain.Farm {
+	farm := domain.Farm{
+		OrganizationID: organizationId,
+		Name:           name,
+	}
+	err := NewFarmRepository(db).CreateFarm(context.Background(), &farm, managerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pondName := range pondNames {
+		pond := domain.Pond{
+			FarmID: farm.ID,
+			Name:   pondName,
+			Type:   domain.PondTypeEarthen,
+			Shape:  domain.PondShapeRectangular,
+		}
+		err = db.Create(&pond).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return farm
+}
+
+func TestCreateFarm(t *testing.T) {
+	t.Run("should create farm managed by its creator", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farmRepository := NewFarmRepository(db)
+
+		// create farm
+		farm := domain.Farm{
+			OrganizationID: organization.ID,
+			Name:           "test_farm",
+		}
+		err := farmRepository.CreateFarm(context.Background(), &farm, user.ID)
+
+		//test farm and membership
+		assert.Nil(t, err, "error should be nil")
+		assert.NotEmpty(t, farm.ID, "id should be generated")
+
+		var member domain.FarmMember
+		err = farmRepository.FindMember(context.Background(), &member, farm.ID, user.ID)
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, domain.RoleManager, member.Role, "creator should manage the farm")
+	})
+
+	t.Run("should reject duplicate name within an organization", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		otherOrganization := databasetest.CreateOrganization(t, db, "other_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		otherUser := databasetest.CreateUser(t, db, otherOrganization.ID, "other@mail.com")
+		createFarm(t, db, organization.ID, user.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+
+		// create farms with the same name
+		duplicate := domain.Farm{
+			OrganizationID: organization.ID,
+			Name:           "test_farm",
+		}
+		duplicateErr := farmRepository.CreateFarm(context.Background(), &duplicate, user.ID)
+		other := domain.Farm{
+			OrganizationID: otherOrganization.ID,
+			Name:           "test_farm",
+		}
+		otherErr := farmRepository.CreateFarm(context.Background(), &other, otherUser.ID)
+
+		//test uniqueness
+		assert.NotNil(t, duplicateErr, "duplicate in the organization should fail")
+		assert.Nil(t, otherErr, "same name in another organization should succeed")
+
+		var count int64
+		db.Model(&domain.FarmMember{}).Where("user_id = ?", user.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "failed farm should leave no membership")
+	})
+
+	t.Run("should reuse name of a deleted farm", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		deleted := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+		err := farmRepository.DeleteFarm(context.Background(), &deleted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// create farm with the deleted name
+		farm := domain.Farm{
+			OrganizationID: organization.ID,
+			Name:           "test_farm",
+		}
+		err = farmRepository.CreateFarm(context.Background(), &farm, user.ID)
+
+		//test uniqueness ignores deleted farms
+		assert.Nil(t, err, "error should be nil")
+	})
+}
+
+func TestFindFarmByCondition(t *testing.T) {
+	t.Run("should only find farms of the organization", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		otherOrganization := databasetest.CreateOrganization(t, db, "other_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+
+		// find farm from both organizations
+		var found, other domain.Farm
+		err := farmRepository.FindFarmByCondition(context.Background(), &found, organization.ID, "id = ?", farm.ID)
+		otherErr := farmRepository.FindFarmByCondition(context.Background(), &other, otherOrganization.ID, "id = ?", farm.ID)
+
+		//test scope
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, farm.Name, found.Name, "name should be equal")
+		assert.ErrorIs(t, otherErr, gorm.ErrRecordNotFound, "other organization should not find the farm")
+	})
+}
+
+func TestFindFarms(t *testing.T) {
+	t.Run("should list farms the user is a member of", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		otherUser := databasetest.CreateUser(t, db, organization.ID, "other@mail.com")
+		createFarm(t, db, organization.ID, user.ID, "north_farm")
+		createFarm(t, db, organization.ID, user.ID, "south_farm")
+		createFarm(t, db, organization.ID, otherUser.ID, "east_farm")
+		deleted := createFarm(t, db, organization.ID, user.ID, "west_farm")
+		farmRepository := NewFarmRepository(db)
+		err := farmRepository.DeleteFarm(context.Background(), &deleted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// list farms
+		var farms []domain.Farm
+		pagination, err := farmRepository.FindFarms(context.Background(), &farms, organization.ID, user.ID, domain.FarmQuery{
+			PageQuery: domain.PageQuery{Sort: "-name"},
+		})
+
+		//test listing
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(2), pagination.Total, "total should count member farms")
+		if assert.Len(t, farms, 2) {
+			assert.Equal(t, "south_farm", farms[0].Name, "farms should be sorted by name descending")
+			assert.Equal(t, "north_farm", farms[1].Name, "farms should be sorted by name descending")
+		}
+	})
+
+	t.Run("should page with a cursor and filter by name", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		for _, name := range []string{"north_farm", "north_hill", "North_lake", "south_farm"} {
+			createFarm(t, db, organization.ID, user.ID, name)
+		}
+		farmRepository := NewFarmRepository(db)
+
+		// list two pages of farms named north
+		query := domain.FarmQuery{
+			PageQuery:  domain.PageQuery{PageSize: 2, Sort: "name"},
+			ListFilter: domain.ListFilter{Name: "north"},
+		}
+		var firstPage []domain.Farm
+		firstPagination, firstErr := farmRepository.FindFarms(context.Background(), &firstPage, organization.ID, user.ID, query)
+		query.Cursor = firstPagination.NextCursor
+		var secondPage []domain.Farm
+		secondPagination, secondErr := farmRepository.FindFarms(context.Background(), &secondPage, organization.ID, user.ID, query)
+
+		//test pages
+		assert.Nil(t, firstErr, "error should be nil")
+		assert.Nil(t, secondErr, "error should be nil")
+		assert.Equal(t, int64(3), firstPagination.Total, "name should match case-insensitive prefixes")
+		assert.Len(t, firstPage, 2, "first page should be full")
+		assert.NotEmpty(t, firstPagination.NextCursor, "first page should have a next cursor")
+		assert.Len(t, secondPage, 1, "second page should hold the rest")
+		assert.Empty(t, secondPagination.NextCursor, "last page should have no next cursor")
+	})
+}
+
+func TestGetFarmById(t *testing.T) {
+	t.Run("should preload ponds not deleted", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm", "first_pond", "second_pond")
+		err := db.Where("farm_id = ? AND name = ?", farm.ID, "second_pond").Delete(&domain.Pond{}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// get farm
+		var farmApi domain.FarmApi
+		err = NewFarmRepository(db).GetFarmById(context.Background(), &farmApi, organization.ID, farm.ID)
+
+		//test ponds
+		assert.Nil(t, err, "error should be nil")
+		if assert.Len(t, farmApi.Ponds, 1) {
+			assert.Equal(t, "first_pond", farmApi.Ponds[0].Name, "deleted pond should not be preloaded")
+		}
+	})
+}
+
+func TestDeleteFarm(t *testing.T) {
+	t.Run("should soft delete farm and its ponds", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm", "first_pond", "second_pond")
+		otherFarm := createFarm(t, db, organization.ID, user.ID, "other_farm", "other_pond")
+
+		// delete farm
+		err := NewFarmRepository(db).DeleteFarm(context.Background(), &farm)
+
+		//test soft delete and cascade
+		assert.Nil(t, err, "error should be nil")
+
+		var count int64
+		db.Model(&domain.Farm{}).Where("id = ?", farm.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "farm should be hidden")
+		db.Unscoped().Model(&domain.Farm{}).Where("id = ? AND deleted_at IS NOT NULL", farm.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "farm should be kept as deleted")
+		db.Model(&domain.Pond{}).Where("farm_id = ?", farm.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "ponds should be hidden")
+		db.Unscoped().Model(&domain.Pond{}).Where("farm_id = ? AND deleted_at IS NOT NULL", farm.ID).Count(&count)
+		assert.Equal(t, int64(2), count, "ponds should be kept as deleted")
+		db.Model(&domain.Pond{}).Where("farm_id = ?", otherFarm.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "ponds of other farms should be kept")
+	})
+
+	t.Run("should keep farm changed since it was read", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm", "test_pond")
+		farmRepository := NewFarmRepository(db)
+		stale := farm
+		farm.Name = "renamed_farm"
+		if err := farmRepository.UpdateFarm(context.Background(), &farm); err != nil {
+			t.Fatal(err)
+		}
+
+		// delete stale farm
+		err := farmRepository.DeleteFarm(context.Background(), &stale)
+
+		//test nothing deleted
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch, "error should be version mismatch")
+
+		var count int64
+		db.Model(&domain.Pond{}).Where("farm_id = ?", farm.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "ponds should be kept")
+	})
+}
+
+func TestUpdateFarm(t *testing.T) {
+	t.Run("should save the farm and bump its version", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		createdAt := farm.UpdatedAt
+
+		// rename farm
+		farm.Name = "renamed_farm"
+		err := NewFarmRepository(db).UpdateFarm(context.Background(), &farm)
+
+		//test stored farm
+		var stored domain.Farm
+		db.First(&stored, "id = ?", farm.ID)
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, "renamed_farm", stored.Name, "name should be updated")
+		assert.Equal(t, int64(2), stored.Version, "version should be bumped")
+		assert.Equal(t, int64(2), farm.Version, "version of farm should be bumped")
+		assert.Equal(t, organization.ID, stored.OrganizationID, "organization should be kept")
+		assert.True(t, stored.UpdatedAt.After(createdAt), "updated at should be bumped")
+	})
+
+	t.Run("should reject farm changed since it was read", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+		stale := farm
+		farm.Name = "first_rename"
+		if err := farmRepository.UpdateFarm(context.Background(), &farm); err != nil {
+			t.Fatal(err)
+		}
+
+		// rename stale farm
+		stale.Name = "second_rename"
+		err := farmRepository.UpdateFarm(context.Background(), &stale)
+
+		//test first rename kept
+		var stored domain.Farm
+		db.First(&stored, "id = ?", farm.ID)
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch, "error should be version mismatch")
+		assert.Equal(t, int64(1), stale.Version, "version of stale farm should be kept")
+		assert.Equal(t, "first_rename", stored.Name, "first rename should be kept")
+	})
+}
+
+func TestMembers(t *testing.T) {
+	t.Run("should list members and count managers", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		manager := databasetest.CreateUser(t, db, organization.ID, "manager@mail.com")
+		technician := databasetest.CreateUser(t, db, organization.ID, "technician@mail.com")
+		farm := createFarm(t, db, organization.ID, manager.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+
+		// add technician
+		err := farmRepository.CreateMember(context.Background(), &domain.FarmMember{
+			FarmID: farm.ID,
+			UserID: technician.ID,
+			Role:   domain.RoleTechnician,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var members []domain.FarmMemberApi
+		err = farmRepository.FindMembers(context.Background(), &members, farm.ID)
+		count, countErr := farmRepository.CountManagers(context.Background(), farm.ID)
+
+		//test members
+		assert.Nil(t, err, "error should be nil")
+		assert.Nil(t, countErr, "error should be nil")
+		assert.Len(t, members, 2, "both members should be listed")
+		assert.Equal(t, int64(1), count, "only the creator should manage the farm")
+	})
+
+	t.Run("should reject adding a member twice", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		manager := databasetest.CreateUser(t, db, organization.ID, "manager@mail.com")
+		farm := createFarm(t, db, organization.ID, manager.ID, "test_farm")
+
+		// add the manager again
+		err := NewFarmRepository(db).CreateMember(context.Background(), &domain.FarmMember{
+			FarmID: farm.ID,
+			UserID: manager.ID,
+			Role:   domain.RoleTechnician,
+		})
+
+		//test membership key
+		assert.NotNil(t, err, "error should not be nil")
+	})
+
+	t.Run("should update and delete a member", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		manager := databasetest.CreateUser(t, db, organization.ID, "manager@mail.com")
+		technician := databasetest.CreateUser(t, db, organization.ID, "technician@mail.com")
+		farm := createFarm(t, db, organization.ID, manager.ID, "test_farm")
+		farmRepository := NewFarmRepository(db)
+		member := domain.FarmMember{
+			FarmID: farm.ID,
+			UserID: technician.ID,
+			Role:   domain.RoleTechnician,
+		}
+		err := farmRepository.CreateMember(context.Background(), &member)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// change role of the technician
+		member.Role = domain.RoleAuditor
+		updateErr := farmRepository.UpdateMember(context.Background(), &member)
+		var updated domain.FarmMember
+		findErr := farmRepository.FindMember(context.Background(), &updated, farm.ID, technician.ID)
+
+		var untouched domain.FarmMember
+		untouchedErr := farmRepository.FindMember(context.Background(), &untouched, farm.ID, manager.ID)
+		var memberCount int64
+		db.Model(&domain.FarmMember{}).Where("farm_id = ?", farm.ID).Count(&memberCount)
+
+		//test update
+		assert.Nil(t, updateErr, "error should be nil")
+		assert.Nil(t, findErr, "error should be nil")
+		assert.Equal(t, domain.RoleAuditor, updated.Role, "role should be updated")
+		assert.Nil(t, untouchedErr, "error should be nil")
+		assert.Equal(t, domain.RoleManager, untouched.Role, "other member should keep its role")
+		assert.Equal(t, int64(2), memberCount, "update should not insert a member")
+
+		// remove the technician
+		deleteErr := farmRepository.DeleteMember(context.Background(), &member)
+		count, countErr := farmRepository.CountManagers(context.Background(), farm.ID)
+		var deleted domain.FarmMember
+		findErr = farmRepository.FindMember(context.Background(), &deleted, farm.ID, technician.ID)
+
+		//test delete
+		assert.Nil(t, deleteErr, "error should be nil")
+		assert.Nil(t, countErr, "error should be nil")
+		assert.Equal(t, int64(1), count, "manager should be kept")
+		assert.ErrorIs(t, findErr, gorm.ErrRecordNotFound, "member should be deleted")
+		assert.Nil(t, farmRepository.FindMember(context.Background(), &domain.FarmMember{}, farm.ID, manager.ID), "other member should be kept")
+	})
+}
+
+func TestRestoreFarm(t *testing.T) {
+	t.Run("should restore farm with the ponds deleted with it", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm", "first_pond", "second_pond")
+		err := db.Where("farm_id = ? AND name = ?", farm.ID, "second_pond").Delete(&domain.Pond{}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		farmRepository := NewFarmRepository(db)
+		err = farmRepository.DeleteFarm(context.Background(), &farm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// restore farm
+		var deleted domain.Farm
+		err = farmRepository.FindDeletedFarm(context.Background(), &deleted, organization.ID, farm.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = farmRepository.RestoreFarm(context.Background(), &deleted)
+
+		//test restored farm and ponds
+		assert.Nil(t, err, "error should be nil")
+
+		var restored domain.FarmApi
+		err = farmRepository.GetFarmById(context.Background(), &restored, organization.ID, farm.ID)
+		assert.Nil(t, err, "farm should be restored")
+		if assert.Len(t, restored.Ponds, 1) {
+			assert.Equal(t, "first_pond", restored.Ponds[0].Name, "pond deleted before the farm should stay deleted")
+		}
+	})
+}
+
+func TestFindDeletedFarms(t *testing.T) {
+	t.Run("should list deleted farms the user is a member of", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		otherUser := databasetest.CreateUser(t, db, organization.ID, "other@mail.com")
+		createFarm(t, db, organization.ID, user.ID, "kept_farm")
+		farmRepository := NewFarmRepository(db)
+		for _, farm := range []domain.Farm{
+			createFarm(t, db, organization.ID, user.ID, "first_farm"),
+			createFarm(t, db, organization.ID, user.ID, "second_farm"),
+			createFarm(t, db, organization.ID, otherUser.ID, "other_farm"),
+		} {
+			err := farmRepository.DeleteFarm(context.Background(), &farm)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// list deleted farms
+		var farms []domain.Farm
+		pagination, err := farmRepository.FindDeletedFarms(context.Background(), &farms, organization.ID, user.ID, domain.TrashQuery{
+			PageQuery: domain.PageQuery{Sort: "-deleted_at"},
+		})
+
+		//test listing
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(2), pagination.Total, "total should count deleted member farms")
+		if assert.Len(t, farms, 2) {
+			assert.Equal(t, "second_farm", farms[0].Name, "latest deleted farm should come first")
+			assert.True(t, farms[0].DeletedAt.Valid, "deletion time should be loaded")
+		}
+	})
+}
+
+func TestPurgeFarms(t *testing.T) {
+	t.Run("should permanently delete farms deleted before the cutoff", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		old := createFarm(t, db, organization.ID, user.ID, "old_farm", "old_pond")
+		recent := createFarm(t, db, organization.ID, user.ID, "recent_farm")
+		kept := createFarm(t, db, organization.ID, user.ID, "kept_farm")
+		farmRepository := NewFarmRepository(db)
+		for _, farm := range []domain.Farm{old, recent} {
+			err := farmRepository.DeleteFarm(context.Background(), &farm)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		cutoff := time.Now().Add(-time.Hour)
+		err := db.Unscoped().Model(&domain.Farm{}).Where("id = ?", old.ID).UpdateColumn("deleted_at", cutoff.Add(-time.Hour)).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// purge farms
+		purged, err := farmRepository.PurgeFarms(context.Background(), cutoff)
+
+		//test purge and cascade
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(1), purged, "only the old farm should be purged")
+
+		var count int64
+		db.Unscoped().Model(&domain.Farm{}).Where("id IN ?", []string{recent.ID, kept.ID}).Count(&count)
+		assert.Equal(t, int64(2), count, "recent and kept farms should stay")
+		db.Unscoped().Model(&domain.Pond{}).Where("farm_id = ?", old.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "ponds should be purged with their farm")
+		db.Model(&domain.FarmMember{}).Where("farm_id = ?", old.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "memberships should be purged with their farm")
+	})
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Authorize checks that the user is a member of the farm with a role granting
+// the permission. Non members get not found so farm ids are not leaked, a
+// failed lookup is reported as internal.
+func Authorize(ctx context.Context, farmRepository repository.IFarmRepository, userId string, farmId string, permission domain.Permission, message string) error {
+	var member domain.FarmMember
+	err := farmRepository.FindMember(ctx, &member, farmId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeFarmNotFound, message, errors.New("farm not found"))
+	}
+	if err != nil {
+		return apperror.Internal(message, err)
+	}
+
+	if !member.Role.Can(permission) {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"USER_ID":    userId,
+			"FARM_ID":    farmId,
+			"ROLE":       member.Role,
+			"PERMISSION": permission,
+		}).Warn("Permission denied")
+		return apperror.Forbidden(apperror.CodeForbidden, message, fmt.Errorf("role %s is not allowed to %s", member.Role, permission))
+	}
+
+	return nil
+}
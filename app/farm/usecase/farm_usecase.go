@@ -1,151 +1,357 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"net/http"
+	"strings"
 
 	"github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	user_repository "github.com/reyhanmichiels/AquaFarmManagement/app/user/repository"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
 	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type IFarmUsecase interface {
-	Create(request domain.FarmBind) (domain.Farm, any)
-	Update(request domain.FarmBind, farmId string) (domain.Farm, any)
-	Get() ([]domain.Farm, any)
-	GetFarmById(farmId string) (domain.FarmApi, any)
-	Delete(farmId string) any
+	Create(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind) (domain.Farm, error)
+	Update(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind, farmId string, ifMatch string) (domain.Farm, string, error)
+	Get(ctx context.Context, authUser domain.AuthUser, query domain.FarmQuery) ([]domain.Farm, domain.Pagination, error)
+	GetFarmById(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.FarmApi, error)
+	Delete(ctx context.Context, authUser domain.AuthUser, farmId string, ifMatch string) error
+	GetMembers(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.FarmMemberApi, error)
+	AddMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberBind, farmId string) (domain.FarmMember, error)
+	UpdateMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberRoleBind, farmId string, userId string) (domain.FarmMember, error)
+	RemoveMember(ctx context.Context, authUser domain.AuthUser, farmId string, userId string) error
 }
 
 type FarmUsecase struct {
 	farmRepository repository.IFarmRepository
+	userRepository user_repository.IUserRepository
 }
 
-func NewFarmUsecase(farmRepository repository.IFarmRepository) IFarmUsecase {
+func NewFarmUsecase(farmRepository repository.IFarmRepository, userRepository user_repository.IUserRepository) IFarmUsecase {
 	return &FarmUsecase{
 		farmRepository: farmRepository,
+		userRepository: userRepository,
 	}
 }
 
-func (farmUsecase *FarmUsecase) Create(request domain.FarmBind) (domain.Farm, any) {
+func (farmUsecase *FarmUsecase) Create(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind) (domain.Farm, error) {
 	// check for duplicate entry
-	isFarmExist := farmUsecase.farmRepository.FindFarmByCondition(&domain.Farm{}, "name = ?", request.Name)
+	isFarmExist := farmUsecase.farmRepository.FindFarmByCondition(ctx, &domain.Farm{}, authUser.OrganizationID, "name = ?", request.Name)
 	if isFarmExist == nil {
-		return domain.Farm{}, util.ErrorObject{
-			Code:    http.StatusConflict,
-			Err:     errors.New("farm name is already used"),
-			Message: "failed to create farm",
-		}
+		return domain.Farm{}, apperror.Conflict(apperror.CodeFarmNameTaken, "failed to create farm", errors.New("farm name is already used"))
+	}
+	if !errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Farm{}, apperror.Internal("failed to create farm", isFarmExist)
 	}
 
 	// create new farm
 	farm := domain.Farm{
-		Name: request.Name,
+		OrganizationID: authUser.OrganizationID,
+		Name:           request.Name,
+	}
+	err := farmUsecase.farmRepository.CreateFarm(ctx, &farm, authUser.ID)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.Farm{}, apperror.Conflict(apperror.CodeFarmNameTaken, "failed to create farm", errors.New("farm name is already used"))
 	}
-	err := farmUsecase.farmRepository.CreateFarm(&farm)
 	if err != nil {
-		return domain.Farm{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to create farm",
-		}
+		return domain.Farm{}, apperror.Internal("failed to create farm", err)
 	}
 
 	return farm, nil
 }
 
-func (farmUsecase *FarmUsecase) Update(request domain.FarmBind, farmId string) (domain.Farm, any) {
-	// check for duplicate entry
-	isFarmExist := farmUsecase.farmRepository.FindFarmByCondition(&domain.Farm{}, "name = ?", request.Name)
+// Update renames the farm, ifMatch is the If-Match header, if any, the farm
+// must still match. It returns the farm with its new entity tag
+func (farmUsecase *FarmUsecase) Update(ctx context.Context, authUser domain.AuthUser, request domain.FarmBind, farmId string, ifMatch string) (domain.Farm, string, error) {
+	// check if user can manage farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to update farm")
+	if err != nil {
+		return domain.Farm{}, "", err
+	}
+
+	// check for duplicate entry, other than the farm itself
+	isFarmExist := farmUsecase.farmRepository.FindFarmByCondition(ctx, &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId)
 	if isFarmExist == nil {
-		return domain.Farm{}, util.ErrorObject{
-			Code:    http.StatusConflict,
-			Err:     errors.New("farm name is already used"),
-			Message: "failed to update farm",
-		}
+		return domain.Farm{}, "", apperror.Conflict(apperror.CodeFarmNameTaken, "failed to update farm", errors.New("farm name is already used"))
+	}
+	if !errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Farm{}, "", apperror.Internal("failed to update farm", isFarmExist)
 	}
 
-	// update farm
-	var farm domain.Farm
-	farmUsecase.farmRepository.FindFarmByCondition(&farm, "id = ?", farmId)
+	// check if farm exist
+	var current domain.FarmApi
+	isFarmExist = farmUsecase.farmRepository.GetFarmById(ctx, &current, authUser.OrganizationID, farmId)
+	if errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Farm{}, "", apperror.NotFound(apperror.CodeFarmNotFound, "failed to update farm", errors.New("farm not found"))
+	}
+	if isFarmExist != nil {
+		return domain.Farm{}, "", apperror.Internal("failed to update farm", isFarmExist)
+	}
 
-	farm.ID = farmId
-	farm.Name = request.Name
+	// check if farm is unchanged since the client read it
+	if ifMatch != "" && !util.MatchETag(ifMatch, current.ETag()) {
+		return domain.Farm{}, "", apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to update farm", errors.New("farm has changed"))
+	}
 
-	err := farmUsecase.farmRepository.UpdateFarm(&farm)
+	// update farm
+	farm := domain.Farm{
+		ID:             current.ID,
+		OrganizationID: current.OrganizationID,
+		Name:           request.Name,
+		Version:        current.Version,
+		CreatedAt:      current.CreatedAt,
+	}
+
+	err = farmUsecase.farmRepository.UpdateFarm(ctx, &farm)
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		return domain.Farm{}, "", apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to update farm", errors.New("farm has changed"))
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.Farm{}, "", apperror.Conflict(apperror.CodeFarmNameTaken, "failed to update farm", errors.New("farm name is already used"))
+	}
 	if err != nil {
-		return domain.Farm{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to update farm",
-		}
+		return domain.Farm{}, "", apperror.Internal("failed to update farm", err)
 	}
 
-	return farm, nil
+	// entity tag of the farm as it now reads by id
+	current.Name = farm.Name
+	current.Version = farm.Version
+
+	return farm, current.ETag(), nil
 }
 
-func (farmUsecase *FarmUsecase) Get() ([]domain.Farm, any) {
+func (farmUsecase *FarmUsecase) Get(ctx context.Context, authUser domain.AuthUser, query domain.FarmQuery) ([]domain.Farm, domain.Pagination, error) {
+	// validate sort and cursor
+	err := util.ValidatePageQuery(query.PageQuery, domain.ListSortFields)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get all farm", err)
+	}
+
 	// get farms
 	var farms []domain.Farm
-	err := farmUsecase.farmRepository.GetFarms(&farms)
+	pagination, err := farmUsecase.farmRepository.FindFarms(ctx, &farms, authUser.OrganizationID, authUser.ID, query)
 	if err != nil {
-		return nil, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to get all farm",
-		}
+		return nil, domain.Pagination{}, apperror.Internal("failed to get all farm", err)
 	}
 
 	// check if farm exist
 	if len(farms) == 0 {
-		return nil, util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     errors.New("farm not found"),
-			Message: "failed to get all farm",
-		}
+		return nil, domain.Pagination{}, apperror.NotFound(apperror.CodeFarmNotFound, "failed to get all farm", errors.New("farm not found"))
 	}
 
-	return farms, nil
+	return farms, pagination, nil
 }
 
-func (farmUsecase *FarmUsecase) GetFarmById(farmId string) (domain.FarmApi, any) {
+func (farmUsecase *FarmUsecase) GetFarmById(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.FarmApi, error) {
+	// check if user can view farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionView, "failed to get farm by id")
+	if err != nil {
+		return domain.FarmApi{}, err
+	}
+
 	// get farm by id
 	var farm domain.FarmApi
-	isFarmExist := farmUsecase.farmRepository.GetFarmById(&farm, farmId)
+	isFarmExist := farmUsecase.farmRepository.GetFarmById(ctx, &farm, authUser.OrganizationID, farmId)
 
 	// check if farm exist
+	if errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.FarmApi{}, apperror.NotFound(apperror.CodeFarmNotFound, "failed to get farm by id", errors.New("farm not found"))
+	}
 	if isFarmExist != nil {
-		return domain.FarmApi{}, util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     errors.New("farm not found"),
-			Message: "failed to get farm by id",
-		}
+		return domain.FarmApi{}, apperror.Internal("failed to get farm by id", isFarmExist)
+	}
+
+	// aggregate water surface and volume of ponds
+	for _, pond := range farm.Ponds {
+		farm.SurfaceArea += pond.SurfaceArea
+		farm.Volume += pond.Volume
 	}
 
 	return farm, nil
 }
 
-func (farmUsecase *FarmUsecase) Delete(farmId string) any {
+// Delete soft deletes the farm, ifMatch is the If-Match header, if any, the
+// farm must still match
+func (farmUsecase *FarmUsecase) Delete(ctx context.Context, authUser domain.AuthUser, farmId string, ifMatch string) error {
+	// check if user can manage farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to delete farm")
+	if err != nil {
+		return err
+	}
+
 	//check if farm exist
-	var farm domain.Farm
-	isFarmExist := farmUsecase.farmRepository.FindFarmByCondition(&farm, "id = ?", farmId)
+	var current domain.FarmApi
+	isFarmExist := farmUsecase.farmRepository.GetFarmById(ctx, &current, authUser.OrganizationID, farmId)
+	if errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeFarmNotFound, "failed to delete farm", isFarmExist)
+	}
 	if isFarmExist != nil {
-		return util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     isFarmExist,
-			Message: "failed to delete farm",
-		}
+		return apperror.Internal("failed to delete farm", isFarmExist)
+	}
+
+	// check if farm is unchanged since the client read it
+	if ifMatch != "" && !util.MatchETag(ifMatch, current.ETag()) {
+		return apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to delete farm", errors.New("farm has changed"))
 	}
 
 	//delete farm
-	err := farmUsecase.farmRepository.DeleteFarm(&farm)
+	farm := domain.Farm{ID: current.ID, Version: current.Version}
+	err = farmUsecase.farmRepository.DeleteFarm(ctx, &farm)
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		return apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to delete farm", errors.New("farm has changed"))
+	}
+	if err != nil {
+		return apperror.Internal("failed to delete farm", err)
+	}
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"FARM_ID": farm.ID,
+	}).Info("Farm deleted")
+
+	return nil
+}
+
+func (farmUsecase *FarmUsecase) GetMembers(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.FarmMemberApi, error) {
+	// check if user can view farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionView, "failed to get farm members")
+	if err != nil {
+		return nil, err
+	}
+
+	// get members
+	var members []domain.FarmMemberApi
+	err = farmUsecase.farmRepository.FindMembers(ctx, &members, farmId)
+	if err != nil {
+		return nil, apperror.Internal("failed to get farm members", err)
+	}
+
+	return members, nil
+}
+
+func (farmUsecase *FarmUsecase) AddMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberBind, farmId string) (domain.FarmMember, error) {
+	// check if user can manage farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to add farm member")
 	if err != nil {
-		return util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to delete farm",
+		return domain.FarmMember{}, err
+	}
+
+	// check if user exist in the same organization
+	var user domain.User
+	isUserExist := farmUsecase.userRepository.FindUserByCondition(ctx, &user, "email = ?", strings.ToLower(request.Email))
+	if isUserExist != nil && !errors.Is(isUserExist, gorm.ErrRecordNotFound) {
+		return domain.FarmMember{}, apperror.Internal("failed to add farm member", isUserExist)
+	}
+	if isUserExist != nil || user.OrganizationID != authUser.OrganizationID {
+		return domain.FarmMember{}, apperror.Validation(apperror.CodeUserNotFound, "failed to add farm member", errors.New("user not found"))
+	}
+
+	// check for duplicate entry
+	isMemberExist := farmUsecase.farmRepository.FindMember(ctx, &domain.FarmMember{}, farmId, user.ID)
+	if isMemberExist == nil {
+		return domain.FarmMember{}, apperror.Conflict(apperror.CodeMemberExists, "failed to add farm member", errors.New("user is already a member"))
+	}
+	if !errors.Is(isMemberExist, gorm.ErrRecordNotFound) {
+		return domain.FarmMember{}, apperror.Internal("failed to add farm member", isMemberExist)
+	}
+
+	// add member
+	member := domain.FarmMember{
+		FarmID: farmId,
+		UserID: user.ID,
+		Role:   request.Role,
+	}
+	err = farmUsecase.farmRepository.CreateMember(ctx, &member)
+	if err != nil {
+		return domain.FarmMember{}, apperror.Internal("failed to add farm member", err)
+	}
+
+	return member, nil
+}
+
+func (farmUsecase *FarmUsecase) UpdateMember(ctx context.Context, authUser domain.AuthUser, request domain.FarmMemberRoleBind, farmId string, userId string) (domain.FarmMember, error) {
+	// check if user can manage farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to update farm member")
+	if err != nil {
+		return domain.FarmMember{}, err
+	}
+
+	// check if member exist
+	var member domain.FarmMember
+	isMemberExist := farmUsecase.farmRepository.FindMember(ctx, &member, farmId, userId)
+	if errors.Is(isMemberExist, gorm.ErrRecordNotFound) {
+		return domain.FarmMember{}, apperror.NotFound(apperror.CodeMemberNotFound, "failed to update farm member", errors.New("member not found"))
+	}
+	if isMemberExist != nil {
+		return domain.FarmMember{}, apperror.Internal("failed to update farm member", isMemberExist)
+	}
+
+	// keep at least one manager
+	if member.Role == domain.RoleManager && request.Role != domain.RoleManager {
+		err = farmUsecase.ensureOtherManager(ctx, farmId, "failed to update farm member")
+		if err != nil {
+			return domain.FarmMember{}, err
+		}
+	}
+
+	// update member
+	member.Role = request.Role
+	err = farmUsecase.farmRepository.UpdateMember(ctx, &member)
+	if err != nil {
+		return domain.FarmMember{}, apperror.Internal("failed to update farm member", err)
+	}
+
+	return member, nil
+}
+
+func (farmUsecase *FarmUsecase) RemoveMember(ctx context.Context, authUser domain.AuthUser, farmId string, userId string) error {
+	// check if user can manage farm
+	err := Authorize(ctx, farmUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to remove farm member")
+	if err != nil {
+		return err
+	}
+
+	// check if member exist
+	var member domain.FarmMember
+	isMemberExist := farmUsecase.farmRepository.FindMember(ctx, &member, farmId, userId)
+	if errors.Is(isMemberExist, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeMemberNotFound, "failed to remove farm member", errors.New("member not found"))
+	}
+	if isMemberExist != nil {
+		return apperror.Internal("failed to remove farm member", isMemberExist)
+	}
+
+	// keep at least one manager
+	if member.Role == domain.RoleManager {
+		err = farmUsecase.ensureOtherManager(ctx, farmId, "failed to remove farm member")
+		if err != nil {
+			return err
 		}
 	}
 
+	// remove member
+	err = farmUsecase.farmRepository.DeleteMember(ctx, &member)
+	if err != nil {
+		return apperror.Internal("failed to remove farm member", err)
+	}
+
+	return nil
+}
+
+func (farmUsecase *FarmUsecase) ensureOtherManager(ctx context.Context, farmId string, message string) error {
+	managers, err := farmUsecase.farmRepository.CountManagers(ctx, farmId)
+	if err != nil {
+		return apperror.Internal(message, err)
+	}
+
+	if managers <= 1 {
+		return apperror.Conflict(apperror.CodeLastManager, message, errors.New("farm must keep at least one manager"))
+	}
+
 	return nil
 }
@@ -1,23 +1,56 @@
 package usecase
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
-	"net/http"
 	"testing"
+	"time"
 
 	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	user_mock "github.com/reyhanmichiels/AquaFarmManagement/app/user/mock"
 
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 var farmRepositoryMock = farm_mock.FarmRepositoryMock{
 	Mock: mock.Mock{},
 }
 
-var farmUsecase = NewFarmUsecase(&farmRepositoryMock)
+var userRepositoryMock = user_mock.UserRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var farmUsecase = NewFarmUsecase(&farmRepositoryMock, &userRepositoryMock)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// mock membership of authUser in farm with role
+func mockMember(farmId string, role domain.Role) *mock.Call {
+	return farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.FarmID = farmId
+		arg.UserID = authUser.ID
+		arg.Role = role
+	})
+}
+
+// mock farm of authUser's organization at version
+func mockFarmById(farmId string, version int64) *mock.Call {
+	return farmRepositoryMock.Mock.On("GetFarmById", &domain.FarmApi{}, authUser.OrganizationID, farmId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmApi)
+		arg.ID = farmId
+		arg.Version = version
+	})
+}
 
 func TestCreate(t *testing.T) {
 	t.Run("should return success", func(t *testing.T) {
@@ -28,17 +61,18 @@ func TestCreate(t *testing.T) {
 
 		//call mock
 		farm := domain.Farm{
-			Name: request.Name,
+			OrganizationID: authUser.OrganizationID,
+			Name:           request.Name,
 		}
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(errors.New("not found"))
-		createFarmMock := farmRepositoryMock.Mock.On("CreateFarm", &farm).Return(nil).Run(func(args mock.Arguments) {
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", request.Name).Return(gorm.ErrRecordNotFound)
+		createFarmMock := farmRepositoryMock.Mock.On("CreateFarm", &farm, authUser.ID).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*domain.Farm)
 			arg.ID = "testId"
 			arg.Name = request.Name
 		})
 
 		//call usecase
-		successResponse, errorResponse := farmUsecase.Create(request)
+		successResponse, errorResponse := farmUsecase.Create(context.Background(), authUser, request)
 
 		//test result
 		assert.Nil(t, errorResponse, "err response should be nil")
@@ -56,20 +90,43 @@ func TestCreate(t *testing.T) {
 		}
 
 		//call mock
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(nil)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", request.Name).Return(nil)
 
 		//call usecase
-		_, errorResponse := farmUsecase.Create(request)
+		_, errorResponse := farmUsecase.Create(context.Background(), authUser, request)
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("farm name is already used"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusConflict, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindConflict, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeFarmNameTaken, errObjectFromResponse.Code, "code should be equal")
 		assert.Equal(t, "failed to create farm", errObjectFromResponse.Message, "message should be equal")
 
 		findFarmMock.Unset()
 	})
 
+	t.Run("should return error when name is taken concurrently", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "test_name",
+		}
+
+		//call mock
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", request.Name).Return(gorm.ErrRecordNotFound)
+		createFarmMock := farmRepositoryMock.Mock.On("CreateFarm", mock.Anything, authUser.ID).Return(gorm.ErrDuplicatedKey)
+
+		//call usecase
+		_, errorResponse := farmUsecase.Create(context.Background(), authUser, request)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeFarmNameTaken, errObjectFromResponse.Code, "code should be equal")
+
+		findFarmMock.Unset()
+		createFarmMock.Unset()
+	})
+
 	t.Run("should return error when failed to create farm", func(t *testing.T) {
 		//prepare usecase parameter
 		request := domain.FarmBind{
@@ -78,18 +135,19 @@ func TestCreate(t *testing.T) {
 
 		//call mock
 		farm := domain.Farm{
-			Name: request.Name,
+			OrganizationID: authUser.OrganizationID,
+			Name:           request.Name,
 		}
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(errors.New("not found"))
-		createFarmMock := farmRepositoryMock.Mock.On("CreateFarm", &farm).Return(errors.New("testError"))
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", request.Name).Return(gorm.ErrRecordNotFound)
+		createFarmMock := farmRepositoryMock.Mock.On("CreateFarm", &farm, authUser.ID).Return(errors.New("testError"))
 
 		//call usecase
-		_, errorResponse := farmUsecase.Create(request)
+		_, errorResponse := farmUsecase.Create(context.Background(), authUser, request)
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("testError"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusInternalServerError, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObjectFromResponse.Kind, "kind should be equal")
 		assert.Equal(t, "failed to create farm", errObjectFromResponse.Message, "message should be equal")
 
 		findFarmMock.Unset()
@@ -106,19 +164,21 @@ func TestUpdate(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
 		farm := domain.Farm{
-			ID:   farmId,
-			Name: request.Name,
+			ID:      farmId,
+			Name:    request.Name,
+			Version: 1,
 		}
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(errors.New("not found"))
-		findFarmByIdMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", farmId).Return(nil)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := mockFarmById(farmId, 1)
 		updateFarmMock := farmRepositoryMock.Mock.On("UpdateFarm", &farm).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*domain.Farm)
 			arg.ID = farmId
 			arg.Name = request.Name
 		})
 
-		successResponse, errorResponse := farmUsecase.Update(request, farmId)
+		successResponse, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
 
 		//test result
 		assert.Nil(t, errorResponse, "err response should be nil")
@@ -128,6 +188,37 @@ func TestUpdate(t *testing.T) {
 		findFarmMock.Unset()
 		findFarmByIdMock.Unset()
 		updateFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should update farm keeping its own name", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testName",
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := farmRepositoryMock.Mock.On("GetFarmById", &domain.FarmApi{}, authUser.OrganizationID, farmId).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.FarmApi)
+			arg.ID = farmId
+			arg.Name = request.Name
+			arg.Version = 1
+		})
+		updateFarmMock := farmRepositoryMock.Mock.On("UpdateFarm", &domain.Farm{ID: farmId, Name: request.Name, Version: 1}).Return(nil)
+
+		successResponse, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, request.Name, successResponse.Name, "name should be equal")
+
+		findFarmMock.Unset()
+		findFarmByIdMock.Unset()
+		updateFarmMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when duplicate entry", func(t *testing.T) {
@@ -138,17 +229,20 @@ func TestUpdate(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(nil)
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(nil)
 
-		_, errorResponse := farmUsecase.Update(request, farmId)
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("farm name is already used"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusConflict, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindConflict, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeFarmNameTaken, errObjectFromResponse.Code, "code should be equal")
 		assert.Equal(t, "failed to update farm", errObjectFromResponse.Message, "message should be equal")
 
 		findFarmMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when failed update farm", func(t *testing.T) {
@@ -159,25 +253,153 @@ func TestUpdate(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
 		farm := domain.Farm{
-			ID:   farmId,
-			Name: request.Name,
+			ID:      farmId,
+			Name:    request.Name,
+			Version: 1,
 		}
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "name = ?", request.Name).Return(errors.New("not found"))
-		findFarmByIdMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", farmId).Return(nil)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := mockFarmById(farmId, 1)
 		updateFarmMock := farmRepositoryMock.Mock.On("UpdateFarm", &farm).Return(errors.New("sql failed"))
 
-		_, errorResponse := farmUsecase.Update(request, farmId)
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("sql failed"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusInternalServerError, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, "failed to update farm", errObjectFromResponse.Message, "message should be equal")
+
+		findFarmMock.Unset()
+		findFarmByIdMock.Unset()
+		updateFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when user is not manager", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testUpdateName",
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleTechnician)
+
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeForbidden, errObjectFromResponse.Code, "code should be equal")
 		assert.Equal(t, "failed to update farm", errObjectFromResponse.Message, "message should be equal")
 
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when user is not member", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testUpdateName",
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, authUser.ID).Return(gorm.ErrRecordNotFound)
+
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeFarmNotFound, errObjectFromResponse.Code, "code should be equal")
+		assert.Equal(t, errors.New("farm not found"), errObjectFromResponse.Err, "error should be equal")
+
+		memberMock.Unset()
+	})
+
+	t.Run("should update farm matching if match", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testUpdateName",
+		}
+		farmId := "testId"
+		etag := domain.FarmApi{ID: farmId, Version: 1}.ETag()
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := mockFarmById(farmId, 1)
+		updateFarmMock := farmRepositoryMock.Mock.On("UpdateFarm", &domain.Farm{ID: farmId, Name: request.Name, Version: 1}).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Farm)
+			arg.Version = 2
+		})
+
+		successResponse, updatedEtag, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, `"stale", `+etag)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, int64(2), successResponse.Version, "version should be bumped")
+		assert.Equal(t, domain.FarmApi{ID: farmId, Version: 2}.ETag(), updatedEtag, "etag should be of the updated farm")
+
+		findFarmMock.Unset()
+		findFarmByIdMock.Unset()
+		updateFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when if match is stale", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testUpdateName",
+		}
+		farmId := "testId"
+		etag := domain.FarmApi{ID: farmId, Version: 1}.ETag()
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := mockFarmById(farmId, 2)
+
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, etag)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindPrecondition, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeVersionMismatch, errObjectFromResponse.Code, "code should be equal")
+		assert.Equal(t, "failed to update farm", errObjectFromResponse.Message, "message should be equal")
+		assert.Equal(t, errors.New("farm has changed"), errObjectFromResponse.Err, "error should be equal")
+
+		findFarmMock.Unset()
+		findFarmByIdMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when farm changed while updating", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmBind{
+			Name: "testUpdateName",
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ? AND id <> ?", request.Name, farmId).Return(gorm.ErrRecordNotFound)
+		findFarmByIdMock := mockFarmById(farmId, 1)
+		updateFarmMock := farmRepositoryMock.Mock.On("UpdateFarm", mock.Anything).Return(domain.ErrVersionMismatch)
+
+		_, _, errorResponse := farmUsecase.Update(context.Background(), authUser, request, farmId, "")
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindPrecondition, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeVersionMismatch, errObjectFromResponse.Code, "code should be equal")
+
 		findFarmMock.Unset()
 		findFarmByIdMock.Unset()
 		updateFarmMock.Unset()
+		memberMock.Unset()
 	})
 }
 
@@ -198,16 +420,22 @@ func TestGet(t *testing.T) {
 				Name: "testName3",
 			},
 		}
+		query := domain.FarmQuery{
+			PageQuery: domain.PageQuery{Page: 1, PageSize: 3, Sort: "-name"},
+		}
+		paginationResponse := domain.Pagination{Page: 1, PageSize: 3, Total: 4, TotalPages: 2}
+
 		var farms []domain.Farm
-		getFarmsMock := farmRepositoryMock.Mock.On("GetFarms", &farms).Return(nil).Run(func(args mock.Arguments) {
+		getFarmsMock := farmRepositoryMock.Mock.On("FindFarms", &farms, authUser.OrganizationID, authUser.ID, query).Return(paginationResponse, nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*[]domain.Farm)
 			*arg = append(*arg, farmsResponse...)
 		})
 
-		successResponse, errorResponse := farmUsecase.Get()
+		successResponse, pagination, errorResponse := farmUsecase.Get(context.Background(), authUser, query)
 
 		//test result
 		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, paginationResponse, pagination, "pagination should be equal")
 		for i := range successResponse {
 			assert.Equal(t, farmsResponse[i].Name, successResponse[i].Name, "name should be equal")
 			assert.Equal(t, farmsResponse[i].ID, successResponse[i].ID, "id should be equal")
@@ -219,14 +447,14 @@ func TestGet(t *testing.T) {
 	t.Run("should return error when usecase call return error", func(t *testing.T) {
 		//call mock
 		var farms []domain.Farm
-		getFarmsMock := farmRepositoryMock.Mock.On("GetFarms", &farms).Return(errors.New("testError"))
+		getFarmsMock := farmRepositoryMock.Mock.On("FindFarms", &farms, authUser.OrganizationID, authUser.ID, domain.FarmQuery{}).Return(nil, errors.New("testError"))
 
-		_, errorResponse := farmUsecase.Get()
+		_, _, errorResponse := farmUsecase.Get(context.Background(), authUser, domain.FarmQuery{})
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("testError"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusInternalServerError, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObjectFromResponse.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get all farm", errObjectFromResponse.Message, "message should be equal")
 
 		getFarmsMock.Unset()
@@ -235,48 +463,81 @@ func TestGet(t *testing.T) {
 	t.Run("should return error when farm is not exist", func(t *testing.T) {
 		//call mock
 		var farms []domain.Farm
-		getFarmsMock := farmRepositoryMock.Mock.On("GetFarms", &farms).Return(nil)
+		getFarmsMock := farmRepositoryMock.Mock.On("FindFarms", &farms, authUser.OrganizationID, authUser.ID, domain.FarmQuery{}).Return(domain.Pagination{}, nil)
 
-		_, errorResponse := farmUsecase.Get()
+		_, _, errorResponse := farmUsecase.Get(context.Background(), authUser, domain.FarmQuery{})
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("farm not found"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusNotFound, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObjectFromResponse.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get all farm", errObjectFromResponse.Message, "message should be equal")
 
 		getFarmsMock.Unset()
 	})
+
+	t.Run("should return error when sort is invalid", func(t *testing.T) {
+		query := domain.FarmQuery{
+			PageQuery: domain.PageQuery{Sort: "password"},
+		}
+
+		_, _, errorResponse := farmUsecase.Get(context.Background(), authUser, query)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, "failed to get all farm", errObjectFromResponse.Message, "message should be equal")
+	})
+
+	t.Run("should return error when cursor is invalid", func(t *testing.T) {
+		query := domain.FarmQuery{
+			PageQuery: domain.PageQuery{Cursor: "not-a-cursor"},
+		}
+
+		_, _, errorResponse := farmUsecase.Get(context.Background(), authUser, query)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, errors.New("invalid cursor"), errObjectFromResponse.Err, "error should be equal")
+	})
 }
 
 func TestGetFarmById(t *testing.T) {
 	t.Run("should return success", func(t *testing.T) {
 		//call mock
+		memberMock := mockMember("testID", domain.RoleAuditor)
 		farmResponse := domain.FarmApi{
 			ID:   "testID",
 			Name: "farm1",
 			Ponds: []domain.Pond{
 				{
-					ID:     "pondID1",
-					Name:   "pond1",
-					FarmID: "testID",
+					ID:           "pondID1",
+					Name:         "pond1",
+					FarmID:       "testID",
+					SurfaceArea:  1000,
+					AverageDepth: 1.5,
+					Volume:       1500,
 				},
 				{
-					ID:     "pondID2",
-					Name:   "pond2",
-					FarmID: "testID",
+					ID:           "pondID2",
+					Name:         "pond2",
+					FarmID:       "testID",
+					SurfaceArea:  500,
+					AverageDepth: 0.6,
+					Volume:       300,
 				},
 			},
 		}
 		var farm domain.FarmApi
-		getFarmByIdMock := farmRepositoryMock.Mock.On("GetFarmById", &farm, farmResponse.ID).Return(nil).Run(func(args mock.Arguments) {
+		getFarmByIdMock := farmRepositoryMock.Mock.On("GetFarmById", &farm, authUser.OrganizationID, farmResponse.ID).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*domain.FarmApi)
 			arg.ID = farmResponse.ID
 			arg.Name = farmResponse.Name
 			arg.Ponds = farmResponse.Ponds
 		})
 
-		successResponse, errorResponse := farmUsecase.GetFarmById(farmResponse.ID)
+		successResponse, errorResponse := farmUsecase.GetFarmById(context.Background(), authUser, farmResponse.ID)
 
 		//test result
 		assert.Nil(t, errorResponse, "err response should be nil")
@@ -284,6 +545,9 @@ func TestGetFarmById(t *testing.T) {
 		assert.Equal(t, farmResponse.ID, successResponse.ID, "id should be equal")
 		assert.Equal(t, farmResponse.Name, successResponse.Name, "name should be equal")
 
+		assert.Equal(t, 1500.0, successResponse.SurfaceArea, "surface area should be summed")
+		assert.Equal(t, 1800.0, successResponse.Volume, "volume should be summed")
+
 		for i, v := range successResponse.Ponds {
 			assert.Equal(t, v.Name, successResponse.Ponds[i].Name, "pond name should be equal")
 			assert.Equal(t, v.ID, successResponse.Ponds[i].ID, "pond id should be equal")
@@ -291,22 +555,70 @@ func TestGetFarmById(t *testing.T) {
 		}
 
 		getFarmByIdMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when farm is not exist", func(t *testing.T) {
 		//call mock
+		memberMock := mockMember("testID", domain.RoleAuditor)
 		var farm domain.FarmApi
-		getFarmByIdMock := farmRepositoryMock.Mock.On("GetFarmById", &farm, "testID").Return(errors.New("record not found"))
+		getFarmByIdMock := farmRepositoryMock.Mock.On("GetFarmById", &farm, authUser.OrganizationID, "testID").Return(gorm.ErrRecordNotFound)
 
-		_, errorResponse := farmUsecase.GetFarmById("testID")
+		_, errorResponse := farmUsecase.GetFarmById(context.Background(), authUser, "testID")
 
 		//test result
-		errObjectFromResponse := errorResponse.(util.ErrorObject)
+		errObjectFromResponse := errorResponse.(*apperror.Error)
 		assert.Equal(t, errors.New("farm not found"), errObjectFromResponse.Err, "error should be equal")
-		assert.Equal(t, http.StatusNotFound, errObjectFromResponse.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObjectFromResponse.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get farm by id", errObjectFromResponse.Message, "message should be equal")
 
 		getFarmByIdMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return internal error when membership lookup fails", func(t *testing.T) {
+		//call mock
+		memberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, "testID", authUser.ID).Return(errors.New("connection refused"))
+
+		_, errorResponse := farmUsecase.GetFarmById(context.Background(), authUser, "testID")
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, "failed to get farm by id", errObjectFromResponse.Message, "message should be equal")
+
+		memberMock.Unset()
+	})
+
+	t.Run("should map a connection error to timeout when the deadline passed", func(t *testing.T) {
+		// prepare expired request context
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		//call mock
+		memberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, "testID", authUser.ID).Return(driver.ErrBadConn)
+
+		_, errorResponse := farmUsecase.GetFarmById(ctx, authUser, "testID")
+
+		//test result
+		errObject := apperror.FromContext(ctx, apperror.From(errorResponse))
+		assert.Equal(t, apperror.KindTimeout, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to get farm by id", errObject.Message, "message should be equal")
+
+		memberMock.Unset()
+	})
+
+	t.Run("should map a connection error to unavailable", func(t *testing.T) {
+		//call mock
+		memberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, "testID", authUser.ID).Return(driver.ErrBadConn)
+
+		_, errorResponse := farmUsecase.GetFarmById(context.Background(), authUser, "testID")
+
+		//test result
+		errObject := apperror.FromContext(context.Background(), apperror.From(errorResponse))
+		assert.Equal(t, apperror.KindUnavailable, errObject.Kind, "kind should be equal")
+
+		memberMock.Unset()
 	})
 }
 
@@ -316,17 +628,19 @@ func TestDelete(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
-		var farm domain.Farm
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &farm, "id = ?", farmId).Return(nil)
+		memberMock := mockMember(farmId, domain.RoleManager)
+		farm := domain.Farm{ID: farmId, Version: 1}
+		findFarmMock := mockFarmById(farmId, 1)
 		updateFarmMock := farmRepositoryMock.Mock.On("DeleteFarm", &farm).Return(nil)
 
-		errorResponse := farmUsecase.Delete(farmId)
+		errorResponse := farmUsecase.Delete(context.Background(), authUser, farmId, "")
 
 		//test result
 		assert.Nil(t, errorResponse, "err response should be nil")
 
 		findFarmMock.Unset()
 		updateFarmMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when farm not found", func(t *testing.T) {
@@ -334,18 +648,19 @@ func TestDelete(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
-		var farm domain.Farm
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &farm, "id = ?", farmId).Return(errors.New("record not found"))
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := farmRepositoryMock.Mock.On("GetFarmById", &domain.FarmApi{}, authUser.OrganizationID, farmId).Return(gorm.ErrRecordNotFound)
 
-		errorResponse := farmUsecase.Delete(farmId)
+		errorResponse := farmUsecase.Delete(context.Background(), authUser, farmId, "")
 
 		//test result
-		errObject := errorResponse.(util.ErrorObject)
-		assert.Equal(t, http.StatusNotFound, errObject.Code, "status code should be equal")
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to delete farm", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("record not found"), errObject.Err, "error should be equal")
 
 		findFarmMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when sql failed", func(t *testing.T) {
@@ -353,19 +668,198 @@ func TestDelete(t *testing.T) {
 		farmId := "testId"
 
 		//call mock
-		var farm domain.Farm
-		findFarmMock := farmRepositoryMock.Mock.On("FindFarmByCondition", &farm, "id = ?", farmId).Return(nil)
+		memberMock := mockMember(farmId, domain.RoleManager)
+		farm := domain.Farm{ID: farmId, Version: 1}
+		findFarmMock := mockFarmById(farmId, 1)
 		updateFarmMock := farmRepositoryMock.Mock.On("DeleteFarm", &farm).Return(errors.New("sql failed"))
 
-		errorResponse := farmUsecase.Delete(farmId)
+		errorResponse := farmUsecase.Delete(context.Background(), authUser, farmId, "")
 
 		//test result
-		errObject := errorResponse.(util.ErrorObject)
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to delete farm", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("sql failed"), errObject.Err, "error should be equal")
 
 		findFarmMock.Unset()
 		updateFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when if match is stale", func(t *testing.T) {
+		//prepare usecase parameter
+		farmId := "testId"
+		etag := domain.FarmApi{ID: farmId, Version: 1}.ETag()
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findFarmMock := mockFarmById(farmId, 2)
+
+		errorResponse := farmUsecase.Delete(context.Background(), authUser, farmId, etag)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindPrecondition, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to delete farm", errObject.Message, "message should be equal")
+		assert.Equal(t, errors.New("farm has changed"), errObject.Err, "error should be equal")
+
+		findFarmMock.Unset()
+		memberMock.Unset()
+	})
+}
+
+func TestAddMember(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmMemberBind{
+			Email: "Tech@mail.com",
+			Role:  domain.RoleTechnician,
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", "tech@mail.com").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = "techId"
+			arg.OrganizationID = authUser.OrganizationID
+		})
+		findNewMemberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, "techId").Return(gorm.ErrRecordNotFound)
+		createMemberMock := farmRepositoryMock.Mock.On("CreateMember", &domain.FarmMember{FarmID: farmId, UserID: "techId", Role: domain.RoleTechnician}).Return(nil)
+
+		successResponse, errorResponse := farmUsecase.AddMember(context.Background(), authUser, request, farmId)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, "techId", successResponse.UserID, "user id should be equal")
+		assert.Equal(t, domain.RoleTechnician, successResponse.Role, "role should be equal")
+
+		memberMock.Unset()
+		findUserMock.Unset()
+		findNewMemberMock.Unset()
+		createMemberMock.Unset()
+	})
+
+	t.Run("should return error when user is already member", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmMemberBind{
+			Email: "tech@mail.com",
+			Role:  domain.RoleAuditor,
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = "techId"
+			arg.OrganizationID = authUser.OrganizationID
+		})
+		findNewMemberMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, "techId").Return(nil)
+
+		_, errorResponse := farmUsecase.AddMember(context.Background(), authUser, request, farmId)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeMemberExists, errObjectFromResponse.Code, "code should be equal")
+
+		memberMock.Unset()
+		findUserMock.Unset()
+		findNewMemberMock.Unset()
+	})
+
+	t.Run("should return error when user belongs to another organization", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmMemberBind{
+			Email: "tech@mail.com",
+			Role:  domain.RoleTechnician,
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = "techId"
+			arg.OrganizationID = "otherOrgId"
+		})
+
+		_, errorResponse := farmUsecase.AddMember(context.Background(), authUser, request, farmId)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeUserNotFound, errObjectFromResponse.Code, "code should be equal")
+
+		memberMock.Unset()
+		findUserMock.Unset()
+	})
+
+	t.Run("should return error when user is auditor", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.FarmMemberBind{
+			Email: "tech@mail.com",
+			Role:  domain.RoleManager,
+		}
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleAuditor)
+
+		_, errorResponse := farmUsecase.AddMember(context.Background(), authUser, request, farmId)
+
+		//test result
+		errObjectFromResponse := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObjectFromResponse.Kind, "kind should be equal")
+		assert.Equal(t, "failed to add farm member", errObjectFromResponse.Message, "message should be equal")
+
+		memberMock.Unset()
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		//prepare usecase parameter
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		findTargetMock := farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, "techId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.FarmMember)
+			arg.FarmID = farmId
+			arg.UserID = "techId"
+			arg.Role = domain.RoleTechnician
+		})
+		deleteMemberMock := farmRepositoryMock.Mock.On("DeleteMember", &domain.FarmMember{FarmID: farmId, UserID: "techId", Role: domain.RoleTechnician}).Return(nil)
+
+		errorResponse := farmUsecase.RemoveMember(context.Background(), authUser, farmId, "techId")
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+
+		memberMock.Unset()
+		findTargetMock.Unset()
+		deleteMemberMock.Unset()
+	})
+
+	t.Run("should return error when removing last manager", func(t *testing.T) {
+		//prepare usecase parameter
+		farmId := "testId"
+
+		//call mock
+		memberMock := mockMember(farmId, domain.RoleManager)
+		countManagersMock := farmRepositoryMock.Mock.On("CountManagers", farmId).Return(int64(1), nil)
+
+		errorResponse := farmUsecase.RemoveMember(context.Background(), authUser, farmId, authUser.ID)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeLastManager, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to remove farm member", errObject.Message, "message should be equal")
+
+		memberMock.Unset()
+		countManagersMock.Unset()
 	})
 }
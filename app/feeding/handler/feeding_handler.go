@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/feeding/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+type FeedingHandler struct {
+	feedingUsecase usecase.IFeedingUsecase
+}
+
+func NewFeedingHandler(feedingUsecase usecase.IFeedingUsecase) *FeedingHandler {
+	return &FeedingHandler{
+		feedingUsecase: feedingUsecase,
+	}
+}
+
+func (feedingHandler *FeedingHandler) Create(c *gin.Context) {
+	//bind request
+	var request domain.FeedingBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//create feeding
+	feeding, err := feedingHandler.feedingUsecase.Create(c.Request.Context(), middleware.GetAuthUser(c), request, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully create feeding", feeding)
+}
+
+func (feedingHandler *FeedingHandler) GetByPond(c *gin.Context) {
+	//bind query
+	var query domain.FeedingQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//get feedings
+	feedings, err := feedingHandler.feedingUsecase.GetByPond(c.Request.Context(), middleware.GetAuthUser(c), query, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get feedings", feedings)
+}
+
+func (feedingHandler *FeedingHandler) GetSummary(c *gin.Context) {
+	//bind query
+	var query domain.FeedingQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//get feeding summary
+	summary, err := feedingHandler.feedingUsecase.GetSummary(c.Request.Context(), middleware.GetAuthUser(c), query, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get feeding summary", summary)
+}
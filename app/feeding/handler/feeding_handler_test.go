@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	feeding_mock "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var feedingUsecaseMock = feeding_mock.FeedingUsecaseMock{
+	Mock: mock.Mock{},
+}
+
+var feedingHandler = NewFeedingHandler(&feedingUsecaseMock)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("should can create feeding", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.FeedingBind{
+			FedAt:    time.Date(2024, 1, 11, 7, 0, 0, 0, time.UTC),
+			FeedType: "starter",
+			Quantity: 2.5,
+			Session:  domain.FeedingSessionMorning,
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockResponse := domain.Feeding{
+			ID:       "feedingID",
+			CycleID:  "cycleID",
+			FedAt:    requestBody.FedAt,
+			FeedType: requestBody.FeedType,
+			Quantity: requestBody.Quantity,
+			Session:  requestBody.Session,
+		}
+		mockCall := feedingUsecaseMock.Mock.On("Create", authUser, requestBody, "pondID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/feedings", feedingHandler.Create)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/feedings", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusCreated, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully create feeding", responseBody["message"], "message should be equal")
+
+		feedingData := responseBody["data"].(map[string]any)
+		assert.Equal(t, mockResponse.ID, feedingData["id"], "feeding id should be equal")
+		assert.Equal(t, mockResponse.Quantity, feedingData["quantity_kg"], "quantity should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when session invalid", func(t *testing.T) {
+		// prepare request body
+		requestBodyJson := []byte(`{"fed_at":"2024-01-11T07:00:00Z","feed_type":"starter","quantity_kg":2.5,"session":"brunch"}`)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/feedings", feedingHandler.Create)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/feedings", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, "failed to bind request", responseBody["message"], "message should be equal")
+	})
+}
+
+func TestGetSummary(t *testing.T) {
+	t.Run("should can get feeding summary", func(t *testing.T) {
+		// call mock
+		harvestBiomass := float64(2000)
+		fcr := 1.5
+		mockResponse := domain.FeedingSummary{
+			CycleID:        "cycleID",
+			Status:         domain.CycleStatusClosed,
+			FeedingCount:   180,
+			TotalFeed:      1500,
+			InitialBiomass: 1000,
+			HarvestBiomass: &harvestBiomass,
+			FCR:            &fcr,
+		}
+		mockCall := feedingUsecaseMock.Mock.On("GetSummary", authUser, domain.FeedingQuery{CycleID: "cycleID"}, "pondID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/ponds/:pondId/feedings/summary", feedingHandler.GetSummary)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/ponds/pondID/feedings/summary?cycle_id=cycleID", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully get feeding summary", responseBody["message"], "message should be equal")
+
+		summaryData := responseBody["data"].(map[string]any)
+		assert.Equal(t, fcr, summaryData["fcr"], "fcr should be equal")
+		assert.Equal(t, mockResponse.TotalFeed, summaryData["total_feed_kg"], "total feed should be equal")
+
+		mockCall.Unset()
+	})
+}
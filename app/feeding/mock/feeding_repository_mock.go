@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type FeedingRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (feedingRepositoryMock *FeedingRepositoryMock) CreateFeeding(ctx context.Context, feeding *domain.Feeding) error {
+	args := feedingRepositoryMock.Mock.Called(feeding)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (feedingRepositoryMock *FeedingRepositoryMock) FindFeedings(ctx context.Context, feedings *[]domain.Feeding, cycleId string) error {
+	args := feedingRepositoryMock.Mock.Called(feedings, cycleId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (feedingRepositoryMock *FeedingRepositoryMock) FindFeedingTotal(ctx context.Context, total *domain.FeedingTotal, cycleId string) error {
+	args := feedingRepositoryMock.Mock.Called(total, cycleId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type FeedingUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (feedingUsecaseMock *FeedingUsecaseMock) Create(ctx context.Context, authUser domain.AuthUser, request domain.FeedingBind, pondId string) (domain.Feeding, error) {
+	args := feedingUsecaseMock.Mock.Called(authUser, request, pondId)
+
+	if args[1] != nil {
+		return domain.Feeding{}, args[1].(error)
+	}
+
+	return args[0].(domain.Feeding), nil
+}
+
+func (feedingUsecaseMock *FeedingUsecaseMock) GetByPond(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) ([]domain.Feeding, error) {
+	args := feedingUsecaseMock.Mock.Called(authUser, query, pondId)
+
+	if args[1] != nil {
+		return nil, args[1].(error)
+	}
+
+	return args[0].([]domain.Feeding), nil
+}
+
+func (feedingUsecaseMock *FeedingUsecaseMock) GetSummary(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) (domain.FeedingSummary, error) {
+	args := feedingUsecaseMock.Mock.Called(authUser, query, pondId)
+
+	if args[1] != nil {
+		return domain.FeedingSummary{}, args[1].(error)
+	}
+
+	return args[0].(domain.FeedingSummary), nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"gorm.io/gorm"
+)
+
+type IFeedingRepository interface {
+	CreateFeeding(ctx context.Context, feeding *domain.Feeding) error
+	FindFeedings(ctx context.Context, feedings *[]domain.Feeding, cycleId string) error
+	FindFeedingTotal(ctx context.Context, total *domain.FeedingTotal, cycleId string) error
+}
+
+type FeedingRepository struct {
+	db *gorm.DB
+}
+
+func NewFeedingRepository(db *gorm.DB) IFeedingRepository {
+	return &FeedingRepository{
+		db: db,
+	}
+}
+
+func (feedingRepository *FeedingRepository) CreateFeeding(ctx context.Context, feeding *domain.Feeding) error {
+	tx := feedingRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Create(feeding).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (feedingRepository *FeedingRepository) FindFeedings(ctx context.Context, feedings *[]domain.Feeding, cycleId string) error {
+	err := feedingRepository.db.WithContext(ctx).Model(&domain.Feeding{}).Where("cycle_id = ?", cycleId).Order("fed_at desc").Find(feedings).Error
+	return err
+}
+
+func (feedingRepository *FeedingRepository) FindFeedingTotal(ctx context.Context, total *domain.FeedingTotal, cycleId string) error {
+	err := feedingRepository.db.WithContext(ctx).Model(&domain.Feeding{}).Select("COALESCE(SUM(quantity), 0) AS total_feed, COUNT(*) AS feeding_count").Where("cycle_id = ?", cycleId).Scan(total).Error
+	return err
+}
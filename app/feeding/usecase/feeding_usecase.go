@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	cycle_repository "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/repository"
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	feeding_repository "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/repository"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	pond_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/pond/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"gorm.io/gorm"
+)
+
+type IFeedingUsecase interface {
+	Create(ctx context.Context, authUser domain.AuthUser, request domain.FeedingBind, pondId string) (domain.Feeding, error)
+	GetByPond(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) ([]domain.Feeding, error)
+	GetSummary(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) (domain.FeedingSummary, error)
+}
+
+type FeedingUsecase struct {
+	feedingRepository feeding_repository.IFeedingRepository
+	cycleRepository   cycle_repository.ICycleRepository
+	pondRepository    pond_repository.IPondRepository
+	farmRepository    farm_repository.IFarmRepository
+}
+
+func NewFeedingUsecase(feedingRepository feeding_repository.IFeedingRepository, cycleRepository cycle_repository.ICycleRepository, pondRepository pond_repository.IPondRepository, farmRepository farm_repository.IFarmRepository) IFeedingUsecase {
+	return &FeedingUsecase{
+		feedingRepository: feedingRepository,
+		cycleRepository:   cycleRepository,
+		pondRepository:    pondRepository,
+		farmRepository:    farmRepository,
+	}
+}
+
+func (feedingUsecase *FeedingUsecase) Create(ctx context.Context, authUser domain.AuthUser, request domain.FeedingBind, pondId string) (domain.Feeding, error) {
+	// check if user can log data of pond
+	_, err := pond_usecase.AuthorizePond(ctx, feedingUsecase.pondRepository, feedingUsecase.farmRepository, authUser, pondId, domain.PermissionLogData, "failed to create feeding")
+	if err != nil {
+		return domain.Feeding{}, err
+	}
+
+	// feed always goes to the active cycle
+	cycle, err := feedingUsecase.findCycle(ctx, pondId, "", "failed to create feeding")
+	if err != nil {
+		return domain.Feeding{}, err
+	}
+
+	if request.FedAt.Before(cycle.StockedAt) {
+		return domain.Feeding{}, apperror.Validation(apperror.CodeInvalidFeedingDate, "failed to create feeding", errors.New("feeding date is before stocking date"))
+	}
+
+	// create feeding
+	feeding := domain.Feeding{
+		CycleID:  cycle.ID,
+		FedAt:    request.FedAt,
+		FeedType: request.FeedType,
+		Quantity: request.Quantity,
+		Session:  request.Session,
+	}
+	err = feedingUsecase.feedingRepository.CreateFeeding(ctx, &feeding)
+	if err != nil {
+		return domain.Feeding{}, apperror.Internal("failed to create feeding", err)
+	}
+
+	return feeding, nil
+}
+
+func (feedingUsecase *FeedingUsecase) GetByPond(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) ([]domain.Feeding, error) {
+	// check if user can view pond
+	_, err := pond_usecase.AuthorizePond(ctx, feedingUsecase.pondRepository, feedingUsecase.farmRepository, authUser, pondId, domain.PermissionView, "failed to get feedings")
+	if err != nil {
+		return nil, err
+	}
+
+	cycle, err := feedingUsecase.findCycle(ctx, pondId, query.CycleID, "failed to get feedings")
+	if err != nil {
+		return nil, err
+	}
+
+	// get feedings
+	var feedings []domain.Feeding
+	err = feedingUsecase.feedingRepository.FindFeedings(ctx, &feedings, cycle.ID)
+	if err != nil {
+		return nil, apperror.Internal("failed to get feedings", err)
+	}
+
+	// check if feeding exist
+	if len(feedings) == 0 {
+		return nil, apperror.NotFound(apperror.CodeFeedingNotFound, "failed to get feedings", errors.New("feeding not found"))
+	}
+
+	return feedings, nil
+}
+
+func (feedingUsecase *FeedingUsecase) GetSummary(ctx context.Context, authUser domain.AuthUser, query domain.FeedingQuery, pondId string) (domain.FeedingSummary, error) {
+	// check if user can view pond
+	_, err := pond_usecase.AuthorizePond(ctx, feedingUsecase.pondRepository, feedingUsecase.farmRepository, authUser, pondId, domain.PermissionView, "failed to get feeding summary")
+	if err != nil {
+		return domain.FeedingSummary{}, err
+	}
+
+	cycle, err := feedingUsecase.findCycle(ctx, pondId, query.CycleID, "failed to get feeding summary")
+	if err != nil {
+		return domain.FeedingSummary{}, err
+	}
+
+	// sum feedings
+	var total domain.FeedingTotal
+	err = feedingUsecase.feedingRepository.FindFeedingTotal(ctx, &total, cycle.ID)
+	if err != nil {
+		return domain.FeedingSummary{}, apperror.Internal("failed to get feeding summary", err)
+	}
+
+	summary := domain.FeedingSummary{
+		CycleID:        cycle.ID,
+		Status:         cycle.Status,
+		FeedingCount:   total.FeedingCount,
+		TotalFeed:      roundFeed(total.TotalFeed),
+		InitialBiomass: roundFeed(float64(cycle.StockCount) * cycle.AverageWeight / 1000),
+	}
+
+	// biomass is known once the cycle is harvested
+	if cycle.Status == domain.CycleStatusClosed {
+		harvestBiomass := cycle.HarvestBiomass
+		summary.HarvestBiomass = &harvestBiomass
+
+		// fcr is feed per kilogram of biomass gained, undefined without a gain
+		gain := harvestBiomass - summary.InitialBiomass
+		if gain > 0 {
+			fcr := math.Round(summary.TotalFeed/gain*100) / 100
+			summary.FCR = &fcr
+		}
+	}
+
+	return summary, nil
+}
+
+// find cycle of pond by id, empty id means the active cycle
+func (feedingUsecase *FeedingUsecase) findCycle(ctx context.Context, pondId string, cycleId string, message string) (domain.Cycle, error) {
+	var cycle domain.Cycle
+	if cycleId == "" {
+		err := feedingUsecase.cycleRepository.FindCycleByCondition(ctx, &cycle, "pond_id = ? AND status = ?", pondId, domain.CycleStatusActive)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Cycle{}, apperror.NotFound(apperror.CodeNoActiveCycle, message, errors.New("pond has no active cycle"))
+		}
+		if err != nil {
+			return domain.Cycle{}, apperror.Internal(message, err)
+		}
+
+		return cycle, nil
+	}
+
+	err := feedingUsecase.cycleRepository.FindCycleByCondition(ctx, &cycle, "id = ? AND pond_id = ?", cycleId, pondId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Cycle{}, apperror.NotFound(apperror.CodeCycleNotFound, message, errors.New("cycle not found"))
+	}
+	if err != nil {
+		return domain.Cycle{}, apperror.Internal(message, err)
+	}
+
+	return cycle, nil
+}
+
+// keep four decimals like the stored quantities
+func roundFeed(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
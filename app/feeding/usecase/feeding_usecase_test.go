@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cycle_mock "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/mock"
+	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	feeding_mock "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/mock"
+	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var feedingRepository = feeding_mock.FeedingRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var cycleRepository = cycle_mock.CycleRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var pondRepository = pond_mock.PondRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var farmRepository = farm_mock.FarmRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var feedingUsecase = NewFeedingUsecase(&feedingRepository, &cycleRepository, &pondRepository, &farmRepository)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+var stockedAt = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// mock pond of farmID where authUser has role
+func mockPondAccess(pondId string, role domain.Role) (*mock.Call, *mock.Call) {
+	pondCall := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "id = ?", pondId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.Pond)
+		arg.ID = pondId
+		arg.FarmID = "farmID"
+	})
+	memberCall := farmRepository.Mock.On("FindMember", &domain.FarmMember{}, "farmID", authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.Role = role
+	})
+
+	return pondCall, memberCall
+}
+
+// mock active cycle of pondId stocked with 100000 fry of 0.01 gram
+func mockActiveCycle(pondId string) *mock.Call {
+	return cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", pondId, domain.CycleStatusActive).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.Cycle)
+		arg.ID = "cycleID"
+		arg.PondID = pondId
+		arg.StockedAt = stockedAt
+		arg.StockCount = 100000
+		arg.AverageWeight = 0.01
+		arg.Status = domain.CycleStatusActive
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.FeedingBind{
+			FedAt:    stockedAt.AddDate(0, 0, 1),
+			FeedType: "starter",
+			Quantity: 2.5,
+			Session:  domain.FeedingSessionMorning,
+		}
+
+		// call mock
+		feeding := domain.Feeding{
+			CycleID:  "cycleID",
+			FedAt:    request.FedAt,
+			FeedType: request.FeedType,
+			Quantity: request.Quantity,
+			Session:  request.Session,
+		}
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		cycleMock := mockActiveCycle("pondID")
+		createFeedingMock := feedingRepository.Mock.On("CreateFeeding", &feeding).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Feeding)
+			arg.ID = "feedingID"
+		})
+
+		// call usecase
+		successResponse, errorResponse := feedingUsecase.Create(context.Background(), authUser, request, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, "feedingID", successResponse.ID, "feeding id should be equal")
+		assert.Equal(t, "cycleID", successResponse.CycleID, "cycle id should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+		createFeedingMock.Unset()
+	})
+
+	t.Run("should return error when pond has no active cycle", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.FeedingBind{
+			FedAt:    stockedAt.AddDate(0, 0, 1),
+			FeedType: "starter",
+			Quantity: 2.5,
+			Session:  domain.FeedingSessionMorning,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		cycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", "pondID", domain.CycleStatusActive).Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		_, errorResponse := feedingUsecase.Create(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeNoActiveCycle, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to create feeding", errObject.Message, "message should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+	})
+
+	t.Run("should return internal error when cycle lookup fails", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		cycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "pond_id = ? AND status = ?", "pondID", domain.CycleStatusActive).Return(errors.New("connection refused"))
+
+		// call usecase
+		_, errorResponse := feedingUsecase.Create(context.Background(), authUser, domain.FeedingBind{}, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+	})
+
+	t.Run("should return error when feeding is before stocking", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.FeedingBind{
+			FedAt:    stockedAt.AddDate(0, 0, -1),
+			FeedType: "starter",
+			Quantity: 2.5,
+			Session:  domain.FeedingSessionMorning,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		cycleMock := mockActiveCycle("pondID")
+
+		// call usecase
+		_, errorResponse := feedingUsecase.Create(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidFeedingDate, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+	})
+
+	t.Run("should return error when user is auditor", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.FeedingBind{
+			FedAt:    stockedAt.AddDate(0, 0, 1),
+			FeedType: "starter",
+			Quantity: 2.5,
+			Session:  domain.FeedingSessionMorning,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+
+		// call usecase
+		_, errorResponse := feedingUsecase.Create(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+	})
+}
+
+func TestGetByPond(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		// call mock
+		var feedings []domain.Feeding
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		cycleMock := mockActiveCycle("pondID")
+		findFeedingsMock := feedingRepository.Mock.On("FindFeedings", &feedings, "cycleID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.Feeding)
+			*arg = []domain.Feeding{{ID: "feedingID", CycleID: "cycleID"}}
+		})
+
+		// call usecase
+		successResponse, errorResponse := feedingUsecase.GetByPond(context.Background(), authUser, domain.FeedingQuery{}, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Len(t, successResponse, 1, "feedings length should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+		findFeedingsMock.Unset()
+	})
+
+	t.Run("should return error when cycle is not found", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		cycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "otherCycleID", "pondID").Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		_, errorResponse := feedingUsecase.GetByPond(context.Background(), authUser, domain.FeedingQuery{CycleID: "otherCycleID"}, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeCycleNotFound, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+	})
+}
+
+func TestGetSummary(t *testing.T) {
+	t.Run("should return fcr when cycle is harvested", func(t *testing.T) {
+		// call mock
+		harvestedAt := stockedAt.AddDate(0, 3, 0)
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		cycleMock := cycleRepository.Mock.On("FindCycleByCondition", &domain.Cycle{}, "id = ? AND pond_id = ?", "cycleID", "pondID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Cycle)
+			arg.ID = "cycleID"
+			arg.StockCount = 100000
+			arg.AverageWeight = 10
+			arg.Status = domain.CycleStatusClosed
+			arg.HarvestedAt = &harvestedAt
+			arg.HarvestBiomass = 2000
+		})
+		totalMock := feedingRepository.Mock.On("FindFeedingTotal", &domain.FeedingTotal{}, "cycleID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.FeedingTotal)
+			arg.TotalFeed = 1500
+			arg.FeedingCount = 180
+		})
+
+		// call usecase
+		successResponse, errorResponse := feedingUsecase.GetSummary(context.Background(), authUser, domain.FeedingQuery{CycleID: "cycleID"}, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, float64(1500), successResponse.TotalFeed, "total feed should be equal")
+		assert.Equal(t, float64(1000), successResponse.InitialBiomass, "initial biomass should be equal")
+		assert.Equal(t, float64(2000), *successResponse.HarvestBiomass, "harvest biomass should be equal")
+		assert.Equal(t, 1.5, *successResponse.FCR, "fcr should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+		totalMock.Unset()
+	})
+
+	t.Run("should return no fcr when cycle is active", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		cycleMock := mockActiveCycle("pondID")
+		totalMock := feedingRepository.Mock.On("FindFeedingTotal", &domain.FeedingTotal{}, "cycleID").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.FeedingTotal)
+			arg.TotalFeed = 25.5
+			arg.FeedingCount = 10
+		})
+
+		// call usecase
+		successResponse, errorResponse := feedingUsecase.GetSummary(context.Background(), authUser, domain.FeedingQuery{}, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, 25.5, successResponse.TotalFeed, "total feed should be equal")
+		assert.Equal(t, int64(10), successResponse.FeedingCount, "feeding count should be equal")
+		assert.Nil(t, successResponse.HarvestBiomass, "harvest biomass should be nil")
+		assert.Nil(t, successResponse.FCR, "fcr should be nil")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		cycleMock.Unset()
+		totalMock.Unset()
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/reyhanmichiels/AquaFarmManagement/app/pond/usecase"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
 	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 )
 
 type PondHandler struct {
@@ -24,15 +26,14 @@ func (pondHandler *PondHandler) Create(c *gin.Context) {
 	var request domain.PondBind
 	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		util.FailResponse(c, http.StatusBadRequest, "failed to bind request", err)
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
 		return
 	}
 
 	//create pond
-	pond, errObject := pondHandler.pondUsecase.Create(request)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	pond, err := pondHandler.pondUsecase.Create(c.Request.Context(), middleware.GetAuthUser(c), request)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -44,7 +45,7 @@ func (pondHandler *PondHandler) Update(c *gin.Context) {
 	var request domain.PondBind
 	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		util.FailResponse(c, http.StatusBadRequest, "failed to bind request", err)
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
 		return
 	}
 
@@ -52,26 +53,34 @@ func (pondHandler *PondHandler) Update(c *gin.Context) {
 	pondId := c.Param("pondId")
 
 	//create pond
-	pond, errObject := pondHandler.pondUsecase.Update(request, pondId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	pond, etag, err := pondHandler.pondUsecase.Update(c.Request.Context(), middleware.GetAuthUser(c), request, pondId, c.GetHeader("If-Match"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", etag)
 	util.SuccessResponse(c, http.StatusOK, "successfully update pond", pond)
 }
 
 func (pondHandler *PondHandler) Get(c *gin.Context) {
+	// bind query
+	var query domain.PondQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
 	// get ponds
-	ponds, errObject := pondHandler.pondUsecase.Get()
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	ponds, pagination, err := pondHandler.pondUsecase.Get(c.Request.Context(), middleware.GetAuthUser(c), query)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	util.SuccessResponse(c, http.StatusOK, "successfully get all pond", ponds)
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get all pond", ponds, pagination)
 }
 
 func (pondHandler *PondHandler) GetPondById(c *gin.Context) {
@@ -79,10 +88,13 @@ func (pondHandler *PondHandler) GetPondById(c *gin.Context) {
 	pondId := c.Param("pondId")
 
 	// get pond by id
-	pond, errObject := pondHandler.pondUsecase.GetPondById(pondId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	pond, err := pondHandler.pondUsecase.GetPondById(c.Request.Context(), middleware.GetAuthUser(c), pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if util.NotModified(c, pond.ETag()) {
 		return
 	}
 
@@ -94,10 +106,9 @@ func (pondHandler *PondHandler) Delete(c *gin.Context) {
 	pondId := c.Param("pondId")
 
 	// delete pond
-	errObject := pondHandler.pondUsecase.Delete(pondId)
-	if errObject != nil {
-		errObject := errObject.(util.ErrorObject)
-		util.FailResponse(c, errObject.Code, errObject.Message, errObject.Err)
+	err := pondHandler.pondUsecase.Delete(c.Request.Context(), middleware.GetAuthUser(c), pondId, c.GetHeader("If-Match"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
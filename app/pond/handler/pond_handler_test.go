@@ -12,7 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +24,17 @@ var pondUsecaseMock = pond_mock.PondUsecaseMock{
 
 var pondHandler = NewPondHandler(&pondUsecaseMock)
 
+var authUser = domain.AuthUser{
+	ID:    "userId",
+	Email: "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("should can create pond", func(t *testing.T) {
 		// prepare request body
@@ -42,10 +54,12 @@ func TestCreate(t *testing.T) {
 			Name:   requestBody.Name,
 			FarmID: requestBody.FarmID,
 		}
-		mockCall := pondUsecaseMock.Mock.On("Create", requestBody).Return(mockResponse, nil)
+		mockCall := pondUsecaseMock.Mock.On("Create", authUser, requestBody).Return(mockResponse, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/ponds", pondHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -77,9 +91,41 @@ func TestCreate(t *testing.T) {
 		mockCall.Unset()
 	})
 
+	t.Run("should reject when pond type is unknown", func(t *testing.T) {
+		// prepare request body
+		requestBodyJson := []byte(`{"name":"pondName","farm_id":"farmID","type":"lake","surface_area":100}`)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds", pondHandler.Create)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeInvalidRequest, responseBody["code"], "code should be equal")
+	})
+
 	t.Run("should reject when request invalid", func(t *testing.T) {
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/ponds", pondHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -116,15 +162,13 @@ func TestCreate(t *testing.T) {
 		}
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := pondUsecaseMock.Mock.On("Create", requestBody).Return(nil, errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := pondUsecaseMock.Mock.On("Create", authUser, requestBody).Return(nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.POST("/api/ponds", pondHandler.Create)
 
 		response := httptest.NewRecorder()
@@ -143,7 +187,7 @@ func TestCreate(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
@@ -174,10 +218,12 @@ func TestUpdate(t *testing.T) {
 			Name:   requestBody.Name,
 			FarmID: requestBody.FarmID,
 		}
-		mockCall := pondUsecaseMock.Mock.On("Update", requestBody, pondId).Return(mockResponse, nil)
+		mockCall := pondUsecaseMock.Mock.On("Update", authUser, requestBody, pondId, "").Return(mockResponse, `"testEtag"`, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/ponds/:pondId", pondHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -205,6 +251,7 @@ func TestUpdate(t *testing.T) {
 		assert.Equal(t, mockResponse.ID, pondData["id"], "pond id should be equal")
 		assert.Equal(t, mockResponse.Name, pondData["name"], "pond name should be equal")
 		assert.Equal(t, mockResponse.FarmID, pondData["farm_id"], "farm id should be equal")
+		assert.Equal(t, `"testEtag"`, response.Header().Get("ETag"), "etag should be of the updated pond")
 
 		mockCall.Unset()
 	})
@@ -215,6 +262,8 @@ func TestUpdate(t *testing.T) {
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/ponds/:pondId", pondHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -254,15 +303,13 @@ func TestUpdate(t *testing.T) {
 		}
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := pondUsecaseMock.Mock.On("Update", requestBody, pondId).Return(nil, errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := pondUsecaseMock.Mock.On("Update", authUser, requestBody, pondId, "").Return(nil, nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.PUT("/api/ponds/:pondId", pondHandler.Update)
 
 		response := httptest.NewRecorder()
@@ -281,13 +328,62 @@ func TestUpdate(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
 
 		mockCall.Unset()
 	})
+
+	t.Run("should reject when pond changed since if match", func(t *testing.T) {
+		// prepare request param
+		pondId := "pondID"
+
+		// prepare request body
+		requestBody := domain.PondBind{
+			Name:   "pondName",
+			FarmID: "farmID",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		errObject := apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to update pond", errors.New("pond has changed"))
+		mockCall := pondUsecaseMock.Mock.On("Update", authUser, requestBody, pondId, `"stale"`).Return(nil, nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.PUT("/api/ponds/:pondId", pondHandler.Update)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", fmt.Sprintf("/api/ponds/%s", pondId), bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("If-Match", `"stale"`)
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusPreconditionFailed, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeVersionMismatch, responseBody["code"], "code should be equal")
+		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
+
+		mockCall.Unset()
+	})
 }
 
 func TestGetPonds(t *testing.T) {
@@ -310,14 +406,22 @@ func TestGetPonds(t *testing.T) {
 				FarmID: "farmID3",
 			},
 		}
-		mockCall := pondUsecaseMock.Mock.On("Get").Return(mockResponse, nil)
+		query := domain.PondQuery{
+			PageQuery: domain.PageQuery{PageSize: 3, Cursor: "cursor1"},
+			FarmID:    "farmID1",
+		}
+		mockPagination := domain.Pagination{PageSize: 3, Total: 7, NextCursor: "cursor2"}
+
+		mockCall := pondUsecaseMock.Mock.On("Get", authUser, query).Return(mockResponse, mockPagination, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/ponds", pondHandler.Get)
 
 		response := httptest.NewRecorder()
-		request, err := http.NewRequest("GET", "/api/ponds", nil)
+		request, err := http.NewRequest("GET", "/api/ponds?farm_id=farmID1&page_size=3&cursor=cursor1", nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -346,20 +450,23 @@ func TestGetPonds(t *testing.T) {
 			assert.Equal(t, mockResponse[i].FarmID, v["farm_id"], "farm id should be equal")
 		}
 
+		meta := responseBody["meta"].(map[string]any)
+		assert.Equal(t, float64(mockPagination.Total), meta["total"], "total should be equal")
+		assert.Equal(t, mockPagination.NextCursor, meta["next_cursor"], "next cursor should be equal")
+		assert.Equal(t, "/api/ponds?cursor=cursor2&farm_id=farmID1&page_size=3", meta["next"], "next link should be equal")
+
 		mockCall.Unset()
 	})
 
 	t.Run("should reject when usecase call return error", func(t *testing.T) {
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := pondUsecaseMock.Mock.On("Get").Return(nil, errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := pondUsecaseMock.Mock.On("Get", authUser, domain.PondQuery{}).Return(nil, nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/ponds", pondHandler.Get)
 
 		response := httptest.NewRecorder()
@@ -378,7 +485,7 @@ func TestGetPonds(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
@@ -402,10 +509,12 @@ func TestGetPondById(t *testing.T) {
 				ID:   "farmID",
 			},
 		}
-		mockCall := pondUsecaseMock.Mock.On("GetPondById", pondId).Return(mockResponse, nil)
+		mockCall := pondUsecaseMock.Mock.On("GetPondById", authUser, pondId).Return(mockResponse, nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/ponds/:pondId", pondHandler.GetPondById)
 
 		response := httptest.NewRecorder()
@@ -438,6 +547,7 @@ func TestGetPondById(t *testing.T) {
 
 		assert.Equal(t, mockResponse.Farm.ID, farmData["id"], "farm id should be equal")
 		assert.Equal(t, mockResponse.Farm.Name, farmData["name"], "farm name should be equal")
+		assert.Equal(t, mockResponse.ETag(), response.Header().Get("ETag"), "etag should be equal")
 
 		mockCall.Unset()
 	})
@@ -447,15 +557,13 @@ func TestGetPondById(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := pondUsecaseMock.Mock.On("GetPondById", pondId).Return(nil, errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := pondUsecaseMock.Mock.On("GetPondById", authUser, pondId).Return(nil, errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.GET("/api/ponds/:pondId", pondHandler.GetPondById)
 
 		response := httptest.NewRecorder()
@@ -474,7 +582,7 @@ func TestGetPondById(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
@@ -489,10 +597,12 @@ func TestDel(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		mockCall := pondUsecaseMock.Mock.On("Delete", pondId).Return(nil)
+		mockCall := pondUsecaseMock.Mock.On("Delete", authUser, pondId, "").Return(nil)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.DELETE("/api/ponds/:pondId", pondHandler.Delete)
 
 		response := httptest.NewRecorder()
@@ -524,15 +634,13 @@ func TestDel(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		errObject := util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     errors.New("testError"),
-			Message: "test message",
-		}
-		mockCall := pondUsecaseMock.Mock.On("Delete", pondId).Return(errObject)
+		errObject := apperror.Internal("test message", errors.New("testError"))
+		mockCall := pondUsecaseMock.Mock.On("Delete", authUser, pondId, "").Return(errObject)
 
 		// call handler
 		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
 		engine.DELETE("/api/ponds/:pondId", pondHandler.Delete)
 
 		response := httptest.NewRecorder()
@@ -551,7 +659,7 @@ func TestDel(t *testing.T) {
 		}
 
 		// test response
-		assert.Equal(t, errObject.Code, response.Code, "status code should be equal")
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status code should be equal")
 		assert.Equal(t, "error", responseBody["status"], "status should be equal")
 		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
 		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
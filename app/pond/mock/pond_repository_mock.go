@@ -1,6 +1,9 @@
 package mock
 
 import (
+	"context"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,8 +12,8 @@ type PondRepositoryMock struct {
 	Mock mock.Mock
 }
 
-func (pondRepositoryMock *PondRepositoryMock) FindPondByCondition(pond any, condition string, value any) error {
-	args := pondRepositoryMock.Mock.Called(pond, condition, value)
+func (pondRepositoryMock *PondRepositoryMock) FindPondByCondition(ctx context.Context, pond any, organizationId string, condition string, values ...any) error {
+	args := pondRepositoryMock.Mock.Called(append([]any{pond, organizationId, condition}, values...)...)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -19,7 +22,7 @@ func (pondRepositoryMock *PondRepositoryMock) FindPondByCondition(pond any, cond
 	return nil
 }
 
-func (pondRepositoryMock *PondRepositoryMock) CreatePond(pond *domain.Pond) error {
+func (pondRepositoryMock *PondRepositoryMock) CreatePond(ctx context.Context, pond *domain.Pond) error {
 	args := pondRepositoryMock.Mock.Called(pond)
 
 	if args[0] != nil {
@@ -29,7 +32,37 @@ func (pondRepositoryMock *PondRepositoryMock) CreatePond(pond *domain.Pond) erro
 	return nil
 }
 
-func (pondRepositoryMock *PondRepositoryMock) UpdatePond(pond *domain.Pond) error {
+func (pondRepositoryMock *PondRepositoryMock) UpdatePond(ctx context.Context, pond *domain.Pond) error {
+	args := pondRepositoryMock.Mock.Called(pond)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (pondRepositoryMock *PondRepositoryMock) FindPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.PondQuery) (domain.Pagination, error) {
+	args := pondRepositoryMock.Mock.Called(ponds, organizationId, userId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
+
+func (pondRepositoryMock *PondRepositoryMock) GetPondById(ctx context.Context, pond *domain.PondApi, organizationId string, pondId string) error {
+	args := pondRepositoryMock.Mock.Called(pond, organizationId, pondId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (pondRepositoryMock *PondRepositoryMock) DeletePond(ctx context.Context, pond *domain.Pond) error {
 	args := pondRepositoryMock.Mock.Called(pond)
 
 	if args[0] != nil {
@@ -39,8 +72,18 @@ func (pondRepositoryMock *PondRepositoryMock) UpdatePond(pond *domain.Pond) erro
 	return nil
 }
 
-func (pondRepositoryMock *PondRepositoryMock) GetPonds(ponds *[]domain.Pond) error {
-	args := pondRepositoryMock.Mock.Called(ponds)
+func (pondRepositoryMock *PondRepositoryMock) FindDeletedPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error) {
+	args := pondRepositoryMock.Mock.Called(ponds, organizationId, userId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
+
+func (pondRepositoryMock *PondRepositoryMock) FindDeletedPond(ctx context.Context, pond *domain.Pond, organizationId string, pondId string) error {
+	args := pondRepositoryMock.Mock.Called(pond, organizationId, pondId)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -49,8 +92,8 @@ func (pondRepositoryMock *PondRepositoryMock) GetPonds(ponds *[]domain.Pond) err
 	return nil
 }
 
-func (pondRepositoryMock *PondRepositoryMock) GetPondById(pond *domain.PondApi, pondId string) error {
-	args := pondRepositoryMock.Mock.Called(pond, pondId)
+func (pondRepositoryMock *PondRepositoryMock) RestorePond(ctx context.Context, pond *domain.Pond) error {
+	args := pondRepositoryMock.Mock.Called(pond)
 
 	if args[0] != nil {
 		return args[0].(error)
@@ -59,7 +102,7 @@ func (pondRepositoryMock *PondRepositoryMock) GetPondById(pond *domain.PondApi,
 	return nil
 }
 
-func (pondRepositoryMock *PondRepositoryMock) DeletePond(pond *domain.Pond) error {
+func (pondRepositoryMock *PondRepositoryMock) PurgePond(ctx context.Context, pond *domain.Pond) error {
 	args := pondRepositoryMock.Mock.Called(pond)
 
 	if args[0] != nil {
@@ -68,3 +111,13 @@ func (pondRepositoryMock *PondRepositoryMock) DeletePond(pond *domain.Pond) erro
 
 	return nil
 }
+
+func (pondRepositoryMock *PondRepositoryMock) PurgePonds(ctx context.Context, before time.Time) (int64, error) {
+	args := pondRepositoryMock.Mock.Called(before)
+
+	if args[1] != nil {
+		return 0, args[1].(error)
+	}
+
+	return args[0].(int64), nil
+}
@@ -1,8 +1,9 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -10,51 +11,51 @@ type PondUsecaseMock struct {
 	Mock mock.Mock
 }
 
-func (pondUsecaseMock *PondUsecaseMock) Create(request domain.PondBind) (domain.Pond, any) {
-	args := pondUsecaseMock.Mock.Called(request)
+func (pondUsecaseMock *PondUsecaseMock) Create(ctx context.Context, authUser domain.AuthUser, request domain.PondBind) (domain.Pond, error) {
+	args := pondUsecaseMock.Mock.Called(authUser, request)
 
 	if args[1] != nil {
-		return domain.Pond{}, args[1].(util.ErrorObject)
+		return domain.Pond{}, args[1].(error)
 	}
 
 	return args[0].(domain.Pond), nil
 }
 
-func (pondUsecaseMock *PondUsecaseMock) Update(request domain.PondBind, pondId string) (domain.Pond, any) {
-	args := pondUsecaseMock.Mock.Called(request, pondId)
+func (pondUsecaseMock *PondUsecaseMock) Update(ctx context.Context, authUser domain.AuthUser, request domain.PondBind, pondId string, ifMatch string) (domain.Pond, string, error) {
+	args := pondUsecaseMock.Mock.Called(authUser, request, pondId, ifMatch)
 
-	if args[1] != nil {
-		return domain.Pond{}, args[1].(util.ErrorObject)
+	if args[2] != nil {
+		return domain.Pond{}, "", args[2].(error)
 	}
 
-	return args[0].(domain.Pond), nil
+	return args[0].(domain.Pond), args[1].(string), nil
 }
 
-func (pondUsecaseMock *PondUsecaseMock) Get() ([]domain.Pond, any) {
-	args := pondUsecaseMock.Mock.Called()
+func (pondUsecaseMock *PondUsecaseMock) Get(ctx context.Context, authUser domain.AuthUser, query domain.PondQuery) ([]domain.Pond, domain.Pagination, error) {
+	args := pondUsecaseMock.Mock.Called(authUser, query)
 
-	if args[1] != nil {
-		return []domain.Pond{}, args[1].(util.ErrorObject)
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
 	}
 
-	return args[0].([]domain.Pond), nil
+	return args[0].([]domain.Pond), args[1].(domain.Pagination), nil
 }
 
-func (pondUsecaseMock *PondUsecaseMock) GetPondById(pondId string) (domain.PondApi, any) {
-	args := pondUsecaseMock.Mock.Called(pondId)
+func (pondUsecaseMock *PondUsecaseMock) GetPondById(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.PondApi, error) {
+	args := pondUsecaseMock.Mock.Called(authUser, pondId)
 
 	if args[1] != nil {
-		return domain.PondApi{}, args[1].(util.ErrorObject)
+		return domain.PondApi{}, args[1].(error)
 	}
 
 	return args[0].(domain.PondApi), nil
 }
 
-func (pondUsecaseMock *PondUsecaseMock) Delete(pondId string) any {
-	args := pondUsecaseMock.Mock.Called(pondId)
+func (pondUsecaseMock *PondUsecaseMock) Delete(ctx context.Context, authUser domain.AuthUser, pondId string, ifMatch string) error {
+	args := pondUsecaseMock.Mock.Called(authUser, pondId, ifMatch)
 
 	if args[0] != nil {
-		return args[0].(util.ErrorObject)
+		return args[0].(error)
 	}
 
 	return nil
@@ -1,17 +1,26 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
 	"gorm.io/gorm"
 )
 
 type IPondRepository interface {
-	FindPondByCondition(pond any, condition string, value any) error
-	CreatePond(pond *domain.Pond) error
-	UpdatePond(pond *domain.Pond) error
-	GetPonds(ponds *[]domain.Pond) error
-	GetPondById(pond *domain.PondApi, pondId string) error
-	DeletePond(pond *domain.Pond) error
+	FindPondByCondition(ctx context.Context, pond any, organizationId string, condition string, values ...any) error
+	CreatePond(ctx context.Context, pond *domain.Pond) error
+	UpdatePond(ctx context.Context, pond *domain.Pond) error
+	FindPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.PondQuery) (domain.Pagination, error)
+	GetPondById(ctx context.Context, pond *domain.PondApi, organizationId string, pondId string) error
+	DeletePond(ctx context.Context, pond *domain.Pond) error
+	FindDeletedPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error)
+	FindDeletedPond(ctx context.Context, pond *domain.Pond, organizationId string, pondId string) error
+	RestorePond(ctx context.Context, pond *domain.Pond) error
+	PurgePond(ctx context.Context, pond *domain.Pond) error
+	PurgePonds(ctx context.Context, before time.Time) (int64, error)
 }
 
 type PondRepository struct {
@@ -24,13 +33,13 @@ func NewPondRepository(db *gorm.DB) IPondRepository {
 	}
 }
 
-func (pondRepository *PondRepository) FindPondByCondition(pond any, condition string, value any) error {
-	err := pondRepository.db.Model(&domain.Pond{}).First(pond, condition, value).Error
+func (pondRepository *PondRepository) FindPondByCondition(ctx context.Context, pond any, organizationId string, condition string, values ...any) error {
+	err := pondRepository.db.WithContext(ctx).Model(&domain.Pond{}).Where("farm_id IN (?)", pondRepository.organizationFarms(ctx, organizationId)).Where(condition, values...).First(pond).Error
 	return err
 }
 
-func (pondRepository *PondRepository) CreatePond(pond *domain.Pond) error {
-	tx := pondRepository.db.Begin()
+func (pondRepository *PondRepository) CreatePond(ctx context.Context, pond *domain.Pond) error {
+	tx := pondRepository.db.WithContext(ctx).Begin()
 
 	err := tx.Create(pond).Error
 	if err != nil {
@@ -38,42 +47,114 @@ func (pondRepository *PondRepository) CreatePond(pond *domain.Pond) error {
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit().Error
 }
 
-func (pondRepository *PondRepository) UpdatePond(pond *domain.Pond) error {
-	tx := pondRepository.db.Begin()
+// UpdatePond saves the pond if it is still at the version it was read at and
+// bumps the version, ErrVersionMismatch when it changed in the meantime
+func (pondRepository *PondRepository) UpdatePond(ctx context.Context, pond *domain.Pond) error {
+	tx := pondRepository.db.WithContext(ctx).Begin()
 
-	err := tx.Save(pond).Error
-	if err != nil {
+	version := pond.Version
+	pond.Version++
+	result := tx.Model(pond).Select("farm_id", "name", "type", "shape", "surface_area", "average_depth", "volume", "version", "updated_at").Where("version = ?", version).Updates(pond)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrVersionMismatch
+	}
+	if result.Error != nil {
 		tx.Rollback()
-		return err
+		pond.Version = version
+		return result.Error
+	}
+
+	return tx.Commit().Error
+}
+
+func (pondRepository *PondRepository) FindPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.PondQuery) (domain.Pagination, error) {
+	memberFarms := pondRepository.db.WithContext(ctx).Model(&domain.FarmMember{}).Select("farm_id").Where("user_id = ?", userId)
+	db := pondRepository.db.WithContext(ctx).Model(&domain.Pond{}).
+		Where("ponds.farm_id IN (?)", pondRepository.organizationFarms(ctx, organizationId)).
+		Where("ponds.farm_id IN (?)", memberFarms)
+	if query.FarmID != "" {
+		db = db.Where("ponds.farm_id = ?", query.FarmID)
 	}
+	db = util.FilterList(db, "ponds", query.ListFilter)
 
-	tx.Commit()
-	return nil
+	return util.Paginate(db, "ponds", query.PageQuery, domain.ListSortFields, ponds)
 }
 
-func (pondRepository *PondRepository) GetPonds(ponds *[]domain.Pond) error {
-	err := pondRepository.db.Find(&ponds).Error
+func (pondRepository *PondRepository) GetPondById(ctx context.Context, pond *domain.PondApi, organizationId string, pondId string) error {
+	err := pondRepository.db.WithContext(ctx).Model(&domain.Pond{}).Preload("Farm").Where("farm_id IN (?)", pondRepository.organizationFarms(ctx, organizationId)).First(pond, "id = ?", pondId).Error
 	return err
 }
 
-func (pondRepository *PondRepository) GetPondById(pond *domain.PondApi, pondId string) error {
-	err := pondRepository.db.Model(&domain.Pond{}).Preload("Farm").First(pond, "id = ?", pondId).Error
+// DeletePond soft deletes the pond, ErrVersionMismatch when it changed since
+// it was read
+func (pondRepository *PondRepository) DeletePond(ctx context.Context, pond *domain.Pond) error {
+	tx := pondRepository.db.WithContext(ctx).Begin()
+
+	result := tx.Where("version = ?", pond.Version).Delete(pond)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrVersionMismatch
+	}
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	return tx.Commit().Error
+}
+
+// FindDeletedPonds lists the deleted ponds of farms not deleted, ponds of a
+// deleted farm are restored and purged with it
+func (pondRepository *PondRepository) FindDeletedPonds(ctx context.Context, ponds *[]domain.Pond, organizationId string, userId string, query domain.TrashQuery) (domain.Pagination, error) {
+	memberFarms := pondRepository.db.WithContext(ctx).Model(&domain.FarmMember{}).Select("farm_id").Where("user_id = ?", userId)
+	db := pondRepository.db.WithContext(ctx).Unscoped().Model(&domain.Pond{}).
+		Where("ponds.deleted_at IS NOT NULL").
+		Where("ponds.farm_id IN (?)", pondRepository.organizationFarms(ctx, organizationId)).
+		Where("ponds.farm_id IN (?)", memberFarms)
+
+	return util.Paginate(db, "ponds", query.PageQuery, domain.TrashSortFields, ponds)
+}
+
+func (pondRepository *PondRepository) FindDeletedPond(ctx context.Context, pond *domain.Pond, organizationId string, pondId string) error {
+	err := pondRepository.db.WithContext(ctx).Unscoped().Where("farm_id IN (?) AND deleted_at IS NOT NULL", pondRepository.organizationFarms(ctx, organizationId)).First(pond, "id = ?", pondId).Error
 	return err
 }
 
-func (pondRepository *PondRepository) DeletePond(pond *domain.Pond) error {
-	tx := pondRepository.db.Begin()
+func (pondRepository *PondRepository) RestorePond(ctx context.Context, pond *domain.Pond) error {
+	tx := pondRepository.db.WithContext(ctx).Begin()
 
-	err := tx.Delete(pond).Error
+	err := tx.Unscoped().Model(pond).UpdateColumn("deleted_at", nil).Error
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit().Error
+}
+
+// PurgePond permanently deletes the pond, everything recorded on it goes
+// with it through the foreign keys
+func (pondRepository *PondRepository) PurgePond(ctx context.Context, pond *domain.Pond) error {
+	tx := pondRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Unscoped().Delete(pond).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgePonds permanently deletes the ponds deleted before the cutoff
+func (pondRepository *PondRepository) PurgePonds(ctx context.Context, before time.Time) (int64, error) {
+	result := pondRepository.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&domain.Pond{})
+	return result.RowsAffected, result.Error
+}
+
+// ponds belong to an organization through their farm
+func (pondRepository *PondRepository) organizationFarms(ctx context.Context, organizationId string) *gorm.DB {
+	return pondRepository.db.WithContext(ctx).Model(&domain.Farm{}).Select("id").Where("organization_id = ?", organizationId)
 }
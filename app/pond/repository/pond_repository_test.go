@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database/databasetest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// farm of organization with user as its manager
+func createFarm(t *testing.T, db *gorm.DB, organizationId string, userId string, name string) domain.Farm {
+	farm := domain.Farm{
+		OrganizationID: organizationId,
+		Name:           name,
+	}
+	err := db.Create(&farm).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Create(&domain.FarmMember{FarmID: farm.ID, UserID: userId, Role: domain.RoleManager}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return farm
+}
+
+func createPond(t *testing.T, db *gorm.DB, farmId string, name string) domain.Pond {
+	pond := domain.Pond{
+		FarmID:       farmId,
+		Name:         name,
+		Type:         domain.PondTypeEarthen,
+		Shape:        domain.PondShapeRectangular,
+		SurfaceArea:  100,
+		AverageDepth: 1.5,
+		Volume:       150,
+	}
+	err := NewPondRepository(db).CreatePond(context.Background(), &pond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pond
+}
+
+func TestCreatePond(t *testing.T) {
+	t.Run("should create pond with its sizes", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+
+		// create pond
+		pond := createPond(t, db, farm.ID, "test_pond")
+
+		//test stored pond
+		var stored domain.Pond
+		err := db.First(&stored, "id = ?", pond.ID).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, 100.0, stored.SurfaceArea, "surface area should be equal")
+		assert.Equal(t, 1.5, stored.AverageDepth, "average depth should be equal")
+		assert.Equal(t, 150.0, stored.Volume, "volume should be equal")
+	})
+
+	t.Run("should reject duplicate name within a farm", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		otherFarm := createFarm(t, db, organization.ID, user.ID, "other_farm")
+		createPond(t, db, farm.ID, "test_pond")
+		pondRepository := NewPondRepository(db)
+
+		// create ponds with the same name
+		duplicateErr := pondRepository.CreatePond(context.Background(), &domain.Pond{FarmID: farm.ID, Name: "test_pond"})
+		otherErr := pondRepository.CreatePond(context.Background(), &domain.Pond{FarmID: otherFarm.ID, Name: "test_pond"})
+
+		//test uniqueness
+		assert.ErrorIs(t, duplicateErr, gorm.ErrDuplicatedKey, "duplicate in the farm should fail")
+		assert.Nil(t, otherErr, "same name in another farm should succeed")
+	})
+
+	t.Run("should reject pond of unknown farm", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+
+		// create pond
+		err := NewPondRepository(db).CreatePond(context.Background(), &domain.Pond{FarmID: "00000000-0000-0000-0000-000000000000", Name: "test_pond"})
+
+		//test foreign key
+		assert.NotNil(t, err, "error should not be nil")
+	})
+}
+
+func TestFindPondByCondition(t *testing.T) {
+	t.Run("should only find ponds of the organization", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		otherOrganization := databasetest.CreateOrganization(t, db, "other_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+		pondRepository := NewPondRepository(db)
+
+		// find pond from both organizations
+		var found, other domain.Pond
+		err := pondRepository.FindPondByCondition(context.Background(), &found, organization.ID, "id = ?", pond.ID)
+		otherErr := pondRepository.FindPondByCondition(context.Background(), &other, otherOrganization.ID, "id = ?", pond.ID)
+
+		//test scope
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, pond.Name, found.Name, "name should be equal")
+		assert.ErrorIs(t, otherErr, gorm.ErrRecordNotFound, "other organization should not find the pond")
+	})
+
+	t.Run("should only find other ponds with the name when the pond is excluded", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+		other := createPond(t, db, farm.ID, "other_pond")
+		pondRepository := NewPondRepository(db)
+
+		// look for the name of pond apart from pond and from other
+		condition := "farm_id = ? AND name = ? AND id <> ?"
+		err := pondRepository.FindPondByCondition(context.Background(), &domain.Pond{}, organization.ID, condition, farm.ID, pond.Name, pond.ID)
+		otherErr := pondRepository.FindPondByCondition(context.Background(), &domain.Pond{}, organization.ID, condition, farm.ID, pond.Name, other.ID)
+
+		//test duplicate check
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "pond should not be a duplicate of itself")
+		assert.Nil(t, otherErr, "name should be taken for other pond")
+	})
+}
+
+func TestFindPonds(t *testing.T) {
+	t.Run("should list ponds of member farms", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		otherUser := databasetest.CreateUser(t, db, organization.ID, "other@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		secondFarm := createFarm(t, db, organization.ID, user.ID, "second_farm")
+		otherFarm := createFarm(t, db, organization.ID, otherUser.ID, "other_farm")
+		createPond(t, db, farm.ID, "first_pond")
+		createPond(t, db, secondFarm.ID, "second_pond")
+		createPond(t, db, otherFarm.ID, "other_pond")
+		deleted := createPond(t, db, farm.ID, "deleted_pond")
+		pondRepository := NewPondRepository(db)
+		err := pondRepository.DeletePond(context.Background(), &deleted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// list every pond, then the ponds of one farm
+		var ponds []domain.Pond
+		pagination, err := pondRepository.FindPonds(context.Background(), &ponds, organization.ID, user.ID, domain.PondQuery{})
+		var farmPonds []domain.Pond
+		farmPagination, farmErr := pondRepository.FindPonds(context.Background(), &farmPonds, organization.ID, user.ID, domain.PondQuery{FarmID: secondFarm.ID})
+
+		//test listing
+		assert.Nil(t, err, "error should be nil")
+		assert.Nil(t, farmErr, "error should be nil")
+		assert.Equal(t, int64(2), pagination.Total, "total should count ponds of member farms")
+		assert.Len(t, ponds, 2, "deleted and other ponds should not be listed")
+		assert.Equal(t, int64(1), farmPagination.Total, "total should count ponds of the farm")
+		if assert.Len(t, farmPonds, 1) {
+			assert.Equal(t, "second_pond", farmPonds[0].Name, "name should be equal")
+		}
+	})
+}
+
+func TestGetPondById(t *testing.T) {
+	t.Run("should preload the farm", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+
+		// get pond
+		var pondApi domain.PondApi
+		err := NewPondRepository(db).GetPondById(context.Background(), &pondApi, organization.ID, pond.ID)
+
+		//test farm
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, farm.ID, pondApi.Farm.ID, "farm should be preloaded")
+		assert.Equal(t, farm.Name, pondApi.Farm.Name, "farm should be preloaded")
+	})
+}
+
+func TestUpdatePond(t *testing.T) {
+	t.Run("should save the changed pond", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+
+		// rename pond
+		pond.Name = "renamed_pond"
+		err := NewPondRepository(db).UpdatePond(context.Background(), &pond)
+
+		//test stored pond
+		var stored domain.Pond
+		db.First(&stored, "id = ?", pond.ID)
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, "renamed_pond", stored.Name, "name should be updated")
+		assert.Equal(t, int64(2), stored.Version, "version should be bumped")
+	})
+
+	t.Run("should reject pond changed since it was read", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+		pondRepository := NewPondRepository(db)
+		stale := pond
+		pond.Name = "first_rename"
+		if err := pondRepository.UpdatePond(context.Background(), &pond); err != nil {
+			t.Fatal(err)
+		}
+
+		// rename and delete stale pond
+		stale.Name = "second_rename"
+		updateErr := pondRepository.UpdatePond(context.Background(), &stale)
+		deleteErr := pondRepository.DeletePond(context.Background(), &stale)
+
+		//test first rename kept
+		var stored domain.Pond
+		db.First(&stored, "id = ?", pond.ID)
+		assert.ErrorIs(t, updateErr, domain.ErrVersionMismatch, "update error should be version mismatch")
+		assert.ErrorIs(t, deleteErr, domain.ErrVersionMismatch, "delete error should be version mismatch")
+		assert.Equal(t, "first_rename", stored.Name, "first rename should be kept")
+	})
+}
+
+func TestDeletePond(t *testing.T) {
+	t.Run("should soft delete pond and free its name", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+		pondRepository := NewPondRepository(db)
+
+		// delete pond
+		err := pondRepository.DeletePond(context.Background(), &pond)
+
+		//test soft delete
+		assert.Nil(t, err, "error should be nil")
+
+		var found domain.Pond
+		findErr := pondRepository.FindPondByCondition(context.Background(), &found, organization.ID, "id = ?", pond.ID)
+		assert.ErrorIs(t, findErr, gorm.ErrRecordNotFound, "pond should be hidden")
+
+		var count int64
+		db.Unscoped().Model(&domain.Pond{}).Where("id = ? AND deleted_at IS NOT NULL", pond.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "pond should be kept as deleted")
+
+		createErr := pondRepository.CreatePond(context.Background(), &domain.Pond{FarmID: farm.ID, Name: "test_pond"})
+		assert.Nil(t, createErr, "name of the deleted pond should be reusable")
+	})
+}
+
+func TestFindDeletedPonds(t *testing.T) {
+	t.Run("should list deleted ponds of farms not deleted", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		deletedFarm := createFarm(t, db, organization.ID, user.ID, "deleted_farm")
+		createPond(t, db, farm.ID, "kept_pond")
+		pondRepository := NewPondRepository(db)
+		for _, pond := range []domain.Pond{createPond(t, db, farm.ID, "deleted_pond"), createPond(t, db, deletedFarm.ID, "farm_pond")} {
+			err := pondRepository.DeletePond(context.Background(), &pond)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := db.Delete(&deletedFarm).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// list deleted ponds
+		var ponds []domain.Pond
+		pagination, err := pondRepository.FindDeletedPonds(context.Background(), &ponds, organization.ID, user.ID, domain.TrashQuery{})
+
+		//test listing
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(1), pagination.Total, "total should count deleted ponds")
+		if assert.Len(t, ponds, 1) {
+			assert.Equal(t, "deleted_pond", ponds[0].Name, "ponds of deleted farms should not be listed")
+		}
+	})
+}
+
+func TestRestorePond(t *testing.T) {
+	t.Run("should restore deleted pond", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		pond := createPond(t, db, farm.ID, "test_pond")
+		pondRepository := NewPondRepository(db)
+		err := pondRepository.DeletePond(context.Background(), &pond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// restore pond
+		var deleted domain.Pond
+		err = pondRepository.FindDeletedPond(context.Background(), &deleted, organization.ID, pond.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pondRepository.RestorePond(context.Background(), &deleted)
+
+		//test restored pond
+		assert.Nil(t, err, "error should be nil")
+
+		var restored domain.Pond
+		findErr := pondRepository.FindPondByCondition(context.Background(), &restored, organization.ID, "id = ?", pond.ID)
+		assert.Nil(t, findErr, "pond should be restored")
+		deletedErr := pondRepository.FindDeletedPond(context.Background(), &domain.Pond{}, organization.ID, pond.ID)
+		assert.ErrorIs(t, deletedErr, gorm.ErrRecordNotFound, "pond should not be deleted anymore")
+	})
+}
+
+func TestPurgePonds(t *testing.T) {
+	t.Run("should permanently delete ponds deleted before the cutoff", func(t *testing.T) {
+		// prepare database
+		db := databasetest.NewDB(t)
+		organization := databasetest.CreateOrganization(t, db, "test_org")
+		user := databasetest.CreateUser(t, db, organization.ID, "user@mail.com")
+		farm := createFarm(t, db, organization.ID, user.ID, "test_farm")
+		old := createPond(t, db, farm.ID, "old_pond")
+		recent := createPond(t, db, farm.ID, "recent_pond")
+		pondRepository := NewPondRepository(db)
+		for _, pond := range []domain.Pond{old, recent} {
+			err := pondRepository.DeletePond(context.Background(), &pond)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		cycle := domain.Cycle{PondID: old.ID, Species: "tilapia", StockedAt: time.Now(), StockCount: 100, AverageWeight: 1, Status: domain.CycleStatusActive}
+		err := db.Create(&cycle).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		cutoff := time.Now().Add(-time.Hour)
+		err = db.Unscoped().Model(&domain.Pond{}).Where("id = ?", old.ID).UpdateColumn("deleted_at", cutoff.Add(-time.Hour)).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// purge ponds
+		purged, err := pondRepository.PurgePonds(context.Background(), cutoff)
+
+		//test purge and cascade
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(1), purged, "only the old pond should be purged")
+
+		var count int64
+		db.Unscoped().Model(&domain.Pond{}).Where("id = ?", recent.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "recent pond should stay")
+		db.Model(&domain.Cycle{}).Where("pond_id = ?", old.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "cycles should be purged with their pond")
+	})
+}
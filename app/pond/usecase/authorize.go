@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	farm_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"gorm.io/gorm"
+)
+
+// AuthorizePond loads a pond of the user organization and checks the user
+// role on its farm grants the permission
+func AuthorizePond(ctx context.Context, pondRepository pond_repository.IPondRepository, farmRepository farm_repository.IFarmRepository, authUser domain.AuthUser, pondId string, permission domain.Permission, message string) (domain.Pond, error) {
+	var pond domain.Pond
+	isPondExist := pondRepository.FindPondByCondition(ctx, &pond, authUser.OrganizationID, "id = ?", pondId)
+	if errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, apperror.NotFound(apperror.CodePondNotFound, message, errors.New("pond not found"))
+	}
+	if isPondExist != nil {
+		return domain.Pond{}, apperror.Internal(message, isPondExist)
+	}
+
+	err := farm_usecase.Authorize(ctx, farmRepository, authUser.ID, pond.FarmID, permission, message)
+	if err != nil {
+		return domain.Pond{}, err
+	}
+
+	return pond, nil
+}
@@ -1,21 +1,27 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"net/http"
+	"math"
 
 	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	farm_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
 	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
 	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type IPondUsecase interface {
-	Create(request domain.PondBind) (domain.Pond, any)
-	Update(request domain.PondBind, pondId string) (domain.Pond, any)
-	Get() ([]domain.Pond, any)
-	GetPondById(pondId string) (domain.PondApi, any)
-	Delete(pondId string) any
+	Create(ctx context.Context, authUser domain.AuthUser, request domain.PondBind) (domain.Pond, error)
+	Update(ctx context.Context, authUser domain.AuthUser, request domain.PondBind, pondId string, ifMatch string) (domain.Pond, string, error)
+	Get(ctx context.Context, authUser domain.AuthUser, query domain.PondQuery) ([]domain.Pond, domain.Pagination, error)
+	GetPondById(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.PondApi, error)
+	Delete(ctx context.Context, authUser domain.AuthUser, pondId string, ifMatch string) error
 }
 
 type PondUsecase struct {
@@ -30,147 +36,213 @@ func NewPondUsecase(pondRepository pond_repository.IPondRepository, farmReposito
 	}
 }
 
-func (pondUsecase *PondUsecase) Create(request domain.PondBind) (domain.Pond, any) {
-	// check for duplicate entry
-	isPondExist := pondUsecase.pondRepository.FindPondByCondition(&domain.Pond{}, "name = ?", request.Name)
-	if isPondExist == nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusConflict,
-			Err:     errors.New("pond name is already used"),
-			Message: "failed to create pond",
-		}
+func (pondUsecase *PondUsecase) Create(ctx context.Context, authUser domain.AuthUser, request domain.PondBind) (domain.Pond, error) {
+	// check if user can manage farm, before anything about it is revealed
+	err := farm_usecase.Authorize(ctx, pondUsecase.farmRepository, authUser.ID, request.FarmID, domain.PermissionManage, "failed to create pond")
+	if err != nil {
+		return domain.Pond{}, err
 	}
 
 	//check if farm exist
-	isFarmExist := pondUsecase.farmRepository.FindFarmByCondition(&domain.Farm{}, "id = ?", request.FarmID)
+	isFarmExist := pondUsecase.farmRepository.FindFarmByCondition(ctx, &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID)
+	if errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, apperror.Validation(apperror.CodeFarmNotFound, "failed to create pond", errors.New("farm is not found"))
+	}
 	if isFarmExist != nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusBadRequest,
-			Err:     errors.New("farm is not found"),
-			Message: "failed to create pond",
-		}
+		return domain.Pond{}, apperror.Internal("failed to create pond", isFarmExist)
+	}
+
+	// check for duplicate entry
+	isPondExist := pondUsecase.pondRepository.FindPondByCondition(ctx, &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name)
+	if isPondExist == nil {
+		return domain.Pond{}, apperror.Conflict(apperror.CodePondNameTaken, "failed to create pond", errors.New("pond name is already used"))
+	}
+	if !errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, apperror.Internal("failed to create pond", isPondExist)
 	}
 
 	// create pond
-	pond := domain.Pond{
-		Name:   request.Name,
-		FarmID: request.FarmID,
+	var pond domain.Pond
+	applyPondBind(&pond, request)
+	err = pondUsecase.pondRepository.CreatePond(ctx, &pond)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.Pond{}, apperror.Conflict(apperror.CodePondNameTaken, "failed to create pond", errors.New("pond name is already used"))
 	}
-	err := pondUsecase.pondRepository.CreatePond(&pond)
 	if err != nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to create pond",
-		}
+		return domain.Pond{}, apperror.Internal("failed to create pond", err)
 	}
 
 	return pond, nil
 }
 
-func (pondUsecase *PondUsecase) Update(request domain.PondBind, pondId string) (domain.Pond, any) {
-	// check for duplicate entry
-	isPondExist := pondUsecase.pondRepository.FindPondByCondition(&domain.Pond{}, "name = ?", request.Name)
-	if isPondExist == nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusConflict,
-			Err:     errors.New("pond name is already used"),
-			Message: "failed to update pond",
+// Update replaces the pond, ifMatch is the If-Match header, if any, the pond
+// must still match. It returns the pond with its new entity tag
+func (pondUsecase *PondUsecase) Update(ctx context.Context, authUser domain.AuthUser, request domain.PondBind, pondId string, ifMatch string) (domain.Pond, string, error) {
+	// check if pond exist
+	var current domain.PondApi
+	isPondExist := pondUsecase.pondRepository.GetPondById(ctx, &current, authUser.OrganizationID, pondId)
+	if errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, "", apperror.NotFound(apperror.CodePondNotFound, "failed to update pond", errors.New("pond not found"))
+	}
+	if isPondExist != nil {
+		return domain.Pond{}, "", apperror.Internal("failed to update pond", isPondExist)
+	}
+
+	// check if user can manage current and target farm, before anything about
+	// the target is revealed
+	farmIds := []string{current.FarmID}
+	if request.FarmID != current.FarmID {
+		farmIds = append(farmIds, request.FarmID)
+	}
+	for _, farmId := range farmIds {
+		err := farm_usecase.Authorize(ctx, pondUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to update pond")
+		if err != nil {
+			return domain.Pond{}, "", err
 		}
 	}
 
 	// check if farm exist
-	isFarmExist := pondUsecase.farmRepository.FindFarmByCondition(&domain.Farm{}, "id = ?", request.FarmID)
+	var farm domain.Farm
+	isFarmExist := pondUsecase.farmRepository.FindFarmByCondition(ctx, &farm, authUser.OrganizationID, "id = ?", request.FarmID)
+	if errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, "", apperror.Validation(apperror.CodeFarmNotFound, "failed to update pond", errors.New("farm is not found"))
+	}
 	if isFarmExist != nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusBadRequest,
-			Err:     errors.New("farm is not found"),
-			Message: "failed to update pond",
-		}
+		return domain.Pond{}, "", apperror.Internal("failed to update pond", isFarmExist)
 	}
 
-	// check if pond exist
-	var pond domain.Pond
-	pondUsecase.pondRepository.FindPondByCondition(&pond, "id = ?", pondId)
+	// check for duplicate entry, other than the pond itself
+	isPondExist = pondUsecase.pondRepository.FindPondByCondition(ctx, &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId)
+	if isPondExist == nil {
+		return domain.Pond{}, "", apperror.Conflict(apperror.CodePondNameTaken, "failed to update pond", errors.New("pond name is already used"))
+	}
+	if !errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, "", apperror.Internal("failed to update pond", isPondExist)
+	}
 
-	pond.ID = pondId
-	pond.Name = request.Name
-	pond.FarmID = request.FarmID
+	// check if pond is unchanged since the client read it
+	if ifMatch != "" && !util.MatchETag(ifMatch, current.ETag()) {
+		return domain.Pond{}, "", apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to update pond", errors.New("pond has changed"))
+	}
+
+	pond := domain.Pond{
+		ID:        current.ID,
+		Version:   current.Version,
+		CreatedAt: current.CreatedAt,
+	}
+	applyPondBind(&pond, request)
 
 	// update pond
-	err := pondUsecase.pondRepository.UpdatePond(&pond)
+	err := pondUsecase.pondRepository.UpdatePond(ctx, &pond)
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		return domain.Pond{}, "", apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to update pond", errors.New("pond has changed"))
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.Pond{}, "", apperror.Conflict(apperror.CodePondNameTaken, "failed to update pond", errors.New("pond name is already used"))
+	}
 	if err != nil {
-		return domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to update pond",
-		}
+		return domain.Pond{}, "", apperror.Internal("failed to update pond", err)
 	}
-	return pond, nil
+
+	// entity tag of the pond as it now reads by id
+	updated := domain.PondApi{ID: pond.ID, Version: pond.Version, Farm: farm}
+
+	return pond, updated.ETag(), nil
 }
 
-func (pondUsecase *PondUsecase) Get() ([]domain.Pond, any) {
+func (pondUsecase *PondUsecase) Get(ctx context.Context, authUser domain.AuthUser, query domain.PondQuery) ([]domain.Pond, domain.Pagination, error) {
+	// validate sort and cursor
+	err := util.ValidatePageQuery(query.PageQuery, domain.ListSortFields)
+	if err != nil {
+		return []domain.Pond{}, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get all pond", err)
+	}
+
 	// get ponds
 	var ponds []domain.Pond
-	err := pondUsecase.pondRepository.GetPonds(&ponds)
+	pagination, err := pondUsecase.pondRepository.FindPonds(ctx, &ponds, authUser.OrganizationID, authUser.ID, query)
 	if err != nil {
-		return []domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to get all pond",
-		}
+		return []domain.Pond{}, domain.Pagination{}, apperror.Internal("failed to get all pond", err)
 	}
 
 	// check if pond exist
 	if len(ponds) == 0 {
-		return []domain.Pond{}, util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     errors.New("pond not found"),
-			Message: "failed to get all pond",
-		}
+		return []domain.Pond{}, domain.Pagination{}, apperror.NotFound(apperror.CodePondNotFound, "failed to get all pond", errors.New("pond not found"))
 	}
 
-	return ponds, nil
+	return ponds, pagination, nil
 }
 
-func (pondUsecase *PondUsecase) GetPondById(pondId string) (domain.PondApi, any) {
+func (pondUsecase *PondUsecase) GetPondById(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.PondApi, error) {
 	// get ponds
 	var pond domain.PondApi
-	isPondExist := pondUsecase.pondRepository.GetPondById(&pond, pondId)
+	isPondExist := pondUsecase.pondRepository.GetPondById(ctx, &pond, authUser.OrganizationID, pondId)
 
 	// check if pond exist
+	if errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.PondApi{}, apperror.NotFound(apperror.CodePondNotFound, "failed to get pond by id", errors.New("pond not found"))
+	}
 	if isPondExist != nil {
-		return domain.PondApi{}, util.ErrorObject{
-			Code:    http.StatusNotFound,
-			Err:     errors.New("pond not found"),
-			Message: "failed to get pond by id",
-		}
+		return domain.PondApi{}, apperror.Internal("failed to get pond by id", isPondExist)
+	}
+
+	// check if user can view farm
+	err := farm_usecase.Authorize(ctx, pondUsecase.farmRepository, authUser.ID, pond.FarmID, domain.PermissionView, "failed to get pond by id")
+	if err != nil {
+		return domain.PondApi{}, err
 	}
 
 	return pond, nil
 }
 
-func (pondUsecase *PondUsecase) Delete(pondId string) any {
-	var pond domain.Pond
+// Delete soft deletes the pond, ifMatch is the If-Match header, if any, the
+// pond must still match
+func (pondUsecase *PondUsecase) Delete(ctx context.Context, authUser domain.AuthUser, pondId string, ifMatch string) error {
+	var current domain.PondApi
 	// check if pond exist
-	isPondExist := pondUsecase.pondRepository.FindPondByCondition(&pond, "id = ?", pondId)
+	isPondExist := pondUsecase.pondRepository.GetPondById(ctx, &current, authUser.OrganizationID, pondId)
+	if errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodePondNotFound, "failed to delete pond", errors.New("pond not found"))
+	}
 	if isPondExist != nil {
-		return util.ErrorObject{
-			Code:    http.StatusBadRequest,
-			Err:     errors.New("pond not found"),
-			Message: "failed to delete pond",
-		}
+		return apperror.Internal("failed to delete pond", isPondExist)
+	}
+
+	// check if user can manage farm
+	err := farm_usecase.Authorize(ctx, pondUsecase.farmRepository, authUser.ID, current.FarmID, domain.PermissionManage, "failed to delete pond")
+	if err != nil {
+		return err
+	}
+
+	// check if pond is unchanged since the client read it
+	if ifMatch != "" && !util.MatchETag(ifMatch, current.ETag()) {
+		return apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to delete pond", errors.New("pond has changed"))
 	}
 
 	//delete pond
-	err := pondUsecase.pondRepository.DeletePond(&pond)
+	pond := domain.Pond{ID: current.ID, FarmID: current.FarmID, Version: current.Version}
+	err = pondUsecase.pondRepository.DeletePond(ctx, &pond)
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		return apperror.PreconditionFailed(apperror.CodeVersionMismatch, "failed to delete pond", errors.New("pond has changed"))
+	}
 	if err != nil {
-		return util.ErrorObject{
-			Code:    http.StatusInternalServerError,
-			Err:     err,
-			Message: "failed to delete pond",
-		}
+		return apperror.Internal("failed to delete pond", err)
 	}
 
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"POND_ID": pond.ID,
+	}).Info("Pond deleted")
+
 	return nil
 }
+
+// copy request into pond with sizes normalized to square meters and meters
+func applyPondBind(pond *domain.Pond, request domain.PondBind) {
+	pond.Name = request.Name
+	pond.FarmID = request.FarmID
+	pond.Type = request.Type
+	pond.Shape = request.Shape
+	pond.SurfaceArea = util.ToSquareMeters(request.SurfaceArea, request.SurfaceAreaUnit)
+	pond.AverageDepth = util.ToMeters(request.AverageDepth, request.DepthUnit)
+	pond.Volume = math.Round(pond.SurfaceArea*pond.AverageDepth*10000) / 10000
+}
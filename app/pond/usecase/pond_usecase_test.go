@@ -1,16 +1,17 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"net/http"
 	"testing"
 
 	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
 	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 var pondRepository = pond_mock.PondRepositoryMock{
@@ -23,6 +24,33 @@ var farmRepository = farm_mock.FarmRepositoryMock{
 
 var pondUsecase = NewPondUsecase(&pondRepository, &farmRepository)
 
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// mock membership of authUser in farm with role
+func mockMember(farmId string, role domain.Role) *mock.Call {
+	return farmRepository.Mock.On("FindMember", &domain.FarmMember{}, farmId, authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.FarmID = farmId
+		arg.UserID = authUser.ID
+		arg.Role = role
+	})
+}
+
+// mock pond of authUser's organization at version, its farm at version 1
+func mockPondById(pondId string, farmId string, version int64) *mock.Call {
+	return pondRepository.Mock.On("GetPondById", &domain.PondApi{}, authUser.OrganizationID, pondId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.PondApi)
+		arg.ID = pondId
+		arg.FarmID = farmId
+		arg.Farm = domain.Farm{ID: farmId, Version: 1}
+		arg.Version = version
+	})
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("should return success", func(t *testing.T) {
 		// prepare usecase parameter
@@ -37,15 +65,16 @@ func TestCreate(t *testing.T) {
 			FarmID: request.FarmID,
 		}
 
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 		createPondMock := pondRepository.Mock.On("CreatePond", &pond).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*domain.Pond)
 			arg.ID = "pondID"
 		})
 
 		// call usecase
-		successResponse, errorResponse := pondUsecase.Create(request)
+		successResponse, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
@@ -56,6 +85,51 @@ func TestCreate(t *testing.T) {
 		findPondMock.Unset()
 		findFarmMock.Unset()
 		createPondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should normalize pond size", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:            "pondName",
+			FarmID:          "farmID",
+			Type:            domain.PondTypeEarthen,
+			Shape:           domain.PondShapeRectangular,
+			SurfaceArea:     0.5,
+			SurfaceAreaUnit: "ha",
+			AverageDepth:    120,
+			DepthUnit:       "cm",
+		}
+
+		// call mock
+		pond := domain.Pond{
+			Name:         request.Name,
+			FarmID:       request.FarmID,
+			Type:         request.Type,
+			Shape:        request.Shape,
+			SurfaceArea:  5000,
+			AverageDepth: 1.2,
+			Volume:       6000,
+		}
+
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
+		createPondMock := pondRepository.Mock.On("CreatePond", &pond).Return(nil)
+
+		// call usecase
+		successResponse, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, 5000.0, successResponse.SurfaceArea, "surface area should be in square meters")
+		assert.Equal(t, 1.2, successResponse.AverageDepth, "average depth should be in meters")
+		assert.Equal(t, 6000.0, successResponse.Volume, "volume should be in cubic meters")
+
+		findPondMock.Unset()
+		findFarmMock.Unset()
+		createPondMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when duplicate entry", func(t *testing.T) {
@@ -66,19 +140,48 @@ func TestCreate(t *testing.T) {
 		}
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(nil)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		// call usecase
-		_, errorResponse := pondUsecase.Create(request)
+		_, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusConflict, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodePondNameTaken, errObject.Code, "code should be equal")
 		assert.Equal(t, "failed to create pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("pond name is already used"), errObject.Err, "error should be equal")
 
 		findPondMock.Unset()
+		findFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return not found before duplicate check when user is not a member", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:   "pondName",
+			FarmID: "farmID",
+		}
+
+		// call mock
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(nil)
+		memberMock := farmRepository.Mock.On("FindMember", &domain.FarmMember{}, request.FarmID, authUser.ID).Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		_, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeFarmNotFound, errObject.Code, "code should be equal")
+
+		findPondMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when farm is not found", func(t *testing.T) {
@@ -89,21 +192,51 @@ func TestCreate(t *testing.T) {
 		}
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(errors.New("farm is not found"))
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(gorm.ErrRecordNotFound)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		// call usecase
-		_, errorResponse := pondUsecase.Create(request)
+		_, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusBadRequest, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to create pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("farm is not found"), errObject.Err, "error should be equal")
 
 		findPondMock.Unset()
 		findFarmMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when name is taken concurrently", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:   "pondName",
+			FarmID: "farmID",
+		}
+
+		// call mock
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
+		createPondMock := pondRepository.Mock.On("CreatePond", mock.Anything).Return(gorm.ErrDuplicatedKey)
+
+		// call usecase
+		_, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodePondNameTaken, errObject.Code, "code should be equal")
+
+		findPondMock.Unset()
+		findFarmMock.Unset()
+		createPondMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when failed to create pond", func(t *testing.T) {
@@ -119,23 +252,25 @@ func TestCreate(t *testing.T) {
 			FarmID: request.FarmID,
 		}
 
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", request.FarmID, request.Name).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 		createPondMock := pondRepository.Mock.On("CreatePond", &pond).Return(errors.New("testError"))
 
 		// call usecase
-		_, errorResponse := pondUsecase.Create(request)
+		_, errorResponse := pondUsecase.Create(context.Background(), authUser, request)
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to create pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
 
 		findPondMock.Unset()
 		findFarmMock.Unset()
 		createPondMock.Unset()
+		memberMock.Unset()
 	})
 }
 
@@ -150,32 +285,86 @@ func TestUpdate(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Farm)
+			arg.ID = request.FarmID
+			arg.Version = 3
+		})
 
 		var pond domain.Pond
 
-		findPondByIdMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "id = ?", pondId).Return(nil)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 1)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		pond.ID = pondId
 		pond.Name = request.Name
 		pond.FarmID = request.FarmID
+		pond.Version = 1
 
-		updatePondMock := pondRepository.Mock.On("UpdatePond", &pond).Return(nil)
+		updatePondMock := pondRepository.Mock.On("UpdatePond", &pond).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Pond)
+			arg.Version = 2
+		})
 
 		// call usecase
-		successResponse, errorResponse := pondUsecase.Update(request, pondId)
+		successResponse, etag, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
 		assert.Equal(t, pondId, successResponse.ID, "pond id should be equal")
 		assert.Equal(t, request.Name, successResponse.Name, "pond name should be equal")
 		assert.Equal(t, request.FarmID, successResponse.FarmID, "farm id should be equal")
+		assert.Equal(t, domain.PondApi{ID: pondId, Version: 2, Farm: domain.Farm{ID: request.FarmID, Version: 3}}.ETag(), etag, "etag should be of the updated pond")
+
+		findPondMock.Unset()
+		findFarmMock.Unset()
+		findPondByIdMock.Unset()
+		updatePondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should update size and type without renaming", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:         "pondName",
+			FarmID:       "farmID",
+			Type:         domain.PondTypeConcrete,
+			Shape:        domain.PondShapeRectangular,
+			SurfaceArea:  200,
+			AverageDepth: 2,
+		}
+
+		pondId := "pondID"
+
+		// call mock
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		findPondByIdMock := pondRepository.Mock.On("GetPondById", &domain.PondApi{}, authUser.OrganizationID, pondId).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.PondApi)
+			arg.ID = pondId
+			arg.FarmID = request.FarmID
+			arg.Name = request.Name
+			arg.Type = domain.PondTypeEarthen
+			arg.Version = 1
+		})
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
+		updatePondMock := pondRepository.Mock.On("UpdatePond", mock.Anything).Return(nil)
+
+		// call usecase
+		successResponse, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, request.Name, successResponse.Name, "pond name should be kept")
+		assert.Equal(t, domain.PondTypeConcrete, successResponse.Type, "pond type should be updated")
+		assert.Equal(t, 400.0, successResponse.Volume, "pond volume should be updated")
 
 		findPondMock.Unset()
 		findFarmMock.Unset()
 		findPondByIdMock.Unset()
 		updatePondMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when duplicate entry", func(t *testing.T) {
@@ -188,18 +377,25 @@ func TestUpdate(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(nil)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 1)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		// call usecase
-		_, errorResponse := pondUsecase.Update(request, pondId)
-		errObject := errorResponse.(util.ErrorObject)
+		_, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
+		errObject := errorResponse.(*apperror.Error)
 
 		//test response
-		assert.Equal(t, http.StatusConflict, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodePondNameTaken, errObject.Code, "code should be equal")
 		assert.Equal(t, "failed to update pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("pond name is already used"), errObject.Err, "error should be equal")
 
 		findPondMock.Unset()
+		findFarmMock.Unset()
+		findPondByIdMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when farm is not found", func(t *testing.T) {
@@ -212,21 +408,25 @@ func TestUpdate(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(errors.New("farm is not found"))
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(gorm.ErrRecordNotFound)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 1)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		// call usecase
-		_, errorResponse := pondUsecase.Update(request, pondId)
+		_, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusBadRequest, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to update pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("farm is not found"), errObject.Err, "error should be equal")
 
 		findPondMock.Unset()
 		findFarmMock.Unset()
+		findPondByIdMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when failed to update pond", func(t *testing.T) {
@@ -239,26 +439,28 @@ func TestUpdate(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "name = ?", request.Name).Return(errors.New("pond is not found"))
-		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, "id = ?", request.FarmID).Return(nil)
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
 
 		var pond domain.Pond
 
-		findPondByIdMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, "id = ?", pondId).Return(nil)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 1)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
 
 		pond.ID = pondId
 		pond.Name = request.Name
 		pond.FarmID = request.FarmID
+		pond.Version = 1
 
 		updatePondMock := pondRepository.Mock.On("UpdatePond", &pond).Return(errors.New("testError"))
 
 		// call usecase
-		_, errorResponse := pondUsecase.Update(request, pondId)
+		_, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to update pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
 
@@ -266,6 +468,71 @@ func TestUpdate(t *testing.T) {
 		findFarmMock.Unset()
 		findPondByIdMock.Unset()
 		updatePondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when if match is stale", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:   "pondName",
+			FarmID: "farmID",
+		}
+
+		pondId := "pondID"
+		etag := domain.PondApi{ID: pondId, Version: 1, Farm: domain.Farm{ID: request.FarmID, Version: 1}}.ETag()
+
+		// call mock
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 2)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
+
+		// call usecase
+		_, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, etag)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindPrecondition, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeVersionMismatch, errObject.Code, "code should be equal")
+		assert.Equal(t, errors.New("pond has changed"), errObject.Err, "error should be equal")
+
+		findPondMock.Unset()
+		findFarmMock.Unset()
+		findPondByIdMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when pond changed while updating", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.PondBind{
+			Name:   "pondName",
+			FarmID: "farmID",
+		}
+
+		pondId := "pondID"
+
+		// call mock
+		findPondMock := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ? AND id <> ?", request.FarmID, request.Name, pondId).Return(gorm.ErrRecordNotFound)
+		findFarmMock := farmRepository.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "id = ?", request.FarmID).Return(nil)
+		findPondByIdMock := mockPondById(pondId, request.FarmID, 1)
+		memberMock := mockMember(request.FarmID, domain.RoleManager)
+		updatePondMock := pondRepository.Mock.On("UpdatePond", mock.Anything).Return(domain.ErrVersionMismatch)
+
+		// call usecase
+		_, _, errorResponse := pondUsecase.Update(context.Background(), authUser, request, pondId, "")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindPrecondition, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to update pond", errObject.Message, "message should be equal")
+
+		findPondMock.Unset()
+		findFarmMock.Unset()
+		findPondByIdMock.Unset()
+		updatePondMock.Unset()
+		memberMock.Unset()
 	})
 }
 
@@ -289,17 +556,24 @@ func TestGet(t *testing.T) {
 				FarmID: "farmID3",
 			},
 		}
+		query := domain.PondQuery{
+			PageQuery: domain.PageQuery{PageSize: 3},
+			FarmID:    "farmID1",
+		}
+		paginationResponse := domain.Pagination{Page: 1, PageSize: 3, Total: 3, TotalPages: 1}
+
 		var ponds []domain.Pond
-		getPondsMock := pondRepository.Mock.On("GetPonds", &ponds).Return(nil).Run(func(args mock.Arguments) {
+		getPondsMock := pondRepository.Mock.On("FindPonds", &ponds, authUser.OrganizationID, authUser.ID, query).Return(paginationResponse, nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*[]domain.Pond)
 			*arg = append(*arg, pondsResponse...)
 		})
 
 		// call usecase
-		successResponse, errorResponse := pondUsecase.Get()
+		successResponse, pagination, errorResponse := pondUsecase.Get(context.Background(), authUser, query)
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, paginationResponse, pagination, "pagination should be equal")
 		for i, v := range successResponse {
 			assert.Equal(t, pondsResponse[i].ID, v.ID, "pond id should be equal")
 			assert.Equal(t, pondsResponse[i].Name, v.Name, "pond name should be equal")
@@ -312,15 +586,15 @@ func TestGet(t *testing.T) {
 	t.Run("should return error when pond not found", func(t *testing.T) {
 		// call mock
 		var ponds []domain.Pond
-		getPondsMock := pondRepository.Mock.On("GetPonds", &ponds).Return(nil)
+		getPondsMock := pondRepository.Mock.On("FindPonds", &ponds, authUser.OrganizationID, authUser.ID, domain.PondQuery{}).Return(domain.Pagination{}, nil)
 
 		// call usecase
-		_, errorResponse := pondUsecase.Get()
+		_, _, errorResponse := pondUsecase.Get(context.Background(), authUser, domain.PondQuery{})
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusNotFound, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get all pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("pond not found"), errObject.Err, "error should be equal")
 
@@ -330,19 +604,32 @@ func TestGet(t *testing.T) {
 	t.Run("should return error when fail get ponds", func(t *testing.T) {
 		// call mock
 		var ponds []domain.Pond
-		getPondsMock := pondRepository.Mock.On("GetPonds", &ponds).Return(errors.New("testError"))
+		getPondsMock := pondRepository.Mock.On("FindPonds", &ponds, authUser.OrganizationID, authUser.ID, domain.PondQuery{}).Return(nil, errors.New("testError"))
 
 		// call usecase
-		_, errorResponse := pondUsecase.Get()
+		_, _, errorResponse := pondUsecase.Get(context.Background(), authUser, domain.PondQuery{})
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get all pond", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
 		getPondsMock.Unset()
 	})
+
+	t.Run("should return error when sort is invalid", func(t *testing.T) {
+		// call usecase
+		_, _, errorResponse := pondUsecase.Get(context.Background(), authUser, domain.PondQuery{
+			PageQuery: domain.PageQuery{Sort: "-farm"},
+		})
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to get all pond", errObject.Message, "message should be equal")
+	})
 }
 
 func TestGetPondById(t *testing.T) {
@@ -361,16 +648,17 @@ func TestGetPondById(t *testing.T) {
 			},
 		}
 		var pond domain.PondApi
-		getPondsMock := pondRepository.Mock.On("GetPondById", &pond, pondId).Return(nil).Run(func(args mock.Arguments) {
+		getPondsMock := pondRepository.Mock.On("GetPondById", &pond, authUser.OrganizationID, pondId).Return(nil).Run(func(args mock.Arguments) {
 			arg := args[0].(*domain.PondApi)
 			arg.ID = pondResponse.ID
 			arg.Name = pondResponse.Name
 			arg.FarmID = pondResponse.FarmID
 			arg.Farm = pondResponse.Farm
 		})
+		memberMock := mockMember(pondResponse.FarmID, domain.RoleAuditor)
 
 		// call usecase
-		successResponse, errorResponse := pondUsecase.GetPondById(pondId)
+		successResponse, errorResponse := pondUsecase.GetPondById(context.Background(), authUser, pondId)
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
@@ -381,6 +669,7 @@ func TestGetPondById(t *testing.T) {
 		assert.Equal(t, pondResponse.Farm, successResponse.Farm, "farm should be equal")
 
 		getPondsMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when pond is not found", func(t *testing.T) {
@@ -389,15 +678,15 @@ func TestGetPondById(t *testing.T) {
 
 		// call mock
 		var pond domain.PondApi
-		getPondsMock := pondRepository.Mock.On("GetPondById", &pond, pondId).Return(errors.New(""))
+		getPondsMock := pondRepository.Mock.On("GetPondById", &pond, authUser.OrganizationID, pondId).Return(gorm.ErrRecordNotFound)
 
 		// call usecase
-		_, errorResponse := pondUsecase.GetPondById(pondId)
+		_, errorResponse := pondUsecase.GetPondById(context.Background(), authUser, pondId)
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusNotFound, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
 		assert.Equal(t, "failed to get pond by id", errObject.Message, "message should be equal")
 		assert.Equal(t, errors.New("pond not found"), errObject.Err, "error should be equal")
 
@@ -411,18 +700,19 @@ func TestDelete(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		var pond domain.Pond
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &pond, "id = ?", pondId).Return(nil)
-		deletePondMock := pondRepository.Mock.On("DeletePond", &pond).Return(nil)
+		findPondMock := mockPondById(pondId, "farmID", 1)
+		memberMock := mockMember("farmID", domain.RoleManager)
+		deletePondMock := pondRepository.Mock.On("DeletePond", &domain.Pond{ID: pondId, FarmID: "farmID", Version: 1}).Return(nil)
 
 		// call usecase
-		errorResponse := pondUsecase.Delete(pondId)
+		errorResponse := pondUsecase.Delete(context.Background(), authUser, pondId, "")
 
 		//test response
 		assert.Nil(t, errorResponse, "error response should be nil")
 
 		findPondMock.Unset()
 		deletePondMock.Unset()
+		memberMock.Unset()
 	})
 
 	t.Run("should return error when pond is not found", func(t *testing.T) {
@@ -430,16 +720,15 @@ func TestDelete(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		var pond domain.Pond
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &pond, "id = ?", pondId).Return(errors.New(""))
+		findPondMock := pondRepository.Mock.On("GetPondById", &domain.PondApi{}, authUser.OrganizationID, pondId).Return(gorm.ErrRecordNotFound)
 
 		// call usecase
-		errorResponse := pondUsecase.Delete(pondId)
+		errorResponse := pondUsecase.Delete(context.Background(), authUser, pondId, "")
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusBadRequest, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
 		assert.Equal(t, errors.New("pond not found"), errObject.Err, "error should be equal")
 		assert.Equal(t, "failed to delete pond", errObject.Message, "message should be equal")
 
@@ -451,21 +740,64 @@ func TestDelete(t *testing.T) {
 		pondId := "pondID"
 
 		// call mock
-		var pond domain.Pond
-		findPondMock := pondRepository.Mock.On("FindPondByCondition", &pond, "id = ?", pondId).Return(nil)
-		deletePondMock := pondRepository.Mock.On("DeletePond", &pond).Return(errors.New("testError"))
+		findPondMock := mockPondById(pondId, "farmID", 1)
+		memberMock := mockMember("farmID", domain.RoleManager)
+		deletePondMock := pondRepository.Mock.On("DeletePond", &domain.Pond{ID: pondId, FarmID: "farmID", Version: 1}).Return(errors.New("testError"))
 
 		// call usecase
-		errorResponse := pondUsecase.Delete(pondId)
+		errorResponse := pondUsecase.Delete(context.Background(), authUser, pondId, "")
 
 		//test response
-		errObject := errorResponse.(util.ErrorObject)
+		errObject := errorResponse.(*apperror.Error)
 
-		assert.Equal(t, http.StatusInternalServerError, errObject.Code, "status code should be equal")
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
 		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
 		assert.Equal(t, "failed to delete pond", errObject.Message, "message should be equal")
 
 		findPondMock.Unset()
 		deletePondMock.Unset()
+		memberMock.Unset()
+	})
+	t.Run("should return error when user is technician", func(t *testing.T) {
+		//prepare usecase parameter
+		pondId := "pondID"
+
+		// call mock
+		findPondMock := mockPondById(pondId, "farmID", 1)
+		memberMock := mockMember("farmID", domain.RoleTechnician)
+
+		// call usecase
+		errorResponse := pondUsecase.Delete(context.Background(), authUser, pondId, "")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeForbidden, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to delete pond", errObject.Message, "message should be equal")
+
+		findPondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should delete pond matching if match", func(t *testing.T) {
+		//prepare usecase parameter
+		pondId := "pondID"
+		etag := domain.PondApi{ID: pondId, Version: 1, Farm: domain.Farm{ID: "farmID", Version: 1}}.ETag()
+
+		// call mock
+		findPondMock := mockPondById(pondId, "farmID", 1)
+		memberMock := mockMember("farmID", domain.RoleManager)
+		deletePondMock := pondRepository.Mock.On("DeletePond", &domain.Pond{ID: pondId, FarmID: "farmID", Version: 1}).Return(nil)
+
+		// call usecase
+		errorResponse := pondUsecase.Delete(context.Background(), authUser, pondId, etag)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+
+		findPondMock.Unset()
+		deletePondMock.Unset()
+		memberMock.Unset()
 	})
 }
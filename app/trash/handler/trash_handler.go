@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/trash/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+type TrashHandler struct {
+	trashUsecase usecase.ITrashUsecase
+}
+
+func NewTrashHandler(trashUsecase usecase.ITrashUsecase) *TrashHandler {
+	return &TrashHandler{
+		trashUsecase: trashUsecase,
+	}
+}
+
+func (trashHandler *TrashHandler) GetFarms(c *gin.Context) {
+	//bind query
+	var query domain.TrashQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//get deleted farms
+	farms, pagination, err := trashHandler.trashUsecase.GetFarms(c.Request.Context(), middleware.GetAuthUser(c), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get deleted farms", farms, pagination)
+}
+
+func (trashHandler *TrashHandler) RestoreFarm(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+
+	//restore farm
+	farm, err := trashHandler.trashUsecase.RestoreFarm(c.Request.Context(), middleware.GetAuthUser(c), farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully restore farm", farm)
+}
+
+func (trashHandler *TrashHandler) PurgeFarm(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+
+	//purge farm
+	err := trashHandler.trashUsecase.PurgeFarm(c.Request.Context(), middleware.GetAuthUser(c), farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully purge farm", nil)
+}
+
+func (trashHandler *TrashHandler) GetPonds(c *gin.Context) {
+	//bind query
+	var query domain.TrashQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//get deleted ponds
+	ponds, pagination, err := trashHandler.trashUsecase.GetPonds(c.Request.Context(), middleware.GetAuthUser(c), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get deleted ponds", ponds, pagination)
+}
+
+func (trashHandler *TrashHandler) RestorePond(c *gin.Context) {
+	//bind param
+	pondId := c.Param("pondId")
+
+	//restore pond
+	pond, err := trashHandler.trashUsecase.RestorePond(c.Request.Context(), middleware.GetAuthUser(c), pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully restore pond", pond)
+}
+
+func (trashHandler *TrashHandler) PurgePond(c *gin.Context) {
+	//bind param
+	pondId := c.Param("pondId")
+
+	//purge pond
+	err := trashHandler.trashUsecase.PurgePond(c.Request.Context(), middleware.GetAuthUser(c), pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully purge pond", nil)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	trash_mock "github.com/reyhanmichiels/AquaFarmManagement/app/trash/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var trashUsecaseMock = trash_mock.TrashUsecaseMock{
+	Mock: mock.Mock{},
+}
+
+var trashHandler = NewTrashHandler(&trashUsecaseMock)
+
+var authUser = domain.AuthUser{
+	ID:    "userId",
+	Email: "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
+func TestGetFarms(t *testing.T) {
+	t.Run("should can get deleted farms", func(t *testing.T) {
+		// call mock
+		query := domain.TrashQuery{PageQuery: domain.PageQuery{Page: 1, PageSize: 2, Sort: "-deleted_at"}}
+		mockCallResponse := []domain.Farm{
+			{ID: "testID1", Name: "testName1"},
+			{ID: "testID2", Name: "testName2"},
+		}
+		mockPagination := domain.Pagination{Page: 1, PageSize: 2, Total: 3, TotalPages: 2}
+
+		mockCall := trashUsecaseMock.Mock.On("GetFarms", authUser, query).Return(mockCallResponse, mockPagination, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/trash/farms", trashHandler.GetFarms)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/trash/farms?page=1&page_size=2&sort=-deleted_at", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully get deleted farms", responseBody["message"], "message should be equal")
+
+		farmsData := responseBody["data"].([]interface{})
+		for i, v := range farmsData {
+			v := v.(map[string]any)
+			assert.Equal(t, mockCallResponse[i].ID, v["id"], "id should be equal")
+		}
+
+		meta := responseBody["meta"].(map[string]any)
+		assert.Equal(t, "/api/trash/farms?page=2&page_size=2&sort=-deleted_at", meta["next"], "next link should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestRestoreFarm(t *testing.T) {
+	t.Run("should can restore farm", func(t *testing.T) {
+		// call mock
+		mockCallResponse := domain.Farm{ID: "testID", Name: "testName"}
+		mockCall := trashUsecaseMock.Mock.On("RestoreFarm", authUser, "testID").Return(mockCallResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/trash/farms/:farmId/restore", trashHandler.RestoreFarm)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/trash/farms/testID/restore", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		farmData := responseBody["data"].(map[string]any)
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully restore farm", responseBody["message"], "message should be equal")
+		assert.Equal(t, mockCallResponse.ID, farmData["id"], "id should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when usecase call return error", func(t *testing.T) {
+		// call mock
+		errObject := apperror.Conflict(apperror.CodeFarmNameTaken, "failed to restore farm", errors.New("farm name already taken"))
+		mockCall := trashUsecaseMock.Mock.On("RestoreFarm", authUser, "testID").Return(nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/trash/farms/:farmId/restore", trashHandler.RestoreFarm)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/trash/farms/testID/restore", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusConflict, response.Code, "status code should be equal")
+		assert.Equal(t, "error", responseBody["status"], "status should be equal")
+		assert.Equal(t, errObject.Message, responseBody["message"], "message should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestPurgePond(t *testing.T) {
+	t.Run("should can purge pond", func(t *testing.T) {
+		// call mock
+		mockCall := trashUsecaseMock.Mock.On("PurgePond", authUser, "testID").Return(nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.DELETE("/api/trash/ponds/:pondId", trashHandler.PurgePond)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("DELETE", "/api/trash/ponds/testID", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully purge pond", responseBody["message"], "message should be equal")
+		assert.Nil(t, responseBody["data"], "data should be nil")
+
+		mockCall.Unset()
+	})
+}
@@ -0,0 +1,72 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type TrashUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) GetFarms(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Farm, domain.Pagination, error) {
+	args := trashUsecaseMock.Mock.Called(authUser, query)
+
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
+	}
+
+	return args[0].([]domain.Farm), args[1].(domain.Pagination), nil
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) RestoreFarm(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.Farm, error) {
+	args := trashUsecaseMock.Mock.Called(authUser, farmId)
+
+	if args[1] != nil {
+		return domain.Farm{}, args[1].(error)
+	}
+
+	return args[0].(domain.Farm), nil
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) PurgeFarm(ctx context.Context, authUser domain.AuthUser, farmId string) error {
+	args := trashUsecaseMock.Mock.Called(authUser, farmId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) GetPonds(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Pond, domain.Pagination, error) {
+	args := trashUsecaseMock.Mock.Called(authUser, query)
+
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
+	}
+
+	return args[0].([]domain.Pond), args[1].(domain.Pagination), nil
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) RestorePond(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.Pond, error) {
+	args := trashUsecaseMock.Mock.Called(authUser, pondId)
+
+	if args[1] != nil {
+		return domain.Pond{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pond), nil
+}
+
+func (trashUsecaseMock *TrashUsecaseMock) PurgePond(ctx context.Context, authUser domain.AuthUser, pondId string) error {
+	args := trashUsecaseMock.Mock.Called(authUser, pondId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
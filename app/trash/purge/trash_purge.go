@@ -0,0 +1,165 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+)
+
+type ITrashPurge interface {
+	Run(ctx context.Context, now time.Time) error
+	Health() error
+	Close(ctx context.Context) error
+}
+
+// Defaults for unset or invalid config values
+const (
+	DefaultInterval  = time.Hour
+	DefaultRetention = 30 * 24 * time.Hour
+	DefaultTimeout   = 10 * time.Minute
+)
+
+type Config struct {
+	// time between two runs
+	Interval time.Duration
+	// how long deleted farms and ponds stay restorable
+	Retention time.Duration
+	// longest time a periodic run may take
+	Timeout time.Duration
+}
+
+// TrashPurge periodically deletes for good the farms and ponds deleted longer
+// than the retention ago, with everything recorded on them
+type TrashPurge struct {
+	farmRepository farm_repository.IFarmRepository
+	pondRepository pond_repository.IPondRepository
+	config         Config
+	stop           chan struct{}
+	done           chan struct{}
+	closeOnce      sync.Once
+
+	// error of the latest periodic run, nil once a run succeeds again
+	errMu  sync.Mutex
+	runErr error
+}
+
+func NewTrashPurge(farmRepository farm_repository.IFarmRepository, pondRepository pond_repository.IPondRepository, config Config) ITrashPurge {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	trashPurge := &TrashPurge{
+		farmRepository: farmRepository,
+		pondRepository: pondRepository,
+		config:         config,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+
+	go trashPurge.work()
+
+	return trashPurge
+}
+
+// Run purges everything deleted before now minus the retention
+func (trashPurge *TrashPurge) Run(ctx context.Context, now time.Time) error {
+	cutoff := now.Add(-trashPurge.config.Retention)
+
+	ponds, err := trashPurge.pondRepository.PurgePonds(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	farms, err := trashPurge.farmRepository.PurgeFarms(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	if ponds > 0 || farms > 0 {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"FARMS": farms,
+			"PONDS": ponds,
+		}).Info("Purged trash")
+	}
+
+	return nil
+}
+
+// Health fails once the purge is closed or while its latest periodic run failed
+func (trashPurge *TrashPurge) Health() error {
+	select {
+	case <-trashPurge.done:
+		return errors.New("trash purge is closed")
+	default:
+	}
+
+	trashPurge.errMu.Lock()
+	defer trashPurge.errMu.Unlock()
+	if trashPurge.runErr != nil {
+		return fmt.Errorf("latest run failed: %w", trashPurge.runErr)
+	}
+
+	return nil
+}
+
+// Close stops the periodic runs and waits for a running one or until ctx is done
+func (trashPurge *TrashPurge) Close(ctx context.Context) error {
+	trashPurge.closeOnce.Do(func() {
+		close(trashPurge.stop)
+	})
+
+	select {
+	case <-trashPurge.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run purges once, bounded by the configured timeout, and keeps its error for
+// Health
+func (trashPurge *TrashPurge) run(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), trashPurge.config.Timeout)
+	defer cancel()
+
+	err := trashPurge.Run(ctx, now)
+
+	trashPurge.errMu.Lock()
+	trashPurge.runErr = err
+	trashPurge.errMu.Unlock()
+
+	if err != nil {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"ERROR": err.Error(),
+		}).Error("Failed to purge trash")
+	}
+}
+
+func (trashPurge *TrashPurge) work() {
+	defer close(trashPurge.done)
+
+	ticker := time.NewTicker(trashPurge.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-trashPurge.stop:
+			return
+		case now := <-ticker.C:
+			trashPurge.run(now)
+		}
+	}
+}
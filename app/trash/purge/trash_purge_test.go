@@ -0,0 +1,81 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2024, 3, 10, 15, 42, 0, 0, time.UTC)
+
+func TestRun(t *testing.T) {
+	infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+
+	t.Run("should purge ponds then farms deleted before the retention", func(t *testing.T) {
+		// prepare purge
+		farmRepositoryMock := farm_mock.FarmRepositoryMock{}
+		pondRepositoryMock := pond_mock.PondRepositoryMock{}
+		cutoff := time.Date(2024, 3, 3, 15, 42, 0, 0, time.UTC)
+		pondRepositoryMock.Mock.On("PurgePonds", cutoff).Return(int64(2), nil)
+		farmRepositoryMock.Mock.On("PurgeFarms", cutoff).Return(int64(1), nil)
+		trashPurge := NewTrashPurge(&farmRepositoryMock, &pondRepositoryMock, Config{Retention: 7 * 24 * time.Hour})
+		defer trashPurge.Close(context.Background())
+
+		// run purge
+		err := trashPurge.Run(context.Background(), now)
+
+		//test run
+		assert.Nil(t, err, "error should be nil")
+		pondRepositoryMock.Mock.AssertExpectations(t)
+		farmRepositoryMock.Mock.AssertExpectations(t)
+	})
+
+	t.Run("should stop when failed to purge ponds", func(t *testing.T) {
+		// prepare purge
+		farmRepositoryMock := farm_mock.FarmRepositoryMock{}
+		pondRepositoryMock := pond_mock.PondRepositoryMock{}
+		pondRepositoryMock.Mock.On("PurgePonds", mock.Anything).Return(nil, errors.New("connection refused"))
+		trashPurge := NewTrashPurge(&farmRepositoryMock, &pondRepositoryMock, Config{})
+		defer trashPurge.Close(context.Background())
+
+		// run purge
+		err := trashPurge.Run(context.Background(), now)
+
+		//test run
+		assert.Equal(t, errors.New("connection refused"), err, "error should be equal")
+		farmRepositoryMock.Mock.AssertNotCalled(t, "PurgeFarms", mock.Anything)
+	})
+}
+
+func TestWork(t *testing.T) {
+	t.Run("should run on interval until closed", func(t *testing.T) {
+		// prepare purge
+		farmRepositoryMock := farm_mock.FarmRepositoryMock{}
+		pondRepositoryMock := pond_mock.PondRepositoryMock{}
+		runs := make(chan struct{}, 10)
+		pondRepositoryMock.Mock.On("PurgePonds", mock.Anything).Return(int64(0), nil)
+		farmRepositoryMock.Mock.On("PurgeFarms", mock.Anything).Return(int64(0), nil).Run(func(args mock.Arguments) {
+			runs <- struct{}{}
+		})
+		trashPurge := NewTrashPurge(&farmRepositoryMock, &pondRepositoryMock, Config{Interval: 10 * time.Millisecond})
+
+		//test run
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("purge did not run")
+		}
+
+		assert.Nil(t, trashPurge.Health(), "health should be nil")
+		assert.Nil(t, trashPurge.Close(context.Background()), "close should succeed")
+		assert.Nil(t, trashPurge.Close(context.Background()), "second close should succeed")
+		assert.EqualError(t, trashPurge.Health(), "trash purge is closed", "health should fail once closed")
+	})
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	farm_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ITrashUsecase interface {
+	GetFarms(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Farm, domain.Pagination, error)
+	RestoreFarm(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.Farm, error)
+	PurgeFarm(ctx context.Context, authUser domain.AuthUser, farmId string) error
+	GetPonds(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Pond, domain.Pagination, error)
+	RestorePond(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.Pond, error)
+	PurgePond(ctx context.Context, authUser domain.AuthUser, pondId string) error
+}
+
+type TrashUsecase struct {
+	farmRepository farm_repository.IFarmRepository
+	pondRepository pond_repository.IPondRepository
+}
+
+func NewTrashUsecase(farmRepository farm_repository.IFarmRepository, pondRepository pond_repository.IPondRepository) ITrashUsecase {
+	return &TrashUsecase{
+		farmRepository: farmRepository,
+		pondRepository: pondRepository,
+	}
+}
+
+func (trashUsecase *TrashUsecase) GetFarms(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Farm, domain.Pagination, error) {
+	// validate sort and cursor
+	query.PageQuery = latestDeletedFirst(query.PageQuery)
+	err := util.ValidatePageQuery(query.PageQuery, domain.TrashSortFields)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get deleted farms", err)
+	}
+
+	// get deleted farms
+	var farms []domain.Farm
+	pagination, err := trashUsecase.farmRepository.FindDeletedFarms(ctx, &farms, authUser.OrganizationID, authUser.ID, query)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Internal("failed to get deleted farms", err)
+	}
+
+	// check if farm exist
+	if len(farms) == 0 {
+		return nil, domain.Pagination{}, apperror.NotFound(apperror.CodeFarmNotFound, "failed to get deleted farms", errors.New("farm not found"))
+	}
+
+	return farms, pagination, nil
+}
+
+func (trashUsecase *TrashUsecase) RestoreFarm(ctx context.Context, authUser domain.AuthUser, farmId string) (domain.Farm, error) {
+	// check if user can manage farm, memberships outlive the deletion
+	err := farm_usecase.Authorize(ctx, trashUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to restore farm")
+	if err != nil {
+		return domain.Farm{}, err
+	}
+
+	// check if farm is deleted
+	var farm domain.Farm
+	isFarmDeleted := trashUsecase.farmRepository.FindDeletedFarm(ctx, &farm, authUser.OrganizationID, farmId)
+	if errors.Is(isFarmDeleted, gorm.ErrRecordNotFound) {
+		return domain.Farm{}, apperror.NotFound(apperror.CodeFarmNotFound, "failed to restore farm", errors.New("farm not found"))
+	}
+	if isFarmDeleted != nil {
+		return domain.Farm{}, apperror.Internal("failed to restore farm", isFarmDeleted)
+	}
+
+	// check if name was taken since the deletion
+	isFarmExist := trashUsecase.farmRepository.FindFarmByCondition(ctx, &domain.Farm{}, authUser.OrganizationID, "name = ?", farm.Name)
+	if isFarmExist == nil {
+		return domain.Farm{}, apperror.Conflict(apperror.CodeFarmNameTaken, "failed to restore farm", errors.New("farm name is already used"))
+	}
+	if !errors.Is(isFarmExist, gorm.ErrRecordNotFound) {
+		return domain.Farm{}, apperror.Internal("failed to restore farm", isFarmExist)
+	}
+
+	// restore farm with its ponds
+	err = trashUsecase.farmRepository.RestoreFarm(ctx, &farm)
+	if err != nil {
+		return domain.Farm{}, apperror.Internal("failed to restore farm", err)
+	}
+	farm.DeletedAt.Valid = false
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"FARM_ID": farm.ID,
+	}).Info("Farm restored")
+
+	return farm, nil
+}
+
+func (trashUsecase *TrashUsecase) PurgeFarm(ctx context.Context, authUser domain.AuthUser, farmId string) error {
+	// check if user can manage farm
+	err := farm_usecase.Authorize(ctx, trashUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to purge farm")
+	if err != nil {
+		return err
+	}
+
+	// check if farm is deleted
+	var farm domain.Farm
+	isFarmDeleted := trashUsecase.farmRepository.FindDeletedFarm(ctx, &farm, authUser.OrganizationID, farmId)
+	if errors.Is(isFarmDeleted, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeFarmNotFound, "failed to purge farm", errors.New("farm not found"))
+	}
+	if isFarmDeleted != nil {
+		return apperror.Internal("failed to purge farm", isFarmDeleted)
+	}
+
+	// purge farm
+	err = trashUsecase.farmRepository.PurgeFarm(ctx, &farm)
+	if err != nil {
+		return apperror.Internal("failed to purge farm", err)
+	}
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"FARM_ID": farm.ID,
+	}).Info("Farm purged")
+
+	return nil
+}
+
+func (trashUsecase *TrashUsecase) GetPonds(ctx context.Context, authUser domain.AuthUser, query domain.TrashQuery) ([]domain.Pond, domain.Pagination, error) {
+	// validate sort and cursor
+	query.PageQuery = latestDeletedFirst(query.PageQuery)
+	err := util.ValidatePageQuery(query.PageQuery, domain.TrashSortFields)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get deleted ponds", err)
+	}
+
+	// get deleted ponds
+	var ponds []domain.Pond
+	pagination, err := trashUsecase.pondRepository.FindDeletedPonds(ctx, &ponds, authUser.OrganizationID, authUser.ID, query)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Internal("failed to get deleted ponds", err)
+	}
+
+	// check if pond exist
+	if len(ponds) == 0 {
+		return nil, domain.Pagination{}, apperror.NotFound(apperror.CodePondNotFound, "failed to get deleted ponds", errors.New("pond not found"))
+	}
+
+	return ponds, pagination, nil
+}
+
+func (trashUsecase *TrashUsecase) RestorePond(ctx context.Context, authUser domain.AuthUser, pondId string) (domain.Pond, error) {
+	// check if pond is deleted
+	pond, err := trashUsecase.authorizeDeletedPond(ctx, authUser, pondId, "failed to restore pond")
+	if err != nil {
+		return domain.Pond{}, err
+	}
+
+	// check if name was taken since the deletion
+	isPondExist := trashUsecase.pondRepository.FindPondByCondition(ctx, &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", pond.FarmID, pond.Name)
+	if isPondExist == nil {
+		return domain.Pond{}, apperror.Conflict(apperror.CodePondNameTaken, "failed to restore pond", errors.New("pond name is already used"))
+	}
+	if !errors.Is(isPondExist, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, apperror.Internal("failed to restore pond", isPondExist)
+	}
+
+	// restore pond
+	err = trashUsecase.pondRepository.RestorePond(ctx, &pond)
+	if err != nil {
+		return domain.Pond{}, apperror.Internal("failed to restore pond", err)
+	}
+	pond.DeletedAt.Valid = false
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"POND_ID": pond.ID,
+	}).Info("Pond restored")
+
+	return pond, nil
+}
+
+func (trashUsecase *TrashUsecase) PurgePond(ctx context.Context, authUser domain.AuthUser, pondId string) error {
+	// check if pond is deleted
+	pond, err := trashUsecase.authorizeDeletedPond(ctx, authUser, pondId, "failed to purge pond")
+	if err != nil {
+		return err
+	}
+
+	// purge pond
+	err = trashUsecase.pondRepository.PurgePond(ctx, &pond)
+	if err != nil {
+		return apperror.Internal("failed to purge pond", err)
+	}
+
+	infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+		"USER_ID": authUser.ID,
+		"POND_ID": pond.ID,
+	}).Info("Pond purged")
+
+	return nil
+}
+
+// authorizeDeletedPond loads a deleted pond of the user organization and
+// checks the user can manage its farm
+func (trashUsecase *TrashUsecase) authorizeDeletedPond(ctx context.Context, authUser domain.AuthUser, pondId string, message string) (domain.Pond, error) {
+	var pond domain.Pond
+	isPondDeleted := trashUsecase.pondRepository.FindDeletedPond(ctx, &pond, authUser.OrganizationID, pondId)
+	if errors.Is(isPondDeleted, gorm.ErrRecordNotFound) {
+		return domain.Pond{}, apperror.NotFound(apperror.CodePondNotFound, message, errors.New("pond not found"))
+	}
+	if isPondDeleted != nil {
+		return domain.Pond{}, apperror.Internal(message, isPondDeleted)
+	}
+
+	err := farm_usecase.Authorize(ctx, trashUsecase.farmRepository, authUser.ID, pond.FarmID, domain.PermissionManage, message)
+	if err != nil {
+		return domain.Pond{}, err
+	}
+
+	return pond, nil
+}
+
+func latestDeletedFirst(query domain.PageQuery) domain.PageQuery {
+	if query.Sort == "" {
+		query.Sort = "-deleted_at"
+	}
+
+	return query
+}
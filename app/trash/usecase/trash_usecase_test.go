@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// usecase over fresh repository mocks, authUser has role on farmId
+func newTrashUsecase(farmId string, role domain.Role) (ITrashUsecase, *farm_mock.FarmRepositoryMock, *pond_mock.PondRepositoryMock) {
+	farmRepositoryMock := &farm_mock.FarmRepositoryMock{}
+	pondRepositoryMock := &pond_mock.PondRepositoryMock{}
+	farmRepositoryMock.Mock.On("FindMember", &domain.FarmMember{}, farmId, authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.FarmID = farmId
+		arg.UserID = authUser.ID
+		arg.Role = role
+	})
+
+	return NewTrashUsecase(farmRepositoryMock, pondRepositoryMock), farmRepositoryMock, pondRepositoryMock
+}
+
+func TestGetFarms(t *testing.T) {
+	t.Run("should list latest deleted farms first by default", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+		query := domain.TrashQuery{PageQuery: domain.PageQuery{Sort: "-deleted_at"}}
+		var farms []domain.Farm
+		farmRepositoryMock.Mock.On("FindDeletedFarms", &farms, authUser.OrganizationID, authUser.ID, query).Return(domain.Pagination{Total: 1}, nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.Farm)
+			*arg = []domain.Farm{{ID: "farmId", Name: "test_farm"}}
+		})
+
+		// call usecase
+		result, pagination, err := trashUsecase.GetFarms(context.Background(), authUser, domain.TrashQuery{})
+
+		//test result
+		assert.Nil(t, err, "error should be nil")
+		assert.Len(t, result, 1, "farms should be returned")
+		assert.Equal(t, int64(1), pagination.Total, "total should be equal")
+	})
+
+	t.Run("should return error when sort is invalid", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+
+		// call usecase
+		_, _, err := trashUsecase.GetFarms(context.Background(), authUser, domain.TrashQuery{PageQuery: domain.PageQuery{Sort: "area"}})
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrValidation), "error should be validation")
+		farmRepositoryMock.Mock.AssertNotCalled(t, "FindDeletedFarms", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRestoreFarm(t *testing.T) {
+	t.Run("should restore farm", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+		farmRepositoryMock.Mock.On("FindDeletedFarm", &domain.Farm{}, authUser.OrganizationID, "farmId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Farm)
+			arg.ID = "farmId"
+			arg.Name = "test_farm"
+			arg.DeletedAt = gorm.DeletedAt{Valid: true}
+		})
+		farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", "test_farm").Return(gorm.ErrRecordNotFound)
+		farmRepositoryMock.Mock.On("RestoreFarm", mock.Anything).Return(nil)
+
+		// call usecase
+		farm, err := trashUsecase.RestoreFarm(context.Background(), authUser, "farmId")
+
+		//test result
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, "farmId", farm.ID, "id should be equal")
+		assert.False(t, farm.DeletedAt.Valid, "farm should not be deleted")
+	})
+
+	t.Run("should return error when name is taken", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+		farmRepositoryMock.Mock.On("FindDeletedFarm", &domain.Farm{}, authUser.OrganizationID, "farmId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Farm)
+			arg.ID = "farmId"
+			arg.Name = "test_farm"
+		})
+		farmRepositoryMock.Mock.On("FindFarmByCondition", &domain.Farm{}, authUser.OrganizationID, "name = ?", "test_farm").Return(nil)
+
+		// call usecase
+		_, err := trashUsecase.RestoreFarm(context.Background(), authUser, "farmId")
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrConflict), "error should be conflict")
+		farmRepositoryMock.Mock.AssertNotCalled(t, "RestoreFarm", mock.Anything)
+	})
+
+	t.Run("should return error when farm is not deleted", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+		farmRepositoryMock.Mock.On("FindDeletedFarm", &domain.Farm{}, authUser.OrganizationID, "farmId").Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		_, err := trashUsecase.RestoreFarm(context.Background(), authUser, "farmId")
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrNotFound), "error should be not found")
+	})
+
+	t.Run("should return error when user can't manage farm", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleTechnician)
+
+		// call usecase
+		_, err := trashUsecase.RestoreFarm(context.Background(), authUser, "farmId")
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrForbidden), "error should be forbidden")
+		farmRepositoryMock.Mock.AssertNotCalled(t, "FindDeletedFarm", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPurgeFarm(t *testing.T) {
+	t.Run("should purge farm", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, farmRepositoryMock, _ := newTrashUsecase("farmId", domain.RoleManager)
+		farmRepositoryMock.Mock.On("FindDeletedFarm", &domain.Farm{}, authUser.OrganizationID, "farmId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Farm)
+			arg.ID = "farmId"
+		})
+		farmRepositoryMock.Mock.On("PurgeFarm", &domain.Farm{ID: "farmId"}).Return(nil)
+
+		// call usecase
+		err := trashUsecase.PurgeFarm(context.Background(), authUser, "farmId")
+
+		//test result
+		assert.Nil(t, err, "error should be nil")
+		farmRepositoryMock.Mock.AssertExpectations(t)
+	})
+}
+
+func TestRestorePond(t *testing.T) {
+	t.Run("should restore pond", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, _, pondRepositoryMock := newTrashUsecase("farmId", domain.RoleManager)
+		pondRepositoryMock.Mock.On("FindDeletedPond", &domain.Pond{}, authUser.OrganizationID, "pondId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Pond)
+			arg.ID = "pondId"
+			arg.FarmID = "farmId"
+			arg.Name = "test_pond"
+		})
+		pondRepositoryMock.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", "farmId", "test_pond").Return(gorm.ErrRecordNotFound)
+		pondRepositoryMock.Mock.On("RestorePond", mock.Anything).Return(nil)
+
+		// call usecase
+		pond, err := trashUsecase.RestorePond(context.Background(), authUser, "pondId")
+
+		//test result
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, "pondId", pond.ID, "id should be equal")
+	})
+
+	t.Run("should return error when name is taken", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, _, pondRepositoryMock := newTrashUsecase("farmId", domain.RoleManager)
+		pondRepositoryMock.Mock.On("FindDeletedPond", &domain.Pond{}, authUser.OrganizationID, "pondId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Pond)
+			arg.ID = "pondId"
+			arg.FarmID = "farmId"
+			arg.Name = "test_pond"
+		})
+		pondRepositoryMock.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "farm_id = ? AND name = ?", "farmId", "test_pond").Return(nil)
+
+		// call usecase
+		_, err := trashUsecase.RestorePond(context.Background(), authUser, "pondId")
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrConflict), "error should be conflict")
+		pondRepositoryMock.Mock.AssertNotCalled(t, "RestorePond", mock.Anything)
+	})
+}
+
+func TestPurgePond(t *testing.T) {
+	t.Run("should return error when user can't manage farm", func(t *testing.T) {
+		// prepare usecase
+		trashUsecase, _, pondRepositoryMock := newTrashUsecase("farmId", domain.RoleAuditor)
+		pondRepositoryMock.Mock.On("FindDeletedPond", &domain.Pond{}, authUser.OrganizationID, "pondId").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.Pond)
+			arg.ID = "pondId"
+			arg.FarmID = "farmId"
+		})
+
+		// call usecase
+		err := trashUsecase.PurgePond(context.Background(), authUser, "pondId")
+
+		//test result
+		assert.True(t, errors.Is(err, apperror.ErrForbidden), "error should be forbidden")
+		pondRepositoryMock.Mock.AssertNotCalled(t, "PurgePond", mock.Anything)
+	})
+}
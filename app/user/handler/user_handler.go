@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/user/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+type UserHandler struct {
+	userUsecase usecase.IUserUsecase
+}
+
+func NewUserHandler(userUsecase usecase.IUserUsecase) *UserHandler {
+	return &UserHandler{
+		userUsecase: userUsecase,
+	}
+}
+
+func (userHandler *UserHandler) Register(c *gin.Context) {
+	//bind and validate data
+	var request domain.RegisterBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//register user
+	user, err := userHandler.userUsecase.Register(c.Request.Context(), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully register user", user)
+}
+
+func (userHandler *UserHandler) CreateOrganizationUser(c *gin.Context) {
+	//bind and validate data
+	var request domain.OrganizationUserBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//create user in organization
+	user, err := userHandler.userUsecase.CreateOrganizationUser(c.Request.Context(), middleware.GetAuthUser(c), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully create organization user", user)
+}
+
+func (userHandler *UserHandler) Login(c *gin.Context) {
+	//bind and validate data
+	var request domain.LoginBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//login user
+	token, err := userHandler.userUsecase.Login(c.Request.Context(), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully login", token)
+}
+
+func (userHandler *UserHandler) Refresh(c *gin.Context) {
+	//bind and validate data
+	var request domain.RefreshTokenBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//refresh token
+	token, err := userHandler.userUsecase.Refresh(c.Request.Context(), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully refresh token", token)
+}
+
+func (userHandler *UserHandler) Logout(c *gin.Context) {
+	//bind and validate data
+	var request domain.RefreshTokenBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind input", err))
+		return
+	}
+
+	//logout user
+	err = userHandler.userUsecase.Logout(c.Request.Context(), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully logout", nil)
+}
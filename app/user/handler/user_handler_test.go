@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	user_mock "github.com/reyhanmichiels/AquaFarmManagement/app/user/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var userUsecaseMock = user_mock.UserUsecaseMock{
+	Mock: mock.Mock{},
+}
+
+var userHandler = NewUserHandler(&userUsecaseMock)
+
+func TestRegister(t *testing.T) {
+	t.Run("should register user", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.RegisterBind{
+			Name:             "testName",
+			Email:            "test@mail.com",
+			Password:         "testPassword",
+			OrganizationName: "testOrganization",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockCallResponse := domain.User{
+			ID:       "userID",
+			Name:     requestBody.Name,
+			Email:    requestBody.Email,
+			Password: "hashedPassword",
+		}
+		mockCall := userUsecaseMock.Mock.On("Register", requestBody).Return(mockCallResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.POST("/api/auth/register", userHandler.Register)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		userData := responseBody["data"].(map[string]any)
+		assert.Equal(t, http.StatusCreated, response.Code, "status code should be equal")
+		assert.Equal(t, "success", responseBody["status"], "status should be equal")
+		assert.Equal(t, "successfully register user", responseBody["message"], "message should be equal")
+		assert.Equal(t, mockCallResponse.ID, userData["id"], "user id should be equal")
+		assert.Equal(t, mockCallResponse.Email, userData["email"], "email should be equal")
+		assert.NotContains(t, userData, "password", "password should never be returned")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when request invalid", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "not an email",
+			Password: "short",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.POST("/api/auth/register", userHandler.Register)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, "error", responseBody["status"], "status should be equal")
+		assert.Equal(t, "failed to bind input", responseBody["message"], "message should be equal")
+	})
+}
+
+func TestLogin(t *testing.T) {
+	t.Run("should login user", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.LoginBind{
+			Email:    "test@mail.com",
+			Password: "testPassword",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockCallResponse := domain.TokenApi{
+			AccessToken:  "accessToken",
+			RefreshToken: "refreshToken",
+			TokenType:    "Bearer",
+			ExpiresAt:    time.Now(),
+		}
+		mockCall := userUsecaseMock.Mock.On("Login", requestBody).Return(mockCallResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.POST("/api/auth/login", userHandler.Login)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		tokenData := responseBody["data"].(map[string]any)
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully login", responseBody["message"], "message should be equal")
+		assert.Equal(t, mockCallResponse.AccessToken, tokenData["access_token"], "access token should be equal")
+		assert.Equal(t, mockCallResponse.RefreshToken, tokenData["refresh_token"], "refresh token should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when credential is wrong", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.LoginBind{
+			Email:    "test@mail.com",
+			Password: "wrongPassword",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		errObject := apperror.Unauthorized(apperror.CodeInvalidCredentials, "failed to login", errors.New("email or password is wrong"))
+		mockCall := userUsecaseMock.Mock.On("Login", requestBody).Return(nil, errObject)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.POST("/api/auth/login", userHandler.Login)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code, "status code should be equal")
+		assert.Equal(t, apperror.CodeInvalidCredentials, responseBody["code"], "code should be equal")
+		assert.Equal(t, errObject.Err.Error(), responseBody["error"], "error should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("should logout user", func(t *testing.T) {
+		//prepare request body
+		requestBody := domain.RefreshTokenBind{
+			RefreshToken: "refreshToken",
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockCall := userUsecaseMock.Mock.On("Logout", requestBody).Return(nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.POST("/api/auth/logout", userHandler.Logout)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/auth/logout", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		engine.ServeHTTP(response, request)
+
+		//test response
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully logout", responseBody["message"], "message should be equal")
+		assert.Nil(t, responseBody["data"], "data should be nil")
+
+		mockCall.Unset()
+	})
+}
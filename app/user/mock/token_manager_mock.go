@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenManagerMock struct {
+	Mock mock.Mock
+}
+
+func (tokenManagerMock *TokenManagerMock) GenerateAccessToken(user domain.User) (string, time.Time, error) {
+	args := tokenManagerMock.Mock.Called(user)
+
+	if args[2] != nil {
+		return "", time.Time{}, args[2].(error)
+	}
+
+	return args[0].(string), args[1].(time.Time), nil
+}
+
+func (tokenManagerMock *TokenManagerMock) ParseAccessToken(token string) (domain.AuthUser, error) {
+	args := tokenManagerMock.Mock.Called(token)
+
+	if args[1] != nil {
+		return domain.AuthUser{}, args[1].(error)
+	}
+
+	return args[0].(domain.AuthUser), nil
+}
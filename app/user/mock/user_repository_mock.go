@@ -0,0 +1,82 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (userRepositoryMock *UserRepositoryMock) FindUserByCondition(ctx context.Context, user any, condition string, value any) error {
+	args := userRepositoryMock.Mock.Called(user, condition, value)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) CreateUser(ctx context.Context, user *domain.User) error {
+	args := userRepositoryMock.Mock.Called(user)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) CreateRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	args := userRepositoryMock.Mock.Called(refreshToken)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) FindRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken, tokenHash string) error {
+	args := userRepositoryMock.Mock.Called(refreshToken, tokenHash)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) RotateRefreshToken(ctx context.Context, oldToken *domain.RefreshToken, newToken *domain.RefreshToken) error {
+	args := userRepositoryMock.Mock.Called(oldToken, newToken)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) RevokeRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	args := userRepositoryMock.Mock.Called(refreshToken)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (userRepositoryMock *UserRepositoryMock) RevokeUserRefreshTokens(ctx context.Context, userId string) error {
+	args := userRepositoryMock.Mock.Called(userId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (userUsecaseMock *UserUsecaseMock) Register(ctx context.Context, request domain.RegisterBind) (domain.User, error) {
+	args := userUsecaseMock.Mock.Called(request)
+
+	if args[1] != nil {
+		return domain.User{}, args[1].(error)
+	}
+
+	return args[0].(domain.User), nil
+}
+
+func (userUsecaseMock *UserUsecaseMock) CreateOrganizationUser(ctx context.Context, authUser domain.AuthUser, request domain.OrganizationUserBind) (domain.User, error) {
+	args := userUsecaseMock.Mock.Called(authUser, request)
+
+	if args[1] != nil {
+		return domain.User{}, args[1].(error)
+	}
+
+	return args[0].(domain.User), nil
+}
+
+func (userUsecaseMock *UserUsecaseMock) Login(ctx context.Context, request domain.LoginBind) (domain.TokenApi, error) {
+	args := userUsecaseMock.Mock.Called(request)
+
+	if args[1] != nil {
+		return domain.TokenApi{}, args[1].(error)
+	}
+
+	return args[0].(domain.TokenApi), nil
+}
+
+func (userUsecaseMock *UserUsecaseMock) Refresh(ctx context.Context, request domain.RefreshTokenBind) (domain.TokenApi, error) {
+	args := userUsecaseMock.Mock.Called(request)
+
+	if args[1] != nil {
+		return domain.TokenApi{}, args[1].(error)
+	}
+
+	return args[0].(domain.TokenApi), nil
+}
+
+func (userUsecaseMock *UserUsecaseMock) Logout(ctx context.Context, request domain.RefreshTokenBind) error {
+	args := userUsecaseMock.Mock.Called(request)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
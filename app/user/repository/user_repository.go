@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"gorm.io/gorm"
+)
+
+type IUserRepository interface {
+	FindUserByCondition(ctx context.Context, user any, condition string, value any) error
+	CreateUser(ctx context.Context, user *domain.User) error
+	CreateRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error
+	FindRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken, tokenHash string) error
+	RotateRefreshToken(ctx context.Context, oldToken *domain.RefreshToken, newToken *domain.RefreshToken) error
+	RevokeRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error
+	RevokeUserRefreshTokens(ctx context.Context, userId string) error
+}
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) IUserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (userRepository *UserRepository) FindUserByCondition(ctx context.Context, user any, condition string, value any) error {
+	err := userRepository.db.WithContext(ctx).Model(&domain.User{}).First(user, condition, value).Error
+	return err
+}
+
+func (userRepository *UserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	tx := userRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Create(user).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (userRepository *UserRepository) CreateRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	err := userRepository.db.WithContext(ctx).Create(refreshToken).Error
+	return err
+}
+
+func (userRepository *UserRepository) FindRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken, tokenHash string) error {
+	err := userRepository.db.WithContext(ctx).First(refreshToken, "token_hash = ?", tokenHash).Error
+	return err
+}
+
+// RotateRefreshToken revokes oldToken and stores newToken atomically. It fails
+// with gorm.ErrRecordNotFound when oldToken was revoked concurrently.
+func (userRepository *UserRepository) RotateRefreshToken(ctx context.Context, oldToken *domain.RefreshToken, newToken *domain.RefreshToken) error {
+	tx := userRepository.db.WithContext(ctx).Begin()
+
+	result := tx.Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", oldToken.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	err := tx.Create(newToken).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (userRepository *UserRepository) RevokeRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	err := userRepository.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshToken.ID).
+		Update("revoked_at", time.Now()).Error
+	return err
+}
+
+func (userRepository *UserRepository) RevokeUserRefreshTokens(ctx context.Context, userId string) error {
+	err := userRepository.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+	return err
+}
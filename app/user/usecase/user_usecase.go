@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/app/user/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/auth"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"gorm.io/gorm"
+)
+
+type IUserUsecase interface {
+	Register(ctx context.Context, request domain.RegisterBind) (domain.User, error)
+	CreateOrganizationUser(ctx context.Context, authUser domain.AuthUser, request domain.OrganizationUserBind) (domain.User, error)
+	Login(ctx context.Context, request domain.LoginBind) (domain.TokenApi, error)
+	Refresh(ctx context.Context, request domain.RefreshTokenBind) (domain.TokenApi, error)
+	Logout(ctx context.Context, request domain.RefreshTokenBind) error
+}
+
+type UserUsecase struct {
+	userRepository repository.IUserRepository
+	tokenManager   auth.ITokenManager
+	refreshTTL     time.Duration
+}
+
+func NewUserUsecase(userRepository repository.IUserRepository, tokenManager auth.ITokenManager, refreshTTL time.Duration) IUserUsecase {
+	return &UserUsecase{
+		userRepository: userRepository,
+		tokenManager:   tokenManager,
+		refreshTTL:     refreshTTL,
+	}
+}
+
+func (userUsecase *UserUsecase) Register(ctx context.Context, request domain.RegisterBind) (domain.User, error) {
+	// register user together with its new organization, which it administers
+	user := domain.User{
+		Role:  domain.OrganizationRoleAdmin,
+		Name:  request.Name,
+		Email: strings.ToLower(request.Email),
+		Organization: domain.Organization{
+			Name: request.OrganizationName,
+		},
+	}
+
+	return userUsecase.createUser(ctx, user, request.Password, "failed to register user")
+}
+
+func (userUsecase *UserUsecase) CreateOrganizationUser(ctx context.Context, authUser domain.AuthUser, request domain.OrganizationUserBind) (domain.User, error) {
+	// check if caller administers its organization
+	var caller domain.User
+	isCallerExist := userUsecase.userRepository.FindUserByCondition(ctx, &caller, "id = ?", authUser.ID)
+	if errors.Is(isCallerExist, gorm.ErrRecordNotFound) {
+		return domain.User{}, apperror.Unauthorized(apperror.CodeUnauthorized, "failed to create organization user", errors.New("user is not found"))
+	}
+	if isCallerExist != nil {
+		return domain.User{}, apperror.Internal("failed to create organization user", isCallerExist)
+	}
+
+	if caller.Role != domain.OrganizationRoleAdmin {
+		infrastructure.LoggerFrom(ctx).WithField("USER_ID", authUser.ID).Warn("Permission denied")
+		return domain.User{}, apperror.Forbidden(apperror.CodeForbidden, "failed to create organization user", errors.New("only organization admins can create users"))
+	}
+
+	// create user in the organization of the caller
+	user := domain.User{
+		OrganizationID: authUser.OrganizationID,
+		Role:           domain.OrganizationRoleMember,
+		Name:           request.Name,
+		Email:          strings.ToLower(request.Email),
+	}
+
+	return userUsecase.createUser(ctx, user, request.Password, "failed to create organization user")
+}
+
+func (userUsecase *UserUsecase) createUser(ctx context.Context, user domain.User, password string, message string) (domain.User, error) {
+	// bcrypt limit is in bytes, binding max counts characters
+	if len(password) > auth.MaxPasswordBytes {
+		return domain.User{}, apperror.Validation(apperror.CodeInvalidRequest, message, errors.New("password must be at most 72 bytes"))
+	}
+
+	// check for duplicate entry
+	isUserExist := userUsecase.userRepository.FindUserByCondition(ctx, &domain.User{}, "email = ?", user.Email)
+	if isUserExist == nil {
+		return domain.User{}, apperror.Conflict(apperror.CodeEmailTaken, message, errors.New("email is already used"))
+	}
+	if !errors.Is(isUserExist, gorm.ErrRecordNotFound) {
+		return domain.User{}, apperror.Internal(message, isUserExist)
+	}
+
+	// hash password
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return domain.User{}, apperror.Internal(message, err)
+	}
+
+	// create user
+	user.Password = hashedPassword
+	err = userUsecase.userRepository.CreateUser(ctx, &user)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// the same email was registered since the check
+		return domain.User{}, apperror.Conflict(apperror.CodeEmailTaken, message, errors.New("email is already used"))
+	}
+	if err != nil {
+		return domain.User{}, apperror.Internal(message, err)
+	}
+
+	return user, nil
+}
+
+func (userUsecase *UserUsecase) Login(ctx context.Context, request domain.LoginBind) (domain.TokenApi, error) {
+	// check credential
+	var user domain.User
+	isUserExist := userUsecase.userRepository.FindUserByCondition(ctx, &user, "email = ?", strings.ToLower(request.Email))
+
+	// compare even for unknown email so timing doesn't reveal registered ones
+	err := auth.ComparePassword(user.Password, request.Password)
+	if errors.Is(isUserExist, gorm.ErrRecordNotFound) {
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidCredentials, "failed to login", errors.New("email or password is wrong"))
+	}
+	if isUserExist != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to login", isUserExist)
+	}
+
+	if err != nil {
+		infrastructure.LoggerFrom(ctx).WithField("USER_ID", user.ID).Warn("Login failed")
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidCredentials, "failed to login", errors.New("email or password is wrong"))
+	}
+
+	// issue token pair
+	accessToken, expiresAt, err := userUsecase.tokenManager.GenerateAccessToken(user)
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to login", err)
+	}
+
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to login", err)
+	}
+
+	err = userUsecase.userRepository.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: time.Now().Add(userUsecase.refreshTTL),
+	})
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to login", err)
+	}
+
+	return newTokenApi(accessToken, refreshToken, expiresAt), nil
+}
+
+func (userUsecase *UserUsecase) Refresh(ctx context.Context, request domain.RefreshTokenBind) (domain.TokenApi, error) {
+	// check if refresh token exist
+	var oldToken domain.RefreshToken
+	isTokenExist := userUsecase.userRepository.FindRefreshToken(ctx, &oldToken, auth.HashRefreshToken(request.RefreshToken))
+	if errors.Is(isTokenExist, gorm.ErrRecordNotFound) {
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to refresh token", errors.New("refresh token is invalid"))
+	}
+	if isTokenExist != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to refresh token", isTokenExist)
+	}
+
+	// a revoked token being replayed means it leaked, so end every session of the user
+	if oldToken.RevokedAt != nil {
+		infrastructure.LoggerFrom(ctx).WithField("USER_ID", oldToken.UserID).Warn("Revoked refresh token replayed")
+
+		err := userUsecase.userRepository.RevokeUserRefreshTokens(ctx, oldToken.UserID)
+		if err != nil {
+			return domain.TokenApi{}, apperror.Internal("failed to refresh token", err)
+		}
+
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to refresh token", errors.New("refresh token is revoked"))
+	}
+
+	if time.Now().After(oldToken.ExpiresAt) {
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to refresh token", errors.New("refresh token is expired"))
+	}
+
+	// check if user still exist
+	var user domain.User
+	isUserExist := userUsecase.userRepository.FindUserByCondition(ctx, &user, "id = ?", oldToken.UserID)
+	if errors.Is(isUserExist, gorm.ErrRecordNotFound) {
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to refresh token", errors.New("user is not found"))
+	}
+	if isUserExist != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to refresh token", isUserExist)
+	}
+
+	// rotate token pair
+	accessToken, expiresAt, err := userUsecase.tokenManager.GenerateAccessToken(user)
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to refresh token", err)
+	}
+
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to refresh token", err)
+	}
+
+	newToken := domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: time.Now().Add(userUsecase.refreshTTL),
+	}
+	err = userUsecase.userRepository.RotateRefreshToken(ctx, &oldToken, &newToken)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.TokenApi{}, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to refresh token", errors.New("refresh token is revoked"))
+	}
+	if err != nil {
+		return domain.TokenApi{}, apperror.Internal("failed to refresh token", err)
+	}
+
+	return newTokenApi(accessToken, refreshToken, expiresAt), nil
+}
+
+func (userUsecase *UserUsecase) Logout(ctx context.Context, request domain.RefreshTokenBind) error {
+	// check if refresh token exist
+	var refreshToken domain.RefreshToken
+	isTokenExist := userUsecase.userRepository.FindRefreshToken(ctx, &refreshToken, auth.HashRefreshToken(request.RefreshToken))
+	if errors.Is(isTokenExist, gorm.ErrRecordNotFound) {
+		return apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "failed to logout", errors.New("refresh token is invalid"))
+	}
+	if isTokenExist != nil {
+		return apperror.Internal("failed to logout", isTokenExist)
+	}
+
+	// revoke refresh token
+	err := userUsecase.userRepository.RevokeRefreshToken(ctx, &refreshToken)
+	if err != nil {
+		return apperror.Internal("failed to logout", err)
+	}
+
+	return nil
+}
+
+func newTokenApi(accessToken string, refreshToken string, expiresAt time.Time) domain.TokenApi {
+	return domain.TokenApi{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}
+}
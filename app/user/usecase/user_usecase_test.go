@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	user_mock "github.com/reyhanmichiels/AquaFarmManagement/app/user/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/auth"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var userRepositoryMock = user_mock.UserRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var tokenManagerMock = user_mock.TokenManagerMock{
+	Mock: mock.Mock{},
+}
+
+var userUsecase = NewUserUsecase(&userRepositoryMock, &tokenManagerMock, time.Hour)
+
+func TestRegister(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:             "testName",
+			Email:            "Test@Mail.com",
+			Password:         "testPassword",
+			OrganizationName: "testOrganization",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", "test@mail.com").Return(gorm.ErrRecordNotFound)
+		createUserMock := userRepositoryMock.Mock.On("CreateUser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = "userID"
+		})
+
+		//call usecase
+		successResponse, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, "userID", successResponse.ID, "id should be equal")
+		assert.Equal(t, "test@mail.com", successResponse.Email, "email should be lower cased")
+		assert.Equal(t, request.OrganizationName, successResponse.Organization.Name, "organization should be created")
+		assert.Equal(t, domain.OrganizationRoleAdmin, successResponse.Role, "registering user should administer organization")
+		assert.Nil(t, auth.ComparePassword(successResponse.Password, request.Password), "password should be hashed")
+
+		findUserMock.Unset()
+		createUserMock.Unset()
+	})
+
+	t.Run("should return error when email is already used", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "test@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(nil)
+
+		//call usecase
+		_, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeEmailTaken, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to register user", errObject.Message, "message should be equal")
+
+		findUserMock.Unset()
+	})
+
+	t.Run("should return error when failed to create user", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "test@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(gorm.ErrRecordNotFound)
+		createUserMock := userRepositoryMock.Mock.On("CreateUser", mock.Anything).Return(errors.New("testError"))
+
+		//call usecase
+		_, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
+
+		findUserMock.Unset()
+		createUserMock.Unset()
+	})
+
+	t.Run("should return error when email is taken concurrently", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "test@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(gorm.ErrRecordNotFound)
+		createUserMock := userRepositoryMock.Mock.On("CreateUser", mock.Anything).Return(gorm.ErrDuplicatedKey)
+
+		//call usecase
+		_, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeEmailTaken, errObject.Code, "code should be equal")
+
+		findUserMock.Unset()
+		createUserMock.Unset()
+	})
+
+	t.Run("should return error when failed to find user", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "test@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(errors.New("testError"))
+
+		//call usecase
+		_, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+		assert.Equal(t, errors.New("testError"), errObject.Err, "error should be equal")
+
+		findUserMock.Unset()
+	})
+
+	t.Run("should return error when password is longer than 72 bytes", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.RegisterBind{
+			Name:     "testName",
+			Email:    "test@mail.com",
+			Password: strings.Repeat("é", 40),
+		}
+
+		//call usecase
+		_, errorResponse := userUsecase.Register(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidRequest, errObject.Code, "code should be equal")
+	})
+}
+
+func TestCreateOrganizationUser(t *testing.T) {
+	authUser := domain.AuthUser{
+		ID:             "userID",
+		OrganizationID: "orgID",
+		Email:          "test@mail.com",
+	}
+
+	// mock the caller with an organization role
+	mockCaller := func(role domain.OrganizationRole) *mock.Call {
+		return userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "id = ?", authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = authUser.ID
+			arg.OrganizationID = authUser.OrganizationID
+			arg.Role = role
+		})
+	}
+
+	t.Run("should return success", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.OrganizationUserBind{
+			Name:     "colleagueName",
+			Email:    "colleague@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		callerMock := mockCaller(domain.OrganizationRoleAdmin)
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(gorm.ErrRecordNotFound)
+		createUserMock := userRepositoryMock.Mock.On("CreateUser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			arg.ID = "colleagueID"
+		})
+
+		//call usecase
+		successResponse, errorResponse := userUsecase.CreateOrganizationUser(context.Background(), authUser, request)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, "colleagueID", successResponse.ID, "id should be equal")
+		assert.Equal(t, authUser.OrganizationID, successResponse.OrganizationID, "organization should be equal")
+		assert.Equal(t, domain.OrganizationRoleMember, successResponse.Role, "role should be member")
+		assert.Empty(t, successResponse.Organization.Name, "no organization should be created")
+
+		callerMock.Unset()
+		findUserMock.Unset()
+		createUserMock.Unset()
+	})
+
+	t.Run("should return error when caller is not an organization admin", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.OrganizationUserBind{
+			Name:     "colleagueName",
+			Email:    "colleague@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		callerMock := mockCaller(domain.OrganizationRoleMember)
+
+		//call usecase
+		_, errorResponse := userUsecase.CreateOrganizationUser(context.Background(), authUser, request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeForbidden, errObject.Code, "code should be equal")
+
+		callerMock.Unset()
+	})
+
+	t.Run("should return error when email is already used", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.OrganizationUserBind{
+			Name:     "colleagueName",
+			Email:    "colleague@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		callerMock := mockCaller(domain.OrganizationRoleAdmin)
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(nil)
+
+		//call usecase
+		_, errorResponse := userUsecase.CreateOrganizationUser(context.Background(), authUser, request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindConflict, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to create organization user", errObject.Message, "message should be equal")
+
+		callerMock.Unset()
+		findUserMock.Unset()
+	})
+}
+
+func TestLogin(t *testing.T) {
+	hashedPassword, err := auth.HashPassword("testPassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := domain.User{
+		ID:       "userID",
+		Email:    "test@mail.com",
+		Password: hashedPassword,
+	}
+
+	t.Run("should return token pair", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.LoginBind{
+			Email:    user.Email,
+			Password: "testPassword",
+		}
+		expiresAt := time.Now().Add(time.Minute)
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", user.Email).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			*arg = user
+		})
+		generateTokenMock := tokenManagerMock.Mock.On("GenerateAccessToken", user).Return("accessToken", expiresAt, nil)
+		createTokenMock := userRepositoryMock.Mock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+		//call usecase
+		successResponse, errorResponse := userUsecase.Login(context.Background(), request)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, "accessToken", successResponse.AccessToken, "access token should be equal")
+		assert.Equal(t, "Bearer", successResponse.TokenType, "token type should be equal")
+		assert.Equal(t, expiresAt, successResponse.ExpiresAt, "expires at should be equal")
+		assert.NotEmpty(t, successResponse.RefreshToken, "refresh token should not be empty")
+
+		storedToken := createTokenMock.Parent.Calls[len(createTokenMock.Parent.Calls)-1].Arguments[0].(*domain.RefreshToken)
+		assert.Equal(t, auth.HashRefreshToken(successResponse.RefreshToken), storedToken.TokenHash, "only token hash should be stored")
+		assert.Equal(t, user.ID, storedToken.UserID, "user id should be equal")
+
+		findUserMock.Unset()
+		generateTokenMock.Unset()
+		createTokenMock.Unset()
+	})
+
+	t.Run("should return error when password is wrong", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.LoginBind{
+			Email:    user.Email,
+			Password: "wrongPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", user.Email).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			*arg = user
+		})
+
+		//call usecase
+		_, errorResponse := userUsecase.Login(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindUnauthorized, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidCredentials, errObject.Code, "code should be equal")
+
+		findUserMock.Unset()
+	})
+
+	t.Run("should return error when user is not found", func(t *testing.T) {
+		//prepare usecase parameter
+		request := domain.LoginBind{
+			Email:    "unknown@mail.com",
+			Password: "testPassword",
+		}
+
+		//call mock
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "email = ?", request.Email).Return(gorm.ErrRecordNotFound)
+
+		//call usecase
+		_, errorResponse := userUsecase.Login(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindUnauthorized, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidCredentials, errObject.Code, "code should be equal")
+
+		findUserMock.Unset()
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	request := domain.RefreshTokenBind{
+		RefreshToken: "refreshToken",
+	}
+	tokenHash := auth.HashRefreshToken(request.RefreshToken)
+	user := domain.User{
+		ID:    "userID",
+		Email: "test@mail.com",
+	}
+
+	t.Run("should rotate token pair", func(t *testing.T) {
+		//call mock
+		oldToken := domain.RefreshToken{
+			ID:        "tokenID",
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		expiresAt := time.Now().Add(time.Minute)
+
+		findTokenMock := userRepositoryMock.Mock.On("FindRefreshToken", &domain.RefreshToken{}, tokenHash).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.RefreshToken)
+			*arg = oldToken
+		})
+		findUserMock := userRepositoryMock.Mock.On("FindUserByCondition", &domain.User{}, "id = ?", user.ID).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.User)
+			*arg = user
+		})
+		generateTokenMock := tokenManagerMock.Mock.On("GenerateAccessToken", user).Return("accessToken", expiresAt, nil)
+		rotateTokenMock := userRepositoryMock.Mock.On("RotateRefreshToken", &oldToken, mock.Anything).Return(nil)
+
+		//call usecase
+		successResponse, errorResponse := userUsecase.Refresh(context.Background(), request)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+		assert.Equal(t, "accessToken", successResponse.AccessToken, "access token should be equal")
+		assert.NotEqual(t, request.RefreshToken, successResponse.RefreshToken, "refresh token should be rotated")
+
+		findTokenMock.Unset()
+		findUserMock.Unset()
+		generateTokenMock.Unset()
+		rotateTokenMock.Unset()
+	})
+
+	t.Run("should revoke every session when revoked token is reused", func(t *testing.T) {
+		//call mock
+		revokedAt := time.Now().Add(-time.Minute)
+		findTokenMock := userRepositoryMock.Mock.On("FindRefreshToken", &domain.RefreshToken{}, tokenHash).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.RefreshToken)
+			arg.UserID = user.ID
+			arg.ExpiresAt = time.Now().Add(time.Hour)
+			arg.RevokedAt = &revokedAt
+		})
+		revokeTokensMock := userRepositoryMock.Mock.On("RevokeUserRefreshTokens", user.ID).Return(nil)
+
+		//call usecase
+		_, errorResponse := userUsecase.Refresh(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindUnauthorized, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidRefreshToken, errObject.Code, "code should be equal")
+		revokeTokensMock.Parent.AssertCalled(t, "RevokeUserRefreshTokens", user.ID)
+
+		findTokenMock.Unset()
+		revokeTokensMock.Unset()
+	})
+
+	t.Run("should return error when token is expired", func(t *testing.T) {
+		//call mock
+		findTokenMock := userRepositoryMock.Mock.On("FindRefreshToken", &domain.RefreshToken{}, tokenHash).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.RefreshToken)
+			arg.UserID = user.ID
+			arg.ExpiresAt = time.Now().Add(-time.Minute)
+		})
+
+		//call usecase
+		_, errorResponse := userUsecase.Refresh(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindUnauthorized, errObject.Kind, "kind should be equal")
+		assert.Equal(t, errors.New("refresh token is expired"), errObject.Err, "error should be equal")
+
+		findTokenMock.Unset()
+	})
+}
+
+func TestLogout(t *testing.T) {
+	request := domain.RefreshTokenBind{
+		RefreshToken: "refreshToken",
+	}
+	tokenHash := auth.HashRefreshToken(request.RefreshToken)
+
+	t.Run("should revoke refresh token", func(t *testing.T) {
+		//call mock
+		refreshToken := domain.RefreshToken{
+			ID: "tokenID",
+		}
+		findTokenMock := userRepositoryMock.Mock.On("FindRefreshToken", &domain.RefreshToken{}, tokenHash).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.RefreshToken)
+			*arg = refreshToken
+		})
+		revokeTokenMock := userRepositoryMock.Mock.On("RevokeRefreshToken", &refreshToken).Return(nil)
+
+		//call usecase
+		errorResponse := userUsecase.Logout(context.Background(), request)
+
+		//test result
+		assert.Nil(t, errorResponse, "err response should be nil")
+
+		findTokenMock.Unset()
+		revokeTokenMock.Unset()
+	})
+
+	t.Run("should return error when token is unknown", func(t *testing.T) {
+		//call mock
+		findTokenMock := userRepositoryMock.Mock.On("FindRefreshToken", &domain.RefreshToken{}, tokenHash).Return(gorm.ErrRecordNotFound)
+
+		//call usecase
+		errorResponse := userUsecase.Logout(context.Background(), request)
+
+		//test result
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindUnauthorized, errObject.Kind, "kind should be equal")
+		assert.Equal(t, "failed to logout", errObject.Message, "message should be equal")
+
+		findTokenMock.Unset()
+	})
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/usecase"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+type WaterQualityHandler struct {
+	waterQualityUsecase usecase.IWaterQualityUsecase
+}
+
+func NewWaterQualityHandler(waterQualityUsecase usecase.IWaterQualityUsecase) *WaterQualityHandler {
+	return &WaterQualityHandler{
+		waterQualityUsecase: waterQualityUsecase,
+	}
+}
+
+func (waterQualityHandler *WaterQualityHandler) CreateReading(c *gin.Context) {
+	//bind request
+	var request domain.WaterReadingBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//create reading
+	reading, err := waterQualityHandler.waterQualityUsecase.CreateReading(c.Request.Context(), middleware.GetAuthUser(c), request, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "successfully create water reading", reading)
+}
+
+func (waterQualityHandler *WaterQualityHandler) GetReadings(c *gin.Context) {
+	//bind query
+	var query domain.WaterReadingQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+
+	//get readings
+	readings, pagination, err := waterQualityHandler.waterQualityUsecase.GetReadings(c.Request.Context(), middleware.GetAuthUser(c), query, pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get water readings", readings, pagination)
+}
+
+func (waterQualityHandler *WaterQualityHandler) GetFarmThresholds(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+
+	//get thresholds
+	thresholds, err := waterQualityHandler.waterQualityUsecase.GetFarmThresholds(c.Request.Context(), middleware.GetAuthUser(c), farmId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get water thresholds", thresholds)
+}
+
+func (waterQualityHandler *WaterQualityHandler) SetFarmThreshold(c *gin.Context) {
+	//bind request
+	var request domain.WaterThresholdBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	farmId := c.Param("farmId")
+	parameter := c.Param("parameter")
+
+	//set threshold
+	threshold, err := waterQualityHandler.waterQualityUsecase.SetFarmThreshold(c.Request.Context(), middleware.GetAuthUser(c), request, farmId, parameter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully set water threshold", threshold)
+}
+
+func (waterQualityHandler *WaterQualityHandler) DeleteFarmThreshold(c *gin.Context) {
+	//bind param
+	farmId := c.Param("farmId")
+	parameter := c.Param("parameter")
+
+	//delete threshold
+	err := waterQualityHandler.waterQualityUsecase.DeleteFarmThreshold(c.Request.Context(), middleware.GetAuthUser(c), farmId, parameter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully delete water threshold", nil)
+}
+
+func (waterQualityHandler *WaterQualityHandler) GetPondThresholds(c *gin.Context) {
+	//bind param
+	pondId := c.Param("pondId")
+
+	//get thresholds
+	thresholds, err := waterQualityHandler.waterQualityUsecase.GetPondThresholds(c.Request.Context(), middleware.GetAuthUser(c), pondId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully get water thresholds", thresholds)
+}
+
+func (waterQualityHandler *WaterQualityHandler) SetPondThreshold(c *gin.Context) {
+	//bind request
+	var request domain.WaterThresholdBind
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	pondId := c.Param("pondId")
+	parameter := c.Param("parameter")
+
+	//set threshold
+	threshold, err := waterQualityHandler.waterQualityUsecase.SetPondThreshold(c.Request.Context(), middleware.GetAuthUser(c), request, pondId, parameter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully set water threshold", threshold)
+}
+
+func (waterQualityHandler *WaterQualityHandler) DeletePondThreshold(c *gin.Context) {
+	//bind param
+	pondId := c.Param("pondId")
+	parameter := c.Param("parameter")
+
+	//delete threshold
+	err := waterQualityHandler.waterQualityUsecase.DeletePondThreshold(c.Request.Context(), middleware.GetAuthUser(c), pondId, parameter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "successfully delete water threshold", nil)
+}
+
+func (waterQualityHandler *WaterQualityHandler) GetAlerts(c *gin.Context) {
+	//bind query
+	var query domain.WaterAlertQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidRequest, "failed to bind request", err))
+		return
+	}
+
+	//get alerts
+	alerts, pagination, err := waterQualityHandler.waterQualityUsecase.GetAlerts(c.Request.Context(), middleware.GetAuthUser(c), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	util.SetPaginationLinks(c, &pagination)
+	util.SuccessPaginatedResponse(c, http.StatusOK, "successfully get water alerts", alerts, pagination)
+}
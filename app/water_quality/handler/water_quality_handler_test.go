@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	water_quality_mock "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var waterQualityUsecaseMock = water_quality_mock.WaterQualityUsecaseMock{
+	Mock: mock.Mock{},
+}
+
+var waterQualityHandler = NewWaterQualityHandler(&waterQualityUsecaseMock)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+// stand in for the authenticate middleware
+func authenticated(c *gin.Context) {
+	middleware.SetAuthUser(c, authUser)
+	c.Next()
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestCreateReading(t *testing.T) {
+	t.Run("should can create reading", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.WaterReadingBind{
+			MeasuredAt:      time.Date(2024, 2, 1, 6, 0, 0, 0, time.UTC),
+			DissolvedOxygen: float(5.2),
+			PH:              float(9),
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		mockResponse := domain.WaterReading{
+			ID:              "readingID",
+			PondID:          "pondID",
+			MeasuredAt:      requestBody.MeasuredAt,
+			DissolvedOxygen: requestBody.DissolvedOxygen,
+			PH:              requestBody.PH,
+			Alerts: []domain.WaterAlert{
+				{ID: "alertID", PondID: "pondID", Parameter: domain.WaterParameterPH, Value: 9, Max: float(8.5)},
+			},
+		}
+		mockCall := waterQualityUsecaseMock.Mock.On("CreateReading", authUser, requestBody, "pondID").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/readings", waterQualityHandler.CreateReading)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/readings", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusCreated, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully create water reading", responseBody["message"], "message should be equal")
+
+		readingData := responseBody["data"].(map[string]any)
+		assert.Equal(t, mockResponse.ID, readingData["id"], "reading id should be equal")
+		assert.Nil(t, readingData["temperature_c"], "temperature should be empty")
+		assert.Len(t, readingData["alerts"], 1, "alerts length should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when ph invalid", func(t *testing.T) {
+		// prepare request body
+		requestBodyJson := []byte(`{"measured_at":"2024-02-01T06:00:00Z","ph":15}`)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.POST("/api/ponds/:pondId/readings", waterQualityHandler.CreateReading)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/api/ponds/pondID/readings", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+		assert.Equal(t, "failed to bind request", responseBody["message"], "message should be equal")
+	})
+}
+
+func TestSetPondThreshold(t *testing.T) {
+	t.Run("should can set threshold", func(t *testing.T) {
+		// prepare request body
+		requestBody := domain.WaterThresholdBind{
+			Min: float(7.5),
+			Max: float(8.5),
+		}
+
+		requestBodyJson, err := json.Marshal(requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// call mock
+		pondId := "pondID"
+		mockResponse := domain.WaterThreshold{
+			ID:        "thresholdID",
+			FarmID:    "farmID",
+			PondID:    &pondId,
+			Parameter: domain.WaterParameterPH,
+			Min:       requestBody.Min,
+			Max:       requestBody.Max,
+		}
+		mockCall := waterQualityUsecaseMock.Mock.On("SetPondThreshold", authUser, requestBody, "pondID", "ph").Return(mockResponse, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.PUT("/api/ponds/:pondId/thresholds/:parameter", waterQualityHandler.SetPondThreshold)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/api/ponds/pondID/thresholds/ph", bytes.NewBuffer(requestBodyJson))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully set water threshold", responseBody["message"], "message should be equal")
+
+		thresholdData := responseBody["data"].(map[string]any)
+		assert.Equal(t, pondId, thresholdData["pond_id"], "pond id should be equal")
+		assert.Equal(t, *requestBody.Max, thresholdData["max"], "max should be equal")
+
+		mockCall.Unset()
+	})
+}
+
+func TestGetAlerts(t *testing.T) {
+	t.Run("should can get alerts", func(t *testing.T) {
+		// call mock
+		mockResponse := []domain.WaterAlert{
+			{ID: "alertID1", FarmID: "farmID", PondID: "pondID", Parameter: domain.WaterParameterDissolvedOxygen, Value: 2.1, Min: float(4)},
+			{ID: "alertID2", FarmID: "farmID", PondID: "pondID", Parameter: domain.WaterParameterDissolvedOxygen, Value: 2.8, Min: float(4)},
+		}
+		query := domain.WaterAlertQuery{
+			PageQuery: domain.PageQuery{Page: 1, PageSize: 2},
+			Parameter: domain.WaterParameterDissolvedOxygen,
+		}
+		mockPagination := domain.Pagination{Page: 1, PageSize: 2, Total: 3, TotalPages: 2}
+
+		mockCall := waterQualityUsecaseMock.Mock.On("GetAlerts", authUser, query).Return(mockResponse, mockPagination, nil)
+
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/alerts", waterQualityHandler.GetAlerts)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/alerts?parameter=dissolved_oxygen&page=1&page_size=2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// parsing response body
+		var responseBody map[string]any
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// test response
+		assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+		assert.Equal(t, "successfully get water alerts", responseBody["message"], "message should be equal")
+		assert.Len(t, responseBody["data"], 2, "data length should be equal")
+
+		meta := responseBody["meta"].(map[string]any)
+		assert.Equal(t, "/api/alerts?page=2&page_size=2&parameter=dissolved_oxygen", meta["next"], "next link should be equal")
+
+		mockCall.Unset()
+	})
+
+	t.Run("should reject when parameter invalid", func(t *testing.T) {
+		// call handler
+		engine := gin.Default()
+		engine.Use(middleware.HandleError)
+		engine.Use(authenticated)
+		engine.GET("/api/alerts", waterQualityHandler.GetAlerts)
+
+		response := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/api/alerts?parameter=turbidity", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.ServeHTTP(response, request)
+
+		// test response
+		assert.Equal(t, http.StatusBadRequest, response.Code, "status code should be equal")
+	})
+}
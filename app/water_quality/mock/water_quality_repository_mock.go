@@ -0,0 +1,92 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type WaterQualityRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) CreateReading(ctx context.Context, reading *domain.WaterReading) error {
+	args := waterQualityRepositoryMock.Mock.Called(reading)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) FindReadings(ctx context.Context, readings *[]domain.WaterReading, pondId string, query domain.WaterReadingQuery) (domain.Pagination, error) {
+	args := waterQualityRepositoryMock.Mock.Called(readings, pondId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) FindThresholdByCondition(ctx context.Context, threshold any, condition string, values ...any) error {
+	args := waterQualityRepositoryMock.Mock.Called(append([]any{threshold, condition}, values...)...)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) FindFarmThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string) error {
+	args := waterQualityRepositoryMock.Mock.Called(thresholds, farmId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) FindPondThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string, pondId string) error {
+	args := waterQualityRepositoryMock.Mock.Called(thresholds, farmId, pondId)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) SaveThreshold(ctx context.Context, threshold *domain.WaterThreshold) error {
+	args := waterQualityRepositoryMock.Mock.Called(threshold)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) DeleteThreshold(ctx context.Context, threshold *domain.WaterThreshold) error {
+	args := waterQualityRepositoryMock.Mock.Called(threshold)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityRepositoryMock *WaterQualityRepositoryMock) FindAlerts(ctx context.Context, alerts *[]domain.WaterAlert, organizationId string, userId string, query domain.WaterAlertQuery) (domain.Pagination, error) {
+	args := waterQualityRepositoryMock.Mock.Called(alerts, organizationId, userId, query)
+
+	if args[1] != nil {
+		return domain.Pagination{}, args[1].(error)
+	}
+
+	return args[0].(domain.Pagination), nil
+}
@@ -0,0 +1,102 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type WaterQualityUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) CreateReading(ctx context.Context, authUser domain.AuthUser, request domain.WaterReadingBind, pondId string) (domain.WaterReading, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, request, pondId)
+
+	if args[1] != nil {
+		return domain.WaterReading{}, args[1].(error)
+	}
+
+	return args[0].(domain.WaterReading), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) GetReadings(ctx context.Context, authUser domain.AuthUser, query domain.WaterReadingQuery, pondId string) ([]domain.WaterReading, domain.Pagination, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, query, pondId)
+
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
+	}
+
+	return args[0].([]domain.WaterReading), args[1].(domain.Pagination), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) GetFarmThresholds(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.WaterThreshold, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, farmId)
+
+	if args[1] != nil {
+		return nil, args[1].(error)
+	}
+
+	return args[0].([]domain.WaterThreshold), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) SetFarmThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, farmId string, parameter string) (domain.WaterThreshold, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, request, farmId, parameter)
+
+	if args[1] != nil {
+		return domain.WaterThreshold{}, args[1].(error)
+	}
+
+	return args[0].(domain.WaterThreshold), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) DeleteFarmThreshold(ctx context.Context, authUser domain.AuthUser, farmId string, parameter string) error {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, farmId, parameter)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) GetPondThresholds(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.WaterThreshold, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, pondId)
+
+	if args[1] != nil {
+		return nil, args[1].(error)
+	}
+
+	return args[0].([]domain.WaterThreshold), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) SetPondThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, pondId string, parameter string) (domain.WaterThreshold, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, request, pondId, parameter)
+
+	if args[1] != nil {
+		return domain.WaterThreshold{}, args[1].(error)
+	}
+
+	return args[0].(domain.WaterThreshold), nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) DeletePondThreshold(ctx context.Context, authUser domain.AuthUser, pondId string, parameter string) error {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, pondId, parameter)
+
+	if args[0] != nil {
+		return args[0].(error)
+	}
+
+	return nil
+}
+
+func (waterQualityUsecaseMock *WaterQualityUsecaseMock) GetAlerts(ctx context.Context, authUser domain.AuthUser, query domain.WaterAlertQuery) ([]domain.WaterAlert, domain.Pagination, error) {
+	args := waterQualityUsecaseMock.Mock.Called(authUser, query)
+
+	if args[2] != nil {
+		return nil, domain.Pagination{}, args[2].(error)
+	}
+
+	return args[0].([]domain.WaterAlert), args[1].(domain.Pagination), nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"gorm.io/gorm"
+)
+
+type IWaterQualityRepository interface {
+	CreateReading(ctx context.Context, reading *domain.WaterReading) error
+	FindReadings(ctx context.Context, readings *[]domain.WaterReading, pondId string, query domain.WaterReadingQuery) (domain.Pagination, error)
+	FindThresholdByCondition(ctx context.Context, threshold any, condition string, values ...any) error
+	FindFarmThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string) error
+	FindPondThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string, pondId string) error
+	SaveThreshold(ctx context.Context, threshold *domain.WaterThreshold) error
+	DeleteThreshold(ctx context.Context, threshold *domain.WaterThreshold) error
+	FindAlerts(ctx context.Context, alerts *[]domain.WaterAlert, organizationId string, userId string, query domain.WaterAlertQuery) (domain.Pagination, error)
+}
+
+type WaterQualityRepository struct {
+	db *gorm.DB
+}
+
+func NewWaterQualityRepository(db *gorm.DB) IWaterQualityRepository {
+	return &WaterQualityRepository{
+		db: db,
+	}
+}
+
+// create reading along with its alerts
+func (waterQualityRepository *WaterQualityRepository) CreateReading(ctx context.Context, reading *domain.WaterReading) error {
+	tx := waterQualityRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Create(reading).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (waterQualityRepository *WaterQualityRepository) FindReadings(ctx context.Context, readings *[]domain.WaterReading, pondId string, query domain.WaterReadingQuery) (domain.Pagination, error) {
+	db := waterQualityRepository.db.WithContext(ctx).Model(&domain.WaterReading{}).Where("water_readings.pond_id = ?", pondId)
+	if !query.MeasuredFrom.IsZero() {
+		db = db.Where("water_readings.measured_at >= ?", query.MeasuredFrom)
+	}
+	if !query.MeasuredTo.IsZero() {
+		db = db.Where("water_readings.measured_at <= ?", query.MeasuredTo)
+	}
+
+	return util.Paginate(db, "water_readings", query.PageQuery, domain.WaterReadingSortFields, readings)
+}
+
+func (waterQualityRepository *WaterQualityRepository) FindThresholdByCondition(ctx context.Context, threshold any, condition string, values ...any) error {
+	err := waterQualityRepository.db.WithContext(ctx).Model(&domain.WaterThreshold{}).Where(condition, values...).First(threshold).Error
+	return err
+}
+
+func (waterQualityRepository *WaterQualityRepository) FindFarmThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string) error {
+	err := waterQualityRepository.db.WithContext(ctx).Model(&domain.WaterThreshold{}).Where("farm_id = ? AND pond_id IS NULL", farmId).Order("parameter").Find(thresholds).Error
+	return err
+}
+
+// thresholds of the farm and overrides of the pond
+func (waterQualityRepository *WaterQualityRepository) FindPondThresholds(ctx context.Context, thresholds *[]domain.WaterThreshold, farmId string, pondId string) error {
+	err := waterQualityRepository.db.WithContext(ctx).Model(&domain.WaterThreshold{}).Where("(farm_id = ? AND pond_id IS NULL) OR pond_id = ?", farmId, pondId).Order("parameter").Find(thresholds).Error
+	return err
+}
+
+func (waterQualityRepository *WaterQualityRepository) SaveThreshold(ctx context.Context, threshold *domain.WaterThreshold) error {
+	tx := waterQualityRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Save(threshold).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (waterQualityRepository *WaterQualityRepository) DeleteThreshold(ctx context.Context, threshold *domain.WaterThreshold) error {
+	tx := waterQualityRepository.db.WithContext(ctx).Begin()
+
+	err := tx.Delete(threshold).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (waterQualityRepository *WaterQualityRepository) FindAlerts(ctx context.Context, alerts *[]domain.WaterAlert, organizationId string, userId string, query domain.WaterAlertQuery) (domain.Pagination, error) {
+	organizationFarms := waterQualityRepository.db.WithContext(ctx).Model(&domain.Farm{}).Select("id").Where("organization_id = ?", organizationId)
+	memberFarms := waterQualityRepository.db.WithContext(ctx).Model(&domain.FarmMember{}).Select("farm_id").Where("user_id = ?", userId)
+	db := waterQualityRepository.db.WithContext(ctx).Model(&domain.WaterAlert{}).
+		Where("water_alerts.farm_id IN (?)", organizationFarms).
+		Where("water_alerts.farm_id IN (?)", memberFarms)
+	if query.FarmID != "" {
+		db = db.Where("water_alerts.farm_id = ?", query.FarmID)
+	}
+	if query.PondID != "" {
+		db = db.Where("water_alerts.pond_id = ?", query.PondID)
+	}
+	if query.Parameter != "" {
+		db = db.Where("water_alerts.parameter = ?", query.Parameter)
+	}
+
+	return util.Paginate(db, "water_alerts", query.PageQuery, domain.WaterAlertSortFields, alerts)
+}
@@ -0,0 +1,379 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
+	farm_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
+	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
+	pond_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/pond/usecase"
+	water_quality_repository "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IWaterQualityUsecase interface {
+	CreateReading(ctx context.Context, authUser domain.AuthUser, request domain.WaterReadingBind, pondId string) (domain.WaterReading, error)
+	GetReadings(ctx context.Context, authUser domain.AuthUser, query domain.WaterReadingQuery, pondId string) ([]domain.WaterReading, domain.Pagination, error)
+	GetFarmThresholds(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.WaterThreshold, error)
+	SetFarmThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, farmId string, parameter string) (domain.WaterThreshold, error)
+	DeleteFarmThreshold(ctx context.Context, authUser domain.AuthUser, farmId string, parameter string) error
+	GetPondThresholds(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.WaterThreshold, error)
+	SetPondThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, pondId string, parameter string) (domain.WaterThreshold, error)
+	DeletePondThreshold(ctx context.Context, authUser domain.AuthUser, pondId string, parameter string) error
+	GetAlerts(ctx context.Context, authUser domain.AuthUser, query domain.WaterAlertQuery) ([]domain.WaterAlert, domain.Pagination, error)
+}
+
+type WaterQualityUsecase struct {
+	waterQualityRepository water_quality_repository.IWaterQualityRepository
+	pondRepository         pond_repository.IPondRepository
+	farmRepository         farm_repository.IFarmRepository
+}
+
+func NewWaterQualityUsecase(waterQualityRepository water_quality_repository.IWaterQualityRepository, pondRepository pond_repository.IPondRepository, farmRepository farm_repository.IFarmRepository) IWaterQualityUsecase {
+	return &WaterQualityUsecase{
+		waterQualityRepository: waterQualityRepository,
+		pondRepository:         pondRepository,
+		farmRepository:         farmRepository,
+	}
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) CreateReading(ctx context.Context, authUser domain.AuthUser, request domain.WaterReadingBind, pondId string) (domain.WaterReading, error) {
+	// check if user can log data of pond
+	pond, err := pond_usecase.AuthorizePond(ctx, waterQualityUsecase.pondRepository, waterQualityUsecase.farmRepository, authUser, pondId, domain.PermissionLogData, "failed to create water reading")
+	if err != nil {
+		return domain.WaterReading{}, err
+	}
+
+	reading := domain.WaterReading{
+		PondID:          pondId,
+		MeasuredAt:      request.MeasuredAt,
+		DissolvedOxygen: request.DissolvedOxygen,
+		PH:              request.PH,
+		Temperature:     request.Temperature,
+		Ammonia:         request.Ammonia,
+		Nitrite:         request.Nitrite,
+		Salinity:        request.Salinity,
+	}
+
+	// check if reading has any value
+	values := reading.Values()
+	isReadingEmpty := true
+	for _, value := range values {
+		if value != nil {
+			isReadingEmpty = false
+		}
+	}
+	if isReadingEmpty {
+		return domain.WaterReading{}, apperror.Validation(apperror.CodeEmptyReading, "failed to create water reading", errors.New("reading has no measured parameter"))
+	}
+
+	// get thresholds in effect for pond
+	thresholds, err := waterQualityUsecase.findPondThresholds(ctx, pond, "failed to create water reading")
+	if err != nil {
+		return domain.WaterReading{}, err
+	}
+
+	// raise alert for every value out of range
+	for _, threshold := range thresholds {
+		value := values[threshold.Parameter]
+		if value == nil || !threshold.Violates(*value) {
+			continue
+		}
+
+		reading.Alerts = append(reading.Alerts, domain.WaterAlert{
+			FarmID:    pond.FarmID,
+			PondID:    pondId,
+			Parameter: threshold.Parameter,
+			Value:     *value,
+			Min:       threshold.Min,
+			Max:       threshold.Max,
+		})
+	}
+
+	// create reading
+	err = waterQualityUsecase.waterQualityRepository.CreateReading(ctx, &reading)
+	if err != nil {
+		return domain.WaterReading{}, apperror.Internal("failed to create water reading", err)
+	}
+
+	if len(reading.Alerts) > 0 {
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"POND_ID":    pondId,
+			"READING_ID": reading.ID,
+			"ALERTS":     len(reading.Alerts),
+		}).Warn("Water quality out of range")
+	}
+
+	return reading, nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) GetReadings(ctx context.Context, authUser domain.AuthUser, query domain.WaterReadingQuery, pondId string) ([]domain.WaterReading, domain.Pagination, error) {
+	// validate sort and cursor
+	err := util.ValidatePageQuery(query.PageQuery, domain.WaterReadingSortFields)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get water readings", err)
+	}
+
+	// check if user can view pond
+	_, err = pond_usecase.AuthorizePond(ctx, waterQualityUsecase.pondRepository, waterQualityUsecase.farmRepository, authUser, pondId, domain.PermissionView, "failed to get water readings")
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	// get readings
+	var readings []domain.WaterReading
+	pagination, err := waterQualityUsecase.waterQualityRepository.FindReadings(ctx, &readings, pondId, query)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Internal("failed to get water readings", err)
+	}
+
+	// check if reading exist
+	if len(readings) == 0 {
+		return nil, domain.Pagination{}, apperror.NotFound(apperror.CodeReadingNotFound, "failed to get water readings", errors.New("reading not found"))
+	}
+
+	return readings, pagination, nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) GetFarmThresholds(ctx context.Context, authUser domain.AuthUser, farmId string) ([]domain.WaterThreshold, error) {
+	// check if user can view farm
+	err := farm_usecase.Authorize(ctx, waterQualityUsecase.farmRepository, authUser.ID, farmId, domain.PermissionView, "failed to get water thresholds")
+	if err != nil {
+		return nil, err
+	}
+
+	// get thresholds
+	var thresholds []domain.WaterThreshold
+	err = waterQualityUsecase.waterQualityRepository.FindFarmThresholds(ctx, &thresholds, farmId)
+	if err != nil {
+		return nil, apperror.Internal("failed to get water thresholds", err)
+	}
+
+	// check if threshold exist
+	if len(thresholds) == 0 {
+		return nil, apperror.NotFound(apperror.CodeThresholdNotFound, "failed to get water thresholds", errors.New("threshold not found"))
+	}
+
+	return thresholds, nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) SetFarmThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, farmId string, parameter string) (domain.WaterThreshold, error) {
+	err := validateThreshold(request, parameter, "failed to set water threshold")
+	if err != nil {
+		return domain.WaterThreshold{}, err
+	}
+
+	// check if user can manage farm
+	err = farm_usecase.Authorize(ctx, waterQualityUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to set water threshold")
+	if err != nil {
+		return domain.WaterThreshold{}, err
+	}
+
+	// replace threshold of farm if exist
+	var threshold domain.WaterThreshold
+	isThresholdExist := waterQualityUsecase.waterQualityRepository.FindThresholdByCondition(ctx, &threshold, "farm_id = ? AND pond_id IS NULL AND parameter = ?", farmId, parameter)
+	if isThresholdExist != nil && !errors.Is(isThresholdExist, gorm.ErrRecordNotFound) {
+		return domain.WaterThreshold{}, apperror.Internal("failed to set water threshold", isThresholdExist)
+	}
+	if isThresholdExist != nil {
+		threshold = domain.WaterThreshold{
+			FarmID:    farmId,
+			Parameter: domain.WaterParameter(parameter),
+		}
+	}
+
+	return waterQualityUsecase.saveThreshold(ctx, threshold, request)
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) DeleteFarmThreshold(ctx context.Context, authUser domain.AuthUser, farmId string, parameter string) error {
+	// check if user can manage farm
+	err := farm_usecase.Authorize(ctx, waterQualityUsecase.farmRepository, authUser.ID, farmId, domain.PermissionManage, "failed to delete water threshold")
+	if err != nil {
+		return err
+	}
+
+	// check if threshold exist
+	var threshold domain.WaterThreshold
+	isThresholdExist := waterQualityUsecase.waterQualityRepository.FindThresholdByCondition(ctx, &threshold, "farm_id = ? AND pond_id IS NULL AND parameter = ?", farmId, parameter)
+	if errors.Is(isThresholdExist, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeThresholdNotFound, "failed to delete water threshold", errors.New("threshold not found"))
+	}
+	if isThresholdExist != nil {
+		return apperror.Internal("failed to delete water threshold", isThresholdExist)
+	}
+
+	// delete threshold
+	err = waterQualityUsecase.waterQualityRepository.DeleteThreshold(ctx, &threshold)
+	if err != nil {
+		return apperror.Internal("failed to delete water threshold", err)
+	}
+
+	return nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) GetPondThresholds(ctx context.Context, authUser domain.AuthUser, pondId string) ([]domain.WaterThreshold, error) {
+	// check if user can view pond
+	pond, err := pond_usecase.AuthorizePond(ctx, waterQualityUsecase.pondRepository, waterQualityUsecase.farmRepository, authUser, pondId, domain.PermissionView, "failed to get water thresholds")
+	if err != nil {
+		return nil, err
+	}
+
+	// get thresholds in effect for pond
+	thresholds, err := waterQualityUsecase.findPondThresholds(ctx, pond, "failed to get water thresholds")
+	if err != nil {
+		return nil, err
+	}
+
+	// check if threshold exist
+	if len(thresholds) == 0 {
+		return nil, apperror.NotFound(apperror.CodeThresholdNotFound, "failed to get water thresholds", errors.New("threshold not found"))
+	}
+
+	return thresholds, nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) SetPondThreshold(ctx context.Context, authUser domain.AuthUser, request domain.WaterThresholdBind, pondId string, parameter string) (domain.WaterThreshold, error) {
+	err := validateThreshold(request, parameter, "failed to set water threshold")
+	if err != nil {
+		return domain.WaterThreshold{}, err
+	}
+
+	// check if user can manage pond
+	pond, err := pond_usecase.AuthorizePond(ctx, waterQualityUsecase.pondRepository, waterQualityUsecase.farmRepository, authUser, pondId, domain.PermissionManage, "failed to set water threshold")
+	if err != nil {
+		return domain.WaterThreshold{}, err
+	}
+
+	// replace override of pond if exist
+	var threshold domain.WaterThreshold
+	isThresholdExist := waterQualityUsecase.waterQualityRepository.FindThresholdByCondition(ctx, &threshold, "pond_id = ? AND parameter = ?", pondId, parameter)
+	if isThresholdExist != nil && !errors.Is(isThresholdExist, gorm.ErrRecordNotFound) {
+		return domain.WaterThreshold{}, apperror.Internal("failed to set water threshold", isThresholdExist)
+	}
+	if isThresholdExist != nil {
+		threshold = domain.WaterThreshold{
+			PondID:    &pond.ID,
+			Parameter: domain.WaterParameter(parameter),
+		}
+	}
+	threshold.FarmID = pond.FarmID
+
+	return waterQualityUsecase.saveThreshold(ctx, threshold, request)
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) DeletePondThreshold(ctx context.Context, authUser domain.AuthUser, pondId string, parameter string) error {
+	// check if user can manage pond
+	_, err := pond_usecase.AuthorizePond(ctx, waterQualityUsecase.pondRepository, waterQualityUsecase.farmRepository, authUser, pondId, domain.PermissionManage, "failed to delete water threshold")
+	if err != nil {
+		return err
+	}
+
+	// check if threshold exist
+	var threshold domain.WaterThreshold
+	isThresholdExist := waterQualityUsecase.waterQualityRepository.FindThresholdByCondition(ctx, &threshold, "pond_id = ? AND parameter = ?", pondId, parameter)
+	if errors.Is(isThresholdExist, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeThresholdNotFound, "failed to delete water threshold", errors.New("threshold not found"))
+	}
+	if isThresholdExist != nil {
+		return apperror.Internal("failed to delete water threshold", isThresholdExist)
+	}
+
+	// delete threshold
+	err = waterQualityUsecase.waterQualityRepository.DeleteThreshold(ctx, &threshold)
+	if err != nil {
+		return apperror.Internal("failed to delete water threshold", err)
+	}
+
+	return nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) GetAlerts(ctx context.Context, authUser domain.AuthUser, query domain.WaterAlertQuery) ([]domain.WaterAlert, domain.Pagination, error) {
+	// validate sort and cursor
+	err := util.ValidatePageQuery(query.PageQuery, domain.WaterAlertSortFields)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Validation(apperror.CodeInvalidQuery, "failed to get water alerts", err)
+	}
+
+	// get alerts
+	var alerts []domain.WaterAlert
+	pagination, err := waterQualityUsecase.waterQualityRepository.FindAlerts(ctx, &alerts, authUser.OrganizationID, authUser.ID, query)
+	if err != nil {
+		return nil, domain.Pagination{}, apperror.Internal("failed to get water alerts", err)
+	}
+
+	// check if alert exist
+	if len(alerts) == 0 {
+		return nil, domain.Pagination{}, apperror.NotFound(apperror.CodeAlertNotFound, "failed to get water alerts", errors.New("alert not found"))
+	}
+
+	return alerts, pagination, nil
+}
+
+// thresholds of the pond farm with the pond overrides applied, one per parameter
+func (waterQualityUsecase *WaterQualityUsecase) findPondThresholds(ctx context.Context, pond domain.Pond, message string) ([]domain.WaterThreshold, error) {
+	var thresholds []domain.WaterThreshold
+	err := waterQualityUsecase.waterQualityRepository.FindPondThresholds(ctx, &thresholds, pond.FarmID, pond.ID)
+	if err != nil {
+		return nil, apperror.Internal(message, err)
+	}
+
+	byParameter := map[domain.WaterParameter]domain.WaterThreshold{}
+	for _, threshold := range thresholds {
+		current, isExist := byParameter[threshold.Parameter]
+		if isExist && current.PondID != nil {
+			continue
+		}
+		byParameter[threshold.Parameter] = threshold
+	}
+
+	effective := []domain.WaterThreshold{}
+	for _, parameter := range domain.WaterParameters {
+		threshold, isExist := byParameter[parameter]
+		if isExist {
+			effective = append(effective, threshold)
+		}
+	}
+
+	return effective, nil
+}
+
+func (waterQualityUsecase *WaterQualityUsecase) saveThreshold(ctx context.Context, threshold domain.WaterThreshold, request domain.WaterThresholdBind) (domain.WaterThreshold, error) {
+	threshold.Min = request.Min
+	threshold.Max = request.Max
+	err := waterQualityUsecase.waterQualityRepository.SaveThreshold(ctx, &threshold)
+	if err != nil {
+		return domain.WaterThreshold{}, apperror.Internal("failed to set water threshold", err)
+	}
+
+	return threshold, nil
+}
+
+// check parameter is known and the bounds form a range
+func validateThreshold(request domain.WaterThresholdBind, parameter string, message string) error {
+	isParameterValid := false
+	for _, waterParameter := range domain.WaterParameters {
+		if string(waterParameter) == parameter {
+			isParameterValid = true
+		}
+	}
+	if !isParameterValid {
+		return apperror.Validation(apperror.CodeInvalidParameter, message, fmt.Errorf("unknown water parameter %s", parameter))
+	}
+
+	if request.Min == nil && request.Max == nil {
+		return apperror.Validation(apperror.CodeInvalidThreshold, message, errors.New("threshold needs a min or a max"))
+	}
+
+	if request.Min != nil && request.Max != nil && *request.Min > *request.Max {
+		return apperror.Validation(apperror.CodeInvalidThreshold, message, errors.New("min is greater than max"))
+	}
+
+	return nil
+}
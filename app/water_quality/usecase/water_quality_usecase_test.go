@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	farm_mock "github.com/reyhanmichiels/AquaFarmManagement/app/farm/mock"
+	pond_mock "github.com/reyhanmichiels/AquaFarmManagement/app/pond/mock"
+	water_quality_mock "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/mock"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var waterQualityRepository = water_quality_mock.WaterQualityRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var pondRepository = pond_mock.PondRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var farmRepository = farm_mock.FarmRepositoryMock{
+	Mock: mock.Mock{},
+}
+
+var waterQualityUsecase = NewWaterQualityUsecase(&waterQualityRepository, &pondRepository, &farmRepository)
+
+var authUser = domain.AuthUser{
+	ID:             "userId",
+	OrganizationID: "orgId",
+	Email:          "user@mail.com",
+}
+
+var measuredAt = time.Date(2024, 2, 1, 6, 0, 0, 0, time.UTC)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+// mock pond of farmID where authUser has role
+func mockPondAccess(pondId string, role domain.Role) (*mock.Call, *mock.Call) {
+	pondCall := pondRepository.Mock.On("FindPondByCondition", &domain.Pond{}, authUser.OrganizationID, "id = ?", pondId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.Pond)
+		arg.ID = pondId
+		arg.FarmID = "farmID"
+	})
+
+	return pondCall, mockMember("farmID", role)
+}
+
+func mockMember(farmId string, role domain.Role) *mock.Call {
+	return farmRepository.Mock.On("FindMember", &domain.FarmMember{}, farmId, authUser.ID).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*domain.FarmMember)
+		arg.Role = role
+	})
+}
+
+// mock farm thresholds of dissolved oxygen and ph with a pond override of dissolved oxygen
+func mockPondThresholds(pondId string) *mock.Call {
+	var thresholds []domain.WaterThreshold
+	return waterQualityRepository.Mock.On("FindPondThresholds", &thresholds, "farmID", pondId).Return(nil).Run(func(args mock.Arguments) {
+		arg := args[0].(*[]domain.WaterThreshold)
+		*arg = []domain.WaterThreshold{
+			{ID: "farmDO", FarmID: "farmID", Parameter: domain.WaterParameterDissolvedOxygen, Min: float(4)},
+			{ID: "pondDO", FarmID: "farmID", PondID: &pondId, Parameter: domain.WaterParameterDissolvedOxygen, Min: float(3)},
+			{ID: "farmPH", FarmID: "farmID", Parameter: domain.WaterParameterPH, Min: float(7.5), Max: float(8.5)},
+		}
+	})
+}
+
+func TestCreateReading(t *testing.T) {
+	t.Run("should raise alert for value out of range", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterReadingBind{
+			MeasuredAt:      measuredAt,
+			DissolvedOxygen: float(3.5),
+			PH:              float(9),
+		}
+
+		// call mock
+		var createdReading *domain.WaterReading
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+		thresholdsMock := mockPondThresholds("pondID")
+		createReadingMock := waterQualityRepository.Mock.On("CreateReading", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			createdReading = args[0].(*domain.WaterReading)
+			createdReading.ID = "readingID"
+		})
+
+		// call usecase
+		successResponse, errorResponse := waterQualityUsecase.CreateReading(context.Background(), authUser, request, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, "readingID", successResponse.ID, "reading id should be equal")
+		assert.Equal(t, "pondID", createdReading.PondID, "pond id should be equal")
+		assert.Len(t, successResponse.Alerts, 1, "only ph should be out of range")
+		assert.Equal(t, domain.WaterParameterPH, successResponse.Alerts[0].Parameter, "parameter should be equal")
+		assert.Equal(t, float64(9), successResponse.Alerts[0].Value, "value should be equal")
+		assert.Equal(t, "farmID", successResponse.Alerts[0].FarmID, "farm id should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		thresholdsMock.Unset()
+		createReadingMock.Unset()
+	})
+
+	t.Run("should return error when reading is empty", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterReadingBind{
+			MeasuredAt: measuredAt,
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleTechnician)
+
+		// call usecase
+		_, errorResponse := waterQualityUsecase.CreateReading(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeEmptyReading, errObject.Code, "code should be equal")
+		assert.Equal(t, "failed to create water reading", errObject.Message, "message should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+	})
+
+	t.Run("should return error when user is auditor", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterReadingBind{
+			MeasuredAt: measuredAt,
+			PH:         float(8),
+		}
+
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+
+		// call usecase
+		_, errorResponse := waterQualityUsecase.CreateReading(context.Background(), authUser, request, "pondID")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+	})
+}
+
+func TestGetPondThresholds(t *testing.T) {
+	t.Run("should apply pond override over farm threshold", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleAuditor)
+		thresholdsMock := mockPondThresholds("pondID")
+
+		// call usecase
+		successResponse, errorResponse := waterQualityUsecase.GetPondThresholds(context.Background(), authUser, "pondID")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Len(t, successResponse, 2, "thresholds length should be equal")
+		assert.Equal(t, "pondDO", successResponse[0].ID, "pond override should win")
+		assert.Equal(t, "farmPH", successResponse[1].ID, "farm threshold should apply")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		thresholdsMock.Unset()
+	})
+}
+
+func TestSetFarmThreshold(t *testing.T) {
+	t.Run("should create threshold when not exist", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterThresholdBind{
+			Min: float(4),
+		}
+
+		// call mock
+		threshold := domain.WaterThreshold{
+			FarmID:    "farmID",
+			Parameter: domain.WaterParameterDissolvedOxygen,
+			Min:       request.Min,
+		}
+		memberMock := mockMember("farmID", domain.RoleManager)
+		findThresholdMock := waterQualityRepository.Mock.On("FindThresholdByCondition", &domain.WaterThreshold{}, "farm_id = ? AND pond_id IS NULL AND parameter = ?", "farmID", "dissolved_oxygen").Return(gorm.ErrRecordNotFound)
+		saveThresholdMock := waterQualityRepository.Mock.On("SaveThreshold", &threshold).Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.WaterThreshold)
+			arg.ID = "thresholdID"
+		})
+
+		// call usecase
+		successResponse, errorResponse := waterQualityUsecase.SetFarmThreshold(context.Background(), authUser, request, "farmID", "dissolved_oxygen")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, "thresholdID", successResponse.ID, "threshold id should be equal")
+		assert.Nil(t, successResponse.PondID, "pond id should be nil")
+
+		memberMock.Unset()
+		findThresholdMock.Unset()
+		saveThresholdMock.Unset()
+	})
+
+	t.Run("should return error when min is greater than max", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterThresholdBind{
+			Min: float(9),
+			Max: float(7),
+		}
+
+		// call usecase
+		_, errorResponse := waterQualityUsecase.SetFarmThreshold(context.Background(), authUser, request, "farmID", "ph")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidThreshold, errObject.Code, "code should be equal")
+	})
+
+	t.Run("should return error when parameter is unknown", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterThresholdBind{
+			Max: float(1),
+		}
+
+		// call usecase
+		_, errorResponse := waterQualityUsecase.SetFarmThreshold(context.Background(), authUser, request, "farmID", "turbidity")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidParameter, errObject.Code, "code should be equal")
+	})
+
+	t.Run("should return error when user is technician", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterThresholdBind{
+			Min: float(4),
+		}
+
+		// call mock
+		memberMock := mockMember("farmID", domain.RoleTechnician)
+
+		// call usecase
+		_, errorResponse := waterQualityUsecase.SetFarmThreshold(context.Background(), authUser, request, "farmID", "dissolved_oxygen")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindForbidden, errObject.Kind, "kind should be equal")
+
+		memberMock.Unset()
+	})
+}
+
+func TestSetPondThreshold(t *testing.T) {
+	t.Run("should update existing override", func(t *testing.T) {
+		// prepare usecase parameter
+		request := domain.WaterThresholdBind{
+			Min: float(7),
+			Max: float(9),
+		}
+
+		// call mock
+		pondId := "pondID"
+		threshold := domain.WaterThreshold{
+			ID:        "thresholdID",
+			FarmID:    "farmID",
+			PondID:    &pondId,
+			Parameter: domain.WaterParameterPH,
+			Min:       request.Min,
+			Max:       request.Max,
+		}
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleManager)
+		findThresholdMock := waterQualityRepository.Mock.On("FindThresholdByCondition", &domain.WaterThreshold{}, "pond_id = ? AND parameter = ?", "pondID", "ph").Return(nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*domain.WaterThreshold)
+			arg.ID = "thresholdID"
+			arg.FarmID = "farmID"
+			arg.PondID = &pondId
+			arg.Parameter = domain.WaterParameterPH
+			arg.Min = float(7.5)
+		})
+		saveThresholdMock := waterQualityRepository.Mock.On("SaveThreshold", &threshold).Return(nil)
+
+		// call usecase
+		successResponse, errorResponse := waterQualityUsecase.SetPondThreshold(context.Background(), authUser, request, "pondID", "ph")
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Equal(t, "thresholdID", successResponse.ID, "threshold id should be equal")
+		assert.Equal(t, float64(9), *successResponse.Max, "max should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findThresholdMock.Unset()
+		saveThresholdMock.Unset()
+	})
+}
+
+func TestDeletePondThreshold(t *testing.T) {
+	t.Run("should return error when threshold is not found", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleManager)
+		findThresholdMock := waterQualityRepository.Mock.On("FindThresholdByCondition", &domain.WaterThreshold{}, "pond_id = ? AND parameter = ?", "pondID", "ph").Return(gorm.ErrRecordNotFound)
+
+		// call usecase
+		errorResponse := waterQualityUsecase.DeletePondThreshold(context.Background(), authUser, "pondID", "ph")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeThresholdNotFound, errObject.Code, "code should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findThresholdMock.Unset()
+	})
+
+	t.Run("should return internal error when threshold lookup fails", func(t *testing.T) {
+		// call mock
+		pondMock, memberMock := mockPondAccess("pondID", domain.RoleManager)
+		findThresholdMock := waterQualityRepository.Mock.On("FindThresholdByCondition", &domain.WaterThreshold{}, "pond_id = ? AND parameter = ?", "pondID", "ph").Return(errors.New("connection refused"))
+
+		// call usecase
+		errorResponse := waterQualityUsecase.DeletePondThreshold(context.Background(), authUser, "pondID", "ph")
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindInternal, errObject.Kind, "kind should be equal")
+
+		pondMock.Unset()
+		memberMock.Unset()
+		findThresholdMock.Unset()
+	})
+}
+
+func TestGetAlerts(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		// prepare usecase parameter
+		query := domain.WaterAlertQuery{
+			PondID: "pondID",
+		}
+
+		// call mock
+		var alerts []domain.WaterAlert
+		findAlertsMock := waterQualityRepository.Mock.On("FindAlerts", &alerts, authUser.OrganizationID, authUser.ID, query).Return(domain.Pagination{Page: 1, PageSize: 20, Total: 1, TotalPages: 1}, nil).Run(func(args mock.Arguments) {
+			arg := args[0].(*[]domain.WaterAlert)
+			*arg = []domain.WaterAlert{{ID: "alertID", PondID: "pondID", Parameter: domain.WaterParameterPH, Value: 9}}
+		})
+
+		// call usecase
+		successResponse, pagination, errorResponse := waterQualityUsecase.GetAlerts(context.Background(), authUser, query)
+
+		//test response
+		assert.Nil(t, errorResponse, "error response should be nil")
+		assert.Len(t, successResponse, 1, "alerts length should be equal")
+		assert.Equal(t, int64(1), pagination.Total, "total should be equal")
+
+		findAlertsMock.Unset()
+	})
+
+	t.Run("should return error when alert is not found", func(t *testing.T) {
+		// call mock
+		var alerts []domain.WaterAlert
+		findAlertsMock := waterQualityRepository.Mock.On("FindAlerts", &alerts, authUser.OrganizationID, authUser.ID, domain.WaterAlertQuery{}).Return(domain.Pagination{}, nil)
+
+		// call usecase
+		_, _, errorResponse := waterQualityUsecase.GetAlerts(context.Background(), authUser, domain.WaterAlertQuery{})
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindNotFound, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeAlertNotFound, errObject.Code, "code should be equal")
+
+		findAlertsMock.Unset()
+	})
+
+	t.Run("should return error when sort is invalid", func(t *testing.T) {
+		// prepare usecase parameter
+		query := domain.WaterAlertQuery{
+			PageQuery: domain.PageQuery{Sort: "name"},
+		}
+
+		// call usecase
+		_, _, errorResponse := waterQualityUsecase.GetAlerts(context.Background(), authUser, query)
+
+		//test response
+		errObject := errorResponse.(*apperror.Error)
+		assert.Equal(t, apperror.KindValidation, errObject.Kind, "kind should be equal")
+		assert.Equal(t, apperror.CodeInvalidQuery, errObject.Code, "code should be equal")
+	})
+}
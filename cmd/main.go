@@ -1,62 +1,251 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
 	api_call_handler "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/handler"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/recorder"
 	api_call_repository "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/repository"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/retention"
 	api_call_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/usecase"
+	cycle_handler "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/handler"
+	cycle_repository "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/repository"
+	cycle_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/usecase"
 	farm_handler "github.com/reyhanmichiels/AquaFarmManagement/app/farm/handler"
 	farm_repository "github.com/reyhanmichiels/AquaFarmManagement/app/farm/repository"
 	farm_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/farm/usecase"
+	feeding_handler "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/handler"
+	feeding_repository "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/repository"
+	feeding_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/usecase"
 	pond_handler "github.com/reyhanmichiels/AquaFarmManagement/app/pond/handler"
 	pond_repository "github.com/reyhanmichiels/AquaFarmManagement/app/pond/repository"
 	pond_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/pond/usecase"
+	trash_handler "github.com/reyhanmichiels/AquaFarmManagement/app/trash/handler"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/trash/purge"
+	trash_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/trash/usecase"
+	user_handler "github.com/reyhanmichiels/AquaFarmManagement/app/user/handler"
+	user_repository "github.com/reyhanmichiels/AquaFarmManagement/app/user/repository"
+	user_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/user/usecase"
+	water_quality_handler "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/handler"
+	water_quality_repository "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/repository"
+	water_quality_usecase "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/usecase"
 	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/auth"
 	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/health"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/metrics"
 	"github.com/reyhanmichiels/AquaFarmManagement/rest"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	//load env
-	infrastructure.LoadEnv()
+	//load config
+	config, err := infrastructure.LoadConfig()
+	if err != nil {
+		log.Println("invalid config")
+		log.Fatal(err)
+	}
 
 	//create logger
-	infrastructure.CreateLogger()
+	infrastructure.CreateLogger(config.Log)
 
 	//connect to database
-	database.ConnectToDB()
+	db, err := database.ConnectToDB(config.Database)
+	if err != nil {
+		log.Println("can't connect to database")
+		log.Fatal(err)
+	}
+
+	//run migrate command instead of serving when requested
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(db, os.Args[2:])
+		return
+	}
+
+	//apply pending database migrations
+	err = database.MigrateUp(db)
+	if err != nil {
+		log.Println("can't migrate database")
+		log.Fatal(err)
+	}
 
-	//migrate database table
-	database.Migrate()
+	//init token manager
+	tokenManager, err := auth.NewJWTManager(config.Auth.JWTSecret, config.Auth.AccessTTL)
+	if err != nil {
+		log.Println("can't create token manager")
+		log.Fatal(err)
+	}
+
+	//init metrics
+	appMetrics, err := metrics.NewMetrics(db, metrics.Config{
+		CountTimeout: config.Metrics.CountTimeout,
+	})
+	if err != nil {
+		log.Println("can't create metrics")
+		log.Fatal(err)
+	}
 
 	//init repository
-	farmRepository := farm_repository.NewFarmRepository(database.DB)
-	pondRepository := pond_repository.NewPondRepository(database.DB)
-	apiCallRepository := api_call_repository.NewApiCallRepository(database.DB)
+	farmRepository := farm_repository.NewFarmRepository(db)
+	pondRepository := pond_repository.NewPondRepository(db)
+	apiCallRepository := api_call_repository.NewApiCallRepository(db)
+	userRepository := user_repository.NewUserRepository(db)
+	cycleRepository := cycle_repository.NewCycleRepository(db)
+	feedingRepository := feeding_repository.NewFeedingRepository(db)
+	waterQualityRepository := water_quality_repository.NewWaterQualityRepository(db)
+
+	//init api call recorder
+	apiCallRecorder := recorder.NewApiCallRecorder(apiCallRepository, recorder.Config{
+		BufferSize:     config.ApiCall.BufferSize,
+		BatchSize:      config.ApiCall.BatchSize,
+		FlushInterval:  config.ApiCall.FlushInterval,
+		EnqueueTimeout: config.ApiCall.EnqueueTimeout,
+		FlushTimeout:   config.ApiCall.FlushTimeout,
+	})
+
+	//init api call retention
+	apiCallRetention := retention.NewApiCallRetention(apiCallRepository, retention.Config{
+		Interval:  config.ApiCall.RollupInterval,
+		RawAge:    config.ApiCall.RawRetention,
+		HourlyAge: config.ApiCall.HourlyRetention,
+		Timeout:   config.ApiCall.RollupTimeout,
+	})
+
+	//init trash purge
+	trashPurge := purge.NewTrashPurge(farmRepository, pondRepository, purge.Config{
+		Interval:  config.Trash.PurgeInterval,
+		Retention: config.Trash.Retention,
+		Timeout:   config.Trash.PurgeTimeout,
+	})
+
+	//init health checker
+	healthChecker := health.NewHealthChecker(config.HTTP.HealthCheckTimeout,
+		health.Check{Name: "database", Required: true, Run: func(ctx context.Context) error {
+			return database.Ping(ctx, db)
+		}},
+		health.Check{Name: "migrations", Required: true, Run: func(ctx context.Context) error {
+			return database.CheckMigrations(ctx, db)
+		}},
+		health.Check{Name: "api_call_recorder", Run: func(ctx context.Context) error {
+			return apiCallRecorder.Health()
+		}},
+		health.Check{Name: "api_call_retention", Run: func(ctx context.Context) error {
+			return apiCallRetention.Health()
+		}},
+		health.Check{Name: "trash_purge", Run: func(ctx context.Context) error {
+			return trashPurge.Health()
+		}},
+	)
 
 	//init usecase
-	farmUsecase := farm_usecase.NewFarmUsecase(farmRepository)
+	farmUsecase := farm_usecase.NewFarmUsecase(farmRepository, userRepository)
 	pondUsecase := pond_usecase.NewPondUsecase(pondRepository, farmRepository)
-	apiCallUsecase := api_call_usecase.NewApiCallUsecase(apiCallRepository)
+	trashUsecase := trash_usecase.NewTrashUsecase(farmRepository, pondRepository)
+	cycleUsecase := cycle_usecase.NewCycleUsecase(cycleRepository, pondRepository, farmRepository)
+	feedingUsecase := feeding_usecase.NewFeedingUsecase(feedingRepository, cycleRepository, pondRepository, farmRepository)
+	waterQualityUsecase := water_quality_usecase.NewWaterQualityUsecase(waterQualityRepository, pondRepository, farmRepository)
+	apiCallUsecase := api_call_usecase.NewApiCallUsecase(apiCallRepository, apiCallRecorder)
+	userUsecase := user_usecase.NewUserUsecase(userRepository, tokenManager, config.Auth.RefreshTTL)
 
 	//init handler
 	farmHandler := farm_handler.NewFarmHandler(farmUsecase)
 	pondHandler := pond_handler.NewPondHandler(pondUsecase)
+	trashHandler := trash_handler.NewTrashHandler(trashUsecase)
+	cycleHandler := cycle_handler.NewCycleHandler(cycleUsecase)
+	feedingHandler := feeding_handler.NewFeedingHandler(feedingUsecase)
+	waterQualityHandler := water_quality_handler.NewWaterQualityHandler(waterQualityUsecase)
 	apiCallHandler := api_call_handler.NewApiCallHandler(apiCallUsecase)
+	userHandler := user_handler.NewUserHandler(userUsecase)
 
 	//init rest
-	rest := rest.NewRest(gin.New())
+	rest := rest.NewRest(gin.New(), tokenManager, apiCallRecorder, appMetrics, rest.Config{
+		Addr:              config.HTTP.Addr,
+		ReadTimeout:       config.HTTP.ReadTimeout,
+		ReadHeaderTimeout: config.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      config.HTTP.WriteTimeout,
+		IdleTimeout:       config.HTTP.IdleTimeout,
+		MaxHeaderBytes:    config.HTTP.MaxHeaderBytes,
+		RequestTimeout:    config.HTTP.RequestTimeout,
+		Operators:         strings.Split(config.ApiCall.Operators, ","),
+		MetricsAddr:       config.Metrics.Addr,
+	})
 
 	//use middleware
 	rest.UseGlobalMiddleware()
 
 	//load route
-	rest.HealthCheckRoute()
+	rest.HealthRoute(healthChecker)
+	rest.MetricsRoute()
+	rest.AuthRoute(userHandler)
+	rest.OrganizationRoute(userHandler)
 	rest.FarmRoute(farmHandler)
 	rest.PondRoute(pondHandler)
+	rest.TrashRoute(trashHandler)
+	rest.CycleRoute(cycleHandler)
+	rest.FeedingRoute(feedingHandler)
+	rest.WaterQualityRoute(waterQualityHandler)
 	rest.ApiCallRoute(apiCallHandler)
 
-	//serve app
-	rest.Serve()
+	//serve app until SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- rest.Serve()
+	}()
+
+	select {
+	case err = <-serveErr:
+		if err != nil {
+			log.Println("can't serve app")
+			log.Println(err)
+		}
+	case <-ctx.Done():
+		stop()
+	}
+
+	//drain in-flight requests, then flush background workers and close the database
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := rest.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		log.Println("can't drain in-flight requests")
+		log.Println(shutdownErr)
+	}
+
+	shutdownErr = trashPurge.Close(shutdownCtx)
+	if shutdownErr != nil {
+		log.Println("can't stop trash purge")
+		log.Println(shutdownErr)
+	}
+
+	shutdownErr = apiCallRetention.Close(shutdownCtx)
+	if shutdownErr != nil {
+		log.Println("can't stop api call retention")
+		log.Println(shutdownErr)
+	}
+
+	shutdownErr = apiCallRecorder.Close(shutdownCtx)
+	if shutdownErr != nil {
+		log.Println("can't flush api calls")
+		log.Println(shutdownErr)
+	}
+
+	shutdownErr = database.Close(db)
+	if shutdownErr != nil {
+		log.Println("can't close database")
+		log.Println(shutdownErr)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: app migrate up | down [steps] | status"
+
+// runMigrateCommand handles `app migrate up|down [steps]|status`.
+func runMigrateCommand(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		err := database.MigrateUp(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("all migrations applied")
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal(migrateUsage)
+			}
+			steps = n
+		}
+
+		err := database.MigrateDown(db, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("reverted up to %d migration(s)\n", steps)
+
+	case "status":
+		statuses, err := database.GetMigrationStatus(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = fmt.Sprintf("applied at %s", status.AppliedAt.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
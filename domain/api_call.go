@@ -4,17 +4,111 @@ import (
 	"time"
 )
 
-// Model for Api Call entity
+const (
+	ApiCallGroupByRoute = "route"
+	ApiCallGroupByPath  = "path"
+
+	// Key of the calls that matched no route when grouping by route
+	ApiCallUnmatched = "unmatched"
+
+	ApiCallRollupHour = "hour"
+	ApiCallRollupDay  = "day"
+)
+
+// Model for Api Call entity, Endpoint is the raw path and Route the matched
+// route template, empty when no route matched
 type ApiCall struct {
-	Endpoint  string    `json:"endpoint" gorm:"type:varchar(100); not null;"`
-	Method    string    `json:"method" gorm:"type:varchar(20); not null"`
-	IpAdress  string    `json:"ip_adress" gorm:"type:varchar(100); not null;"`
-	CreatedAt time.Time `json:"created_at"`
+	Endpoint     string    `json:"endpoint" gorm:"type:varchar(100); not null;"`
+	Route        string    `json:"route" gorm:"type:varchar(255); not null"`
+	Method       string    `json:"method" gorm:"type:varchar(20); not null"`
+	IpAdress     string    `json:"ip_adress" gorm:"type:varchar(100); not null;"`
+	StatusCode   int       `json:"status_code" gorm:"not null"`
+	Latency      float64   `json:"latency_ms" gorm:"type:numeric(12,3); not null"`
+	ResponseSize int       `json:"response_size" gorm:"not null"`
+	UserAgent    string    `json:"user_agent" gorm:"type:varchar(255); not null"`
+	UserID       *string   `json:"user_id" gorm:"type:uuid"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Model for Api Call Rollup entity, the count of the api calls sharing a route,
+// path, ip and status in the hour or day starting at BucketStart. Raw calls are
+// rolled up once they are older than the retention window.
+type ApiCallRollup struct {
+	Granularity string    `json:"granularity" gorm:"type:varchar(10); not null"`
+	BucketStart time.Time `json:"bucket_start" gorm:"not null"`
+	Endpoint    string    `json:"endpoint" gorm:"type:varchar(100); not null"`
+	Route       string    `json:"route" gorm:"type:varchar(255); not null"`
+	Method      string    `json:"method" gorm:"type:varchar(20); not null"`
+	IpAdress    string    `json:"ip_adress" gorm:"type:varchar(100); not null"`
+	StatusCode  int       `json:"status_code" gorm:"not null"`
+	Count       int       `json:"count" gorm:"not null"`
+}
+
+// Filters of the api call stats. Endpoint matches a route template or a raw
+// path, From and To are inclusive RFC3339 timestamps. Bucket switches from
+// totals to a time series of counts.
+type ApiCallQuery struct {
+	GroupBy  string    `form:"group_by" binding:"omitempty,oneof=route path"`
+	From     time.Time `form:"from"`
+	To       time.Time `form:"to"`
+	Method   string    `form:"method" binding:"omitempty,max=20"`
+	Endpoint string    `form:"endpoint" binding:"omitempty,max=255"`
+	Ip       string    `form:"ip" binding:"omitempty,max=100"`
+	Bucket   string    `form:"bucket" binding:"omitempty,oneof=minute hour day"`
 }
 
+// Aggregate of the api calls of one endpoint and method. Calls recorded before
+// the outcome was tracked have no status and only add to Count. RawFrom is the
+// earliest call not rolled up yet, nil once every call is rolled up.
 type ApiCallResponse struct {
-	Endpoint        string `json:"endpoint"`
-	Method          string `json:"method"`
-	Count           int    `json:"count"`
-	UniqueUserAgent int    `json:"unique_user_agent"`
+	Endpoint         string     `json:"endpoint"`
+	Method           string     `json:"method"`
+	Count            int        `json:"count"`
+	RecordedCount    int        `json:"recorded_count"`
+	UniqueUserAgent  int        `json:"unique_user_agent"`
+	UniqueIp         int        `json:"unique_ip"`
+	ClientErrorCount int        `json:"client_error_count"`
+	ServerErrorCount int        `json:"server_error_count"`
+	LatencyP50       float64    `json:"latency_p50"`
+	LatencyP95       float64    `json:"latency_p95"`
+	LatencyP99       float64    `json:"latency_p99"`
+	RawFrom          *time.Time `json:"raw_from" gorm:"-"`
+}
+
+// Count of the api calls of one endpoint and method in the bucket starting at BucketStart
+type ApiCallBucket struct {
+	Method      string
+	Endpoint    string
+	BucketStart time.Time
+	Count       int
+}
+
+// One point of the call series of an endpoint, keyed by the start of its bucket
+type ApiCallPoint struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// Stats of one endpoint and method, rates are fractions of the calls with a
+// status. Rollups keep no user agent or latency, so UniqueUserAgent and the
+// percentiles only cover the calls from RawFrom on.
+type ApiCallStats struct {
+	Count           int        `json:"count"`
+	UniqueUserAgent int        `json:"unique_user_agent"`
+	UniqueIp        int        `json:"unique_ip"`
+	ClientErrorRate float64    `json:"client_error_rate"`
+	ServerErrorRate float64    `json:"server_error_rate"`
+	LatencyP50      float64    `json:"latency_p50_ms"`
+	LatencyP95      float64    `json:"latency_p95_ms"`
+	LatencyP99      float64    `json:"latency_p99_ms"`
+	RawFrom         *time.Time `json:"raw_from"`
+}
+
+// Counters of the api call recorder since start
+type ApiCallRecorderStats struct {
+	Enqueued uint64 `json:"enqueued"`
+	Dropped  uint64 `json:"dropped"`
+	Flushed  uint64 `json:"flushed"`
+	Failed   uint64 `json:"failed"`
+	Pending  int    `json:"pending"`
 }
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CycleStatus string
+
+const (
+	CycleStatusActive CycleStatus = "active"
+	CycleStatusClosed CycleStatus = "closed"
+)
+
+// Returned by the cycle repository when the cycle is no longer active
+var ErrCycleClosed = errors.New("cycle is already closed")
+
+// Model for Cycle entity, one stocking of a pond until harvest
+type Cycle struct {
+	ID             string      `json:"id" gorm:"type:uuid; not null; primary key"`
+	PondID         string      `json:"pond_id" gorm:"type:uuid; not null; index"`
+	Pond           Pond        `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Species        string      `json:"species" gorm:"type:varchar(100); not null"`
+	StockedAt      time.Time   `json:"stocked_at" gorm:"not null"`
+	StockCount     int64       `json:"stock_count" gorm:"not null"`
+	AverageWeight  float64     `json:"average_weight_g" gorm:"type:numeric(10,4); not null"`
+	Status         CycleStatus `json:"status" gorm:"type:varchar(20); not null"`
+	HarvestedAt    *time.Time  `json:"harvested_at"`
+	HarvestCount   int64       `json:"harvest_count" gorm:"not null"`
+	HarvestBiomass float64     `json:"harvest_biomass_kg" gorm:"type:numeric(14,4); not null"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// Automate generate uuid when create cycle
+func (cycle *Cycle) BeforeCreate(tx *gorm.DB) error {
+	cycle.ID = uuid.NewString()
+	return nil
+}
+
+type CycleBind struct {
+	Species       string    `json:"species" binding:"required,max=100"`
+	StockedAt     time.Time `json:"stocked_at" binding:"required"`
+	StockCount    int64     `json:"stock_count" binding:"required,gt=0"`
+	AverageWeight float64   `json:"average_weight_g" binding:"required,gt=0"`
+}
+
+// Harvest closing a cycle, a lost crop is closed with zero count and biomass
+type CloseCycleBind struct {
+	HarvestedAt    time.Time `json:"harvested_at" binding:"required"`
+	HarvestCount   int64     `json:"harvest_count" binding:"gte=0"`
+	HarvestBiomass float64   `json:"harvest_biomass_kg" binding:"gte=0"`
+}
@@ -9,28 +9,49 @@ import (
 
 // Model for Farm entity
 type Farm struct {
-	ID        string         `json:"id" gorm:"type:uuid; not null; primary key"`
-	Ponds     []Pond         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Name      string         `json:"name" gorm:"type:varchar(100); not null; unique"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+	ID             string         `json:"id" gorm:"type:uuid; not null; primary key"`
+	OrganizationID string         `json:"organization_id" gorm:"type:uuid; not null; index"`
+	Organization   Organization   `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Ponds          []Pond         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name           string         `json:"name" gorm:"type:varchar(100); not null"`
+	Version        int64          `json:"version" gorm:"not null; default:1"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at"`
 }
 
 // Automate generate uuid when create farm
 func (farm *Farm) BeforeCreate(tx *gorm.DB) error {
 	farm.ID = uuid.NewString()
+	farm.Version = 1
 	return nil
 }
 
+// Sort key of farm for keyset pagination
+func (farm Farm) CursorFor(field string) Cursor {
+	return Cursor{
+		Value: sortValue(field, farm.Name, farm.CreatedAt, farm.UpdatedAt, farm.DeletedAt.Time),
+		ID:    farm.ID,
+	}
+}
+
 type FarmBind struct {
 	Name string `json:"name" binding:"required,max=100,min=4"`
 }
 
 type FarmApi struct {
-	ID        string    `json:"id"`
-	Ponds     []Pond    `json:"ponds" gorm:"foreignKey:FarmID"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Ponds          []Pond    `json:"ponds" gorm:"foreignKey:FarmID"`
+	Name           string    `json:"name"`
+	SurfaceArea    float64   `json:"total_surface_area_m2" gorm:"-"`
+	Volume         float64   `json:"total_volume_m3" gorm:"-"`
+	Version        int64     `json:"version"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type FarmQuery struct {
+	PageQuery
+	ListFilter
 }
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+type Role string
+
+const (
+	RoleManager    Role = "manager"
+	RoleTechnician Role = "technician"
+	RoleAuditor    Role = "auditor"
+)
+
+type Permission string
+
+const (
+	// read farms, ponds and their records
+	PermissionView Permission = "view"
+	// record operational data such as stocking, feeding and water quality
+	PermissionLogData Permission = "log_data"
+	// change farms and ponds, their members and thresholds
+	PermissionManage Permission = "manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleManager:    {PermissionView, PermissionLogData, PermissionManage},
+	RoleTechnician: {PermissionView, PermissionLogData},
+	RoleAuditor:    {PermissionView},
+}
+
+func (role Role) Can(permission Permission) bool {
+	for _, v := range rolePermissions[role] {
+		if v == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Model for Farm Member entity linking a user to a farm with a role
+type FarmMember struct {
+	FarmID    string    `json:"farm_id" gorm:"type:uuid; not null; primaryKey"`
+	Farm      Farm      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    string    `json:"user_id" gorm:"type:uuid; not null; primaryKey; index"`
+	User      User      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role      Role      `json:"role" gorm:"type:varchar(20); not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FarmMemberBind struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  Role   `json:"role" binding:"required,oneof=manager technician auditor"`
+}
+
+type FarmMemberRoleBind struct {
+	Role Role `json:"role" binding:"required,oneof=manager technician auditor"`
+}
+
+type FarmMemberApi struct {
+	FarmID    string    `json:"farm_id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FeedingSession string
+
+const (
+	FeedingSessionMorning   FeedingSession = "morning"
+	FeedingSessionMidday    FeedingSession = "midday"
+	FeedingSessionAfternoon FeedingSession = "afternoon"
+	FeedingSessionEvening   FeedingSession = "evening"
+	FeedingSessionNight     FeedingSession = "night"
+)
+
+// Model for Feeding entity, feed given to a pond during a cycle
+type Feeding struct {
+	ID        string         `json:"id" gorm:"type:uuid; not null; primary key"`
+	CycleID   string         `json:"cycle_id" gorm:"type:uuid; not null; index"`
+	Cycle     Cycle          `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FedAt     time.Time      `json:"fed_at" gorm:"not null"`
+	FeedType  string         `json:"feed_type" gorm:"type:varchar(100); not null"`
+	Quantity  float64        `json:"quantity_kg" gorm:"type:numeric(10,4); not null"`
+	Session   FeedingSession `json:"session" gorm:"type:varchar(20); not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Automate generate uuid when create feeding
+func (feeding *Feeding) BeforeCreate(tx *gorm.DB) error {
+	feeding.ID = uuid.NewString()
+	return nil
+}
+
+type FeedingBind struct {
+	FedAt    time.Time      `json:"fed_at" binding:"required"`
+	FeedType string         `json:"feed_type" binding:"required,max=100"`
+	Quantity float64        `json:"quantity_kg" binding:"required,gt=0"`
+	Session  FeedingSession `json:"session" binding:"required,oneof=morning midday afternoon evening night"`
+}
+
+// Empty cycle id means the active cycle of the pond
+type FeedingQuery struct {
+	CycleID string `form:"cycle_id"`
+}
+
+type FeedingTotal struct {
+	TotalFeed    float64
+	FeedingCount int64
+}
+
+// Feed conversion of a cycle. No biomass estimate is recorded between stocking
+// and harvest, so HarvestBiomass and FCR stay null while the cycle is active.
+type FeedingSummary struct {
+	CycleID        string      `json:"cycle_id"`
+	Status         CycleStatus `json:"status"`
+	FeedingCount   int64       `json:"feeding_count"`
+	TotalFeed      float64     `json:"total_feed_kg"`
+	InitialBiomass float64     `json:"initial_biomass_kg"`
+	HarvestBiomass *float64    `json:"harvest_biomass_kg"`
+	FCR            *float64    `json:"fcr"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role of a user within its organization
+type OrganizationRole string
+
+const (
+	// manages the accounts of the organization
+	OrganizationRoleAdmin  OrganizationRole = "admin"
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// Model for Organization entity, the tenant owning users and farms
+type Organization struct {
+	ID        string         `json:"id" gorm:"type:uuid; not null; primary key"`
+	Name      string         `json:"name" gorm:"type:varchar(100); not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+// Automate generate uuid when create organization
+func (organization *Organization) BeforeCreate(tx *gorm.DB) error {
+	organization.ID = uuid.NewString()
+	return nil
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Sortable columns shared by farm and pond listings
+var ListSortFields = []string{"name", "created_at", "updated_at"}
+
+// Query string for paginated listing. When Cursor is set, Page is ignored and
+// keyset pagination continues after the row the cursor points to.
+type PageQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor   string `form:"cursor"`
+	Sort     string `form:"sort"`
+}
+
+// Filters shared by farm and pond listings. Name matches as a case-insensitive
+// prefix and the date ranges are inclusive RFC3339 timestamps.
+type ListFilter struct {
+	Name        string    `form:"name" binding:"omitempty,max=100"`
+	CreatedFrom time.Time `form:"created_from"`
+	CreatedTo   time.Time `form:"created_to"`
+	UpdatedFrom time.Time `form:"updated_from"`
+	UpdatedTo   time.Time `form:"updated_to"`
+}
+
+// Column and direction parsed from a sort parameter such as "-created_at"
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Position of the last row of a page, encoded opaquely in next_cursor
+type Cursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+func sortValue(field string, name string, createdAt time.Time, updatedAt time.Time, deletedAt time.Time) string {
+	switch field {
+	case "name":
+		return name
+	case "updated_at":
+		return updatedAt.UTC().Format(time.RFC3339Nano)
+	case "deleted_at":
+		return deletedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return createdAt.UTC().Format(time.RFC3339Nano)
+	}
+}
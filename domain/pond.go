@@ -7,33 +7,88 @@ import (
 	"gorm.io/gorm"
 )
 
-// Model for Pond entity
+type PondType string
+
+const (
+	PondTypeEarthen  PondType = "earthen"
+	PondTypeLined    PondType = "lined"
+	PondTypeConcrete PondType = "concrete"
+	PondTypeTank     PondType = "tank"
+	PondTypeRAS      PondType = "ras"
+	PondTypeCage     PondType = "cage"
+)
+
+type PondShape string
+
+const (
+	PondShapeRectangular PondShape = "rectangular"
+	PondShapeSquare      PondShape = "square"
+	PondShapeCircular    PondShape = "circular"
+	PondShapeOval        PondShape = "oval"
+	PondShapeIrregular   PondShape = "irregular"
+)
+
+// Model for Pond entity, sizes are stored in square meters and meters
 type Pond struct {
-	ID        string         `json:"id" gorm:"type:uuid;not null; primary key"`
-	FarmID    string         `json:"farm_id" gorm:"type:uuid;not null"`
-	Farm      Farm           `json:"-"`
-	Name      string         `json:"name" gorm:"type:varchar(100); not null; unique"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+	ID           string         `json:"id" gorm:"type:uuid;not null; primary key"`
+	FarmID       string         `json:"farm_id" gorm:"type:uuid;not null"`
+	Farm         Farm           `json:"-"`
+	Name         string         `json:"name" gorm:"type:varchar(100); not null"`
+	Type         PondType       `json:"type" gorm:"type:varchar(20); not null"`
+	Shape        PondShape      `json:"shape" gorm:"type:varchar(20); not null"`
+	SurfaceArea  float64        `json:"surface_area_m2" gorm:"type:numeric(14,4); not null"`
+	AverageDepth float64        `json:"average_depth_m" gorm:"type:numeric(8,4); not null"`
+	Volume       float64        `json:"volume_m3" gorm:"type:numeric(16,4); not null"`
+	Version      int64          `json:"version" gorm:"not null; default:1"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at"`
 }
 
 // Automate generate uuid when create farm
 func (pond *Pond) BeforeCreate(tx *gorm.DB) error {
 	pond.ID = uuid.NewString()
+	pond.Version = 1
 	return nil
 }
 
+// Sort key of pond for keyset pagination
+func (pond Pond) CursorFor(field string) Cursor {
+	return Cursor{
+		Value: sortValue(field, pond.Name, pond.CreatedAt, pond.UpdatedAt, pond.DeletedAt.Time),
+		ID:    pond.ID,
+	}
+}
+
+// Sizes are optional, units default to square meters and meters
 type PondBind struct {
-	Name   string `json:"name" binding:"required,max=100,min=4"`
-	FarmID string `json:"farm_id" binding:"required"`
+	Name            string    `json:"name" binding:"required,max=100,min=4"`
+	FarmID          string    `json:"farm_id" binding:"required"`
+	Type            PondType  `json:"type" binding:"omitempty,oneof=earthen lined concrete tank ras cage"`
+	Shape           PondShape `json:"shape" binding:"omitempty,oneof=rectangular square circular oval irregular"`
+	SurfaceArea     float64   `json:"surface_area" binding:"omitempty,gt=0"`
+	SurfaceAreaUnit string    `json:"surface_area_unit" binding:"omitempty,oneof=m2 ha ft2 acre"`
+	AverageDepth    float64   `json:"average_depth" binding:"omitempty,gt=0"`
+	DepthUnit       string    `json:"depth_unit" binding:"omitempty,oneof=m cm ft"`
 }
 
 type PondApi struct {
-	ID        string    `json:"id"`
-	FarmID    string    `json:"farm_id"`
-	Farm      Farm      `json:"farm"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	FarmID       string    `json:"farm_id"`
+	Farm         Farm      `json:"farm"`
+	Name         string    `json:"name"`
+	Type         PondType  `json:"type"`
+	Shape        PondShape `json:"shape"`
+	SurfaceArea  float64   `json:"surface_area_m2"`
+	AverageDepth float64   `json:"average_depth_m"`
+	Volume       float64   `json:"volume_m3"`
+	Version      int64     `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type PondQuery struct {
+	PageQuery
+	ListFilter
+	FarmID string `form:"farm_id"`
 }
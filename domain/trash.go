@@ -0,0 +1,10 @@
+package domain
+
+// Sortable columns of the trash listings
+var TrashSortFields = []string{"name", "created_at", "updated_at", "deleted_at"}
+
+// Query string of the trash listings, sorted from the latest deleted when no
+// sort is given
+type TrashQuery struct {
+	PageQuery
+}
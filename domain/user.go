@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Model for User entity
+type User struct {
+	ID             string           `json:"id" gorm:"type:uuid; not null; primary key"`
+	OrganizationID string           `json:"organization_id" gorm:"type:uuid; not null; index"`
+	Organization   Organization     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Role           OrganizationRole `json:"role" gorm:"type:varchar(20); not null; default:member"`
+	Name           string           `json:"name" gorm:"type:varchar(100); not null"`
+	Email          string           `json:"email" gorm:"type:varchar(255); not null; unique"`
+	Password       string           `json:"-" gorm:"type:varchar(255); not null"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt   `json:"-"`
+}
+
+// Automate generate uuid when create user
+func (user *User) BeforeCreate(tx *gorm.DB) error {
+	user.ID = uuid.NewString()
+	return nil
+}
+
+// Model for Refresh Token entity, only the sha256 hash of the token is stored
+type RefreshToken struct {
+	ID        string     `json:"id" gorm:"type:uuid; not null; primary key"`
+	UserID    string     `json:"user_id" gorm:"type:uuid; not null; index"`
+	User      User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64); not null; unique"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Automate generate uuid when create refresh token
+func (refreshToken *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	refreshToken.ID = uuid.NewString()
+	return nil
+}
+
+// User resolved from a valid access token
+type AuthUser struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Email          string `json:"email"`
+}
+
+type RegisterBind struct {
+	Name             string `json:"name" binding:"required,max=100,min=4"`
+	Email            string `json:"email" binding:"required,email,max=255"`
+	Password         string `json:"password" binding:"required,min=8"`
+	OrganizationName string `json:"organization_name" binding:"required,max=100,min=2"`
+}
+
+// Account created by a user for a colleague of the same organization
+type OrganizationUserBind struct {
+	Name     string `json:"name" binding:"required,max=100,min=4"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type LoginBind struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokenBind struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenApi struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Returned by repositories when a record changed since it was read
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// Strong entity tag of the farm as returned by id, it changes with the farm
+// and with any of its ponds
+func (farm FarmApi) ETag() string {
+	ponds := make([]string, 0, len(farm.Ponds))
+	for _, pond := range farm.Ponds {
+		ponds = append(ponds, pond.ID+":"+strconv.FormatInt(pond.Version, 10))
+	}
+	sort.Strings(ponds)
+
+	return entityTag(append([]string{farm.ID + ":" + strconv.FormatInt(farm.Version, 10)}, ponds...))
+}
+
+// Strong entity tag of the pond as returned by id, it changes with the pond
+// and with the farm embedded in it
+func (pond PondApi) ETag() string {
+	return entityTag([]string{
+		pond.ID + ":" + strconv.FormatInt(pond.Version, 10),
+		pond.Farm.ID + ":" + strconv.FormatInt(pond.Farm.Version, 10),
+	})
+}
+
+func entityTag(parts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, ",")))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaterParameter string
+
+const (
+	WaterParameterDissolvedOxygen WaterParameter = "dissolved_oxygen"
+	WaterParameterPH              WaterParameter = "ph"
+	WaterParameterTemperature     WaterParameter = "temperature"
+	WaterParameterAmmonia         WaterParameter = "ammonia"
+	WaterParameterNitrite         WaterParameter = "nitrite"
+	WaterParameterSalinity        WaterParameter = "salinity"
+)
+
+var WaterParameters = []WaterParameter{
+	WaterParameterDissolvedOxygen,
+	WaterParameterPH,
+	WaterParameterTemperature,
+	WaterParameterAmmonia,
+	WaterParameterNitrite,
+	WaterParameterSalinity,
+}
+
+// Sortable columns of reading and alert listings
+var (
+	WaterReadingSortFields = []string{"measured_at", "created_at"}
+	WaterAlertSortFields   = []string{"created_at"}
+)
+
+// Model for WaterReading entity, parameters not measured are left empty
+type WaterReading struct {
+	ID              string       `json:"id" gorm:"type:uuid; not null; primary key"`
+	PondID          string       `json:"pond_id" gorm:"type:uuid; not null; index"`
+	Pond            Pond         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MeasuredAt      time.Time    `json:"measured_at" gorm:"not null"`
+	DissolvedOxygen *float64     `json:"dissolved_oxygen_mg_l" gorm:"type:numeric(8,4)"`
+	PH              *float64     `json:"ph" gorm:"type:numeric(8,4)"`
+	Temperature     *float64     `json:"temperature_c" gorm:"type:numeric(8,4)"`
+	Ammonia         *float64     `json:"ammonia_mg_l" gorm:"type:numeric(8,4)"`
+	Nitrite         *float64     `json:"nitrite_mg_l" gorm:"type:numeric(8,4)"`
+	Salinity        *float64     `json:"salinity_ppt" gorm:"type:numeric(8,4)"`
+	Alerts          []WaterAlert `json:"alerts,omitempty" gorm:"foreignKey:ReadingID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// Automate generate uuid when create water reading
+func (reading *WaterReading) BeforeCreate(tx *gorm.DB) error {
+	reading.ID = uuid.NewString()
+	return nil
+}
+
+func (reading WaterReading) CursorFor(field string) Cursor {
+	value := reading.CreatedAt
+	if field == "measured_at" {
+		value = reading.MeasuredAt
+	}
+
+	return Cursor{
+		Value: value.UTC().Format(time.RFC3339Nano),
+		ID:    reading.ID,
+	}
+}
+
+// Measured values of the reading by parameter
+func (reading WaterReading) Values() map[WaterParameter]*float64 {
+	return map[WaterParameter]*float64{
+		WaterParameterDissolvedOxygen: reading.DissolvedOxygen,
+		WaterParameterPH:              reading.PH,
+		WaterParameterTemperature:     reading.Temperature,
+		WaterParameterAmmonia:         reading.Ammonia,
+		WaterParameterNitrite:         reading.Nitrite,
+		WaterParameterSalinity:        reading.Salinity,
+	}
+}
+
+type WaterReadingBind struct {
+	MeasuredAt      time.Time `json:"measured_at" binding:"required"`
+	DissolvedOxygen *float64  `json:"dissolved_oxygen_mg_l" binding:"omitempty,gte=0"`
+	PH              *float64  `json:"ph" binding:"omitempty,gte=0,lte=14"`
+	Temperature     *float64  `json:"temperature_c"`
+	Ammonia         *float64  `json:"ammonia_mg_l" binding:"omitempty,gte=0"`
+	Nitrite         *float64  `json:"nitrite_mg_l" binding:"omitempty,gte=0"`
+	Salinity        *float64  `json:"salinity_ppt" binding:"omitempty,gte=0"`
+}
+
+type WaterReadingQuery struct {
+	PageQuery
+	MeasuredFrom time.Time `form:"measured_from"`
+	MeasuredTo   time.Time `form:"measured_to"`
+}
+
+// Model for WaterThreshold entity, a threshold without pond applies to every
+// pond of the farm and a pond threshold overrides it
+type WaterThreshold struct {
+	ID        string         `json:"id" gorm:"type:uuid; not null; primary key"`
+	FarmID    string         `json:"farm_id" gorm:"type:uuid; not null; index"`
+	Farm      Farm           `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PondID    *string        `json:"pond_id" gorm:"type:uuid; index"`
+	Pond      *Pond          `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Parameter WaterParameter `json:"parameter" gorm:"type:varchar(30); not null"`
+	Min       *float64       `json:"min" gorm:"type:numeric(8,4)"`
+	Max       *float64       `json:"max" gorm:"type:numeric(8,4)"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Automate generate uuid when create water threshold
+func (threshold *WaterThreshold) BeforeCreate(tx *gorm.DB) error {
+	threshold.ID = uuid.NewString()
+	return nil
+}
+
+// Check if value is out of the threshold range
+func (threshold WaterThreshold) Violates(value float64) bool {
+	if threshold.Min != nil && value < *threshold.Min {
+		return true
+	}
+
+	return threshold.Max != nil && value > *threshold.Max
+}
+
+// Empty bound means the parameter is unbounded on that side
+type WaterThresholdBind struct {
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+}
+
+// Model for WaterAlert entity, a reading value out of its threshold
+type WaterAlert struct {
+	ID        string         `json:"id" gorm:"type:uuid; not null; primary key"`
+	ReadingID string         `json:"reading_id" gorm:"type:uuid; not null; index"`
+	FarmID    string         `json:"farm_id" gorm:"type:uuid; not null; index"`
+	PondID    string         `json:"pond_id" gorm:"type:uuid; not null; index"`
+	Parameter WaterParameter `json:"parameter" gorm:"type:varchar(30); not null"`
+	Value     float64        `json:"value" gorm:"type:numeric(8,4); not null"`
+	Min       *float64       `json:"min" gorm:"type:numeric(8,4)"`
+	Max       *float64       `json:"max" gorm:"type:numeric(8,4)"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Automate generate uuid when create water alert
+func (alert *WaterAlert) BeforeCreate(tx *gorm.DB) error {
+	alert.ID = uuid.NewString()
+	return nil
+}
+
+func (alert WaterAlert) CursorFor(field string) Cursor {
+	return Cursor{
+		Value: alert.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:    alert.ID,
+	}
+}
+
+type WaterAlertQuery struct {
+	PageQuery
+	FarmID    string         `form:"farm_id"`
+	PondID    string         `form:"pond_id"`
+	Parameter WaterParameter `form:"parameter" binding:"omitempty,oneof=dissolved_oxygen ph temperature ammonia nitrite salinity"`
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+)
+
+const issuer = "aqua-farm-management"
+
+type ITokenManager interface {
+	GenerateAccessToken(user domain.User) (string, time.Time, error)
+	ParseAccessToken(token string) (domain.AuthUser, error)
+}
+
+type accessClaims struct {
+	OrganizationID string `json:"org"`
+	Email          string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+type JWTManager struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+func NewJWTManager(secret string, accessTTL time.Duration) (ITokenManager, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("jwt secret must be at least 32 characters")
+	}
+
+	return &JWTManager{
+		secret:    []byte(secret),
+		accessTTL: accessTTL,
+	}, nil
+}
+
+func (jwtManager *JWTManager) GenerateAccessToken(user domain.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(jwtManager.accessTTL)
+
+	claims := accessClaims{
+		OrganizationID: user.OrganizationID,
+		Email:          user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtManager.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+func (jwtManager *JWTManager) ParseAccessToken(token string) (domain.AuthUser, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		return jwtManager.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return domain.AuthUser{}, err
+	}
+
+	if claims.Subject == "" {
+		return domain.AuthUser{}, errors.New("token has no subject")
+	}
+
+	if claims.OrganizationID == "" {
+		return domain.AuthUser{}, errors.New("token has no organization")
+	}
+
+	return domain.AuthUser{
+		ID:             claims.Subject,
+		OrganizationID: claims.OrganizationID,
+		Email:          claims.Email,
+	}, nil
+}
@@ -1,15 +1,304 @@
 package infrastructure
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
-func LoadEnv() {
+// Config is the whole app configuration. Fields are filled from defaults, then
+// the YAML file named by CONFIG_FILE, then the environment variable in their
+// env tag, each overriding the one before.
+type Config struct {
+	HTTP     HTTPConfig     `yaml:"http"`
+	Database DatabaseConfig `yaml:"database"`
+	Log      LogConfig      `yaml:"log"`
+	Auth     AuthConfig     `yaml:"auth"`
+	ApiCall  ApiCallConfig  `yaml:"api_call"`
+	Trash    TrashConfig    `yaml:"trash"`
+	Metrics  MetricsConfig  `yaml:"metrics"`
+}
+
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"HTTP_ADDR"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	// zero leaves requests without a deadline
+	RequestTimeout  time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// longest time a readiness check may take before it counts as failed
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+type DatabaseConfig struct {
+	// "postgres" or "sqlite"
+	Driver string `yaml:"driver" env:"DB_DRIVER"`
+	// database file of the sqlite driver, ":memory:" keeps it in memory
+	Path               string        `yaml:"path" env:"DB_PATH"`
+	Host               string        `yaml:"host" env:"DB_HOST"`
+	Port               int           `yaml:"port" env:"DB_PORT"`
+	User               string        `yaml:"user" env:"DB_USER"`
+	Password           string        `yaml:"password" env:"DB_PASS"`
+	Name               string        `yaml:"name" env:"DB_NAME"`
+	SSLMode            string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	TimeZone           string        `yaml:"timezone" env:"DB_TIMEZONE"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+}
+
+type LogConfig struct {
+	// a logrus level such as "debug", "info" or "warn"
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// "json" or "text"
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type AuthConfig struct {
+	JWTSecret  string        `yaml:"jwt_secret" env:"JWT_SECRET"`
+	AccessTTL  time.Duration `yaml:"access_ttl" env:"JWT_ACCESS_TTL"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env:"JWT_REFRESH_TTL"`
+}
+
+type ApiCallConfig struct {
+	BufferSize      int           `yaml:"buffer_size" env:"API_CALL_BUFFER_SIZE"`
+	BatchSize       int           `yaml:"batch_size" env:"API_CALL_BATCH_SIZE"`
+	FlushInterval   time.Duration `yaml:"flush_interval" env:"API_CALL_FLUSH_INTERVAL"`
+	EnqueueTimeout  time.Duration `yaml:"enqueue_timeout" env:"API_CALL_ENQUEUE_TIMEOUT"`
+	FlushTimeout    time.Duration `yaml:"flush_timeout" env:"API_CALL_FLUSH_TIMEOUT"`
+	RollupInterval  time.Duration `yaml:"rollup_interval" env:"API_CALL_ROLLUP_INTERVAL"`
+	RawRetention    time.Duration `yaml:"raw_retention" env:"API_CALL_RAW_RETENTION"`
+	HourlyRetention time.Duration `yaml:"hourly_retention" env:"API_CALL_HOURLY_RETENTION"`
+	RollupTimeout   time.Duration `yaml:"rollup_timeout" env:"API_CALL_ROLLUP_TIMEOUT"`
+	// comma separated emails of the operators allowed to read the stats
+	Operators string `yaml:"operators" env:"API_CALL_OPERATORS"`
+}
+
+type TrashConfig struct {
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL"`
+	PurgeTimeout  time.Duration `yaml:"purge_timeout" env:"TRASH_PURGE_TIMEOUT"`
+	Retention     time.Duration `yaml:"retention" env:"TRASH_RETENTION"`
+}
+
+type MetricsConfig struct {
+	// address /metrics is served on apart from the api, empty doesn't serve it
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+	// longest time counting the domain gauges of one scrape may take
+	CountTimeout time.Duration `yaml:"count_timeout" env:"METRICS_COUNT_TIMEOUT"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:               ":8080",
+			ReadTimeout:        15 * time.Second,
+			ReadHeaderTimeout:  5 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        60 * time.Second,
+			MaxHeaderBytes:     1 << 20,
+			RequestTimeout:     10 * time.Second,
+			ShutdownTimeout:    15 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:             "postgres",
+			Path:               "aquafarm.db",
+			Port:               5432,
+			SSLMode:            "disable",
+			TimeZone:           "UTC",
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Auth: AuthConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		ApiCall: ApiCallConfig{
+			BufferSize:      1024,
+			BatchSize:       100,
+			FlushInterval:   time.Second,
+			FlushTimeout:    10 * time.Second,
+			RollupInterval:  time.Hour,
+			RawRetention:    7 * 24 * time.Hour,
+			HourlyRetention: 90 * 24 * time.Hour,
+			RollupTimeout:   10 * time.Minute,
+		},
+		Trash: TrashConfig{
+			PurgeInterval: time.Hour,
+			PurgeTimeout:  10 * time.Minute,
+			Retention:     30 * 24 * time.Hour,
+		},
+		Metrics: MetricsConfig{
+			CountTimeout: 2 * time.Second,
+		},
+	}
+}
+
+// LoadConfig reads the config and reports every invalid value at once. A
+// missing .env is fine, variables then come from the real environment only.
+func LoadConfig() (Config, error) {
 	err := godotenv.Load(".env")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("can't load .env: %w", err)
+	}
+
+	config := DefaultConfig()
+
+	path := os.Getenv("CONFIG_FILE")
+	if path != "" {
+		err := loadConfigFile(path, &config)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	err = errors.Join(loadConfigEnv(reflect.ValueOf(&config).Elem()), config.Validate())
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func loadConfigFile(path string, config *Config) error {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Println("Error loading .env file")
-		log.Fatal(err)
+		return fmt.Errorf("can't open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("can't parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadConfigEnv overrides every field with an env tag whose variable is set
+// and not empty
+func loadConfigEnv(value reflect.Value) error {
+	var errs []error
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if structField.Type.Kind() == reflect.Struct {
+			errs = append(errs, loadConfigEnv(field))
+			continue
+		}
+
+		key := structField.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+
+		switch {
+		case structField.Type == reflect.TypeOf(time.Duration(0)):
+			duration, err := time.ParseDuration(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 15m, got %q", key, raw))
+				continue
+			}
+			field.SetInt(int64(duration))
+
+		case structField.Type.Kind() == reflect.Int:
+			number, err := strconv.Atoi(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer, got %q", key, raw))
+				continue
+			}
+			field.SetInt(int64(number))
+
+		case structField.Type.Kind() == reflect.String:
+			field.SetString(raw)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every missing or out of range value
+func (config Config) Validate() error {
+	var errs []error
+	check := func(ok bool, message string) {
+		if !ok {
+			errs = append(errs, errors.New(message))
+		}
+	}
+
+	check(config.HTTP.Addr != "", "http addr is required")
+	check(config.HTTP.ReadTimeout > 0, "http read timeout must be positive")
+	check(config.HTTP.ReadHeaderTimeout > 0, "http read header timeout must be positive")
+	check(config.HTTP.WriteTimeout > 0, "http write timeout must be positive")
+	check(config.HTTP.IdleTimeout > 0, "http idle timeout must be positive")
+	check(config.HTTP.MaxHeaderBytes > 0, "http max header bytes must be positive")
+	check(config.HTTP.RequestTimeout >= 0, "request timeout can't be negative")
+	check(config.HTTP.ShutdownTimeout > 0, "shutdown timeout must be positive")
+	check(config.HTTP.HealthCheckTimeout > 0, "health check timeout must be positive")
+
+	switch config.Database.Driver {
+	case "postgres":
+		check(config.Database.Host != "", "database host is required")
+		check(config.Database.Port > 0 && config.Database.Port <= 65535, "database port must be between 1 and 65535")
+		check(config.Database.User != "", "database user is required")
+		check(config.Database.Name != "", "database name is required")
+		check(config.Database.SSLMode != "", "database sslmode is required")
+		_, err := time.LoadLocation(config.Database.TimeZone)
+		check(config.Database.TimeZone != "" && err == nil, "database timezone must be an IANA time zone such as UTC")
+	case "sqlite":
+		check(config.Database.Path != "", "database path is required")
+	default:
+		check(false, "database driver must be postgres or sqlite")
 	}
+	check(config.Database.SlowQueryThreshold > 0, "database slow query threshold must be positive")
+
+	_, err := logrus.ParseLevel(config.Log.Level)
+	check(err == nil, "log level must be one of panic, fatal, error, warn, info, debug or trace")
+	check(config.Log.Format == "json" || config.Log.Format == "text", "log format must be json or text")
+
+	check(len(config.Auth.JWTSecret) >= 32, "jwt secret must be at least 32 characters")
+	check(config.Auth.AccessTTL > 0, "jwt access ttl must be positive")
+	check(config.Auth.RefreshTTL > 0, "jwt refresh ttl must be positive")
+
+	check(config.ApiCall.BufferSize > 0, "api call buffer size must be positive")
+	check(config.ApiCall.BatchSize > 0, "api call batch size must be positive")
+	check(config.ApiCall.FlushInterval > 0, "api call flush interval must be positive")
+	check(config.ApiCall.EnqueueTimeout >= 0, "api call enqueue timeout can't be negative")
+	check(config.ApiCall.FlushTimeout > 0, "api call flush timeout must be positive")
+	check(config.ApiCall.RollupInterval > 0, "api call rollup interval must be positive")
+	check(config.ApiCall.RawRetention > 0, "api call raw retention must be positive")
+	check(config.ApiCall.HourlyRetention >= config.ApiCall.RawRetention, "api call hourly retention must be at least raw retention")
+	check(config.ApiCall.RollupTimeout > 0, "api call rollup timeout must be positive")
+
+	check(config.Trash.PurgeInterval > 0, "trash purge interval must be positive")
+	check(config.Trash.PurgeTimeout > 0, "trash purge timeout must be positive")
+	check(config.Trash.Retention > 0, "trash retention must be positive")
+
+	check(config.Metrics.Addr != config.HTTP.Addr, "metrics addr must differ from http addr")
+	check(config.Metrics.CountTimeout > 0, "metrics count timeout must be positive")
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER", "aquafarm")
+	t.Setenv("DB_NAME", "aquafarm")
+	t.Setenv("JWT_SECRET", testSecret)
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("should fill unset values with defaults", func(t *testing.T) {
+		// prepare env
+		setRequiredEnv(t)
+
+		// load config
+		config, err := LoadConfig()
+
+		//test load config
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, ":8080", config.HTTP.Addr, "http addr should be equal")
+		assert.Equal(t, 5432, config.Database.Port, "database port should be equal")
+		assert.Equal(t, "UTC", config.Database.TimeZone, "database timezone should be equal")
+		assert.Equal(t, 15*time.Minute, config.Auth.AccessTTL, "access ttl should be equal")
+	})
+
+	t.Run("should let env override the config file", func(t *testing.T) {
+		// prepare env and file
+		setRequiredEnv(t)
+		t.Setenv("CONFIG_FILE", writeConfigFile(t, "http:\n  addr: \":9000\"\n  request_timeout: 3s\ndatabase:\n  port: 6543\n"))
+		t.Setenv("DB_PORT", "7654")
+
+		// load config
+		config, err := LoadConfig()
+
+		//test load config
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, ":9000", config.HTTP.Addr, "http addr should be equal")
+		assert.Equal(t, 3*time.Second, config.HTTP.RequestTimeout, "request timeout should be equal")
+		assert.Equal(t, 7654, config.Database.Port, "database port should be equal")
+	})
+
+	t.Run("should reject unknown keys in the config file", func(t *testing.T) {
+		// prepare env and file
+		setRequiredEnv(t)
+		t.Setenv("CONFIG_FILE", writeConfigFile(t, "http:\n  adress: \":9000\"\n"))
+
+		// load config
+		_, err := LoadConfig()
+
+		//test load config
+		assert.ErrorContains(t, err, "adress", "error should name the key")
+	})
+
+	t.Run("should report every invalid value", func(t *testing.T) {
+		// prepare env
+		t.Setenv("DB_HOST", "")
+		t.Setenv("JWT_SECRET", "short")
+		t.Setenv("JWT_ACCESS_TTL", "soon")
+		t.Setenv("LOG_LEVEL", "loud")
+		t.Setenv("METRICS_ADDR", ":8080")
+
+		// load config
+		_, err := LoadConfig()
+
+		//test load config
+		assert.ErrorContains(t, err, "database host is required", "error should contain missing host")
+		assert.ErrorContains(t, err, "jwt secret must be at least 32 characters", "error should contain short secret")
+		assert.ErrorContains(t, err, "JWT_ACCESS_TTL must be a duration", "error should contain invalid duration")
+		assert.ErrorContains(t, err, "log level must be one of", "error should contain invalid level")
+		assert.ErrorContains(t, err, "metrics addr must differ from http addr", "error should contain shared metrics addr")
+	})
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"gorm.io/gorm"
+)
+
+// Names of the supported drivers, as reported by db.Dialector.Name()
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// ConnectToDB opens the database of the configured driver
+func ConnectToDB(config infrastructure.DatabaseConfig) (*gorm.DB, error) {
+	switch config.Driver {
+	case DriverPostgres:
+		return connectToPostgres(config)
+	case DriverSQLite:
+		return connectToSQLite(config)
+	}
+
+	return nil, fmt.Errorf("unknown database driver %q", config.Driver)
+}
+
+// Close closes the connection pool of db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// Ping checks a connection of db can reach the database
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,68 @@
+// Package databasetest opens migrated databases for the repository tests, so
+// the GORM code of the repositories runs against a real database instead of
+// the mocks
+package databasetest
+
+import (
+	"testing"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+// NewDB opens an empty in-memory SQLite database with every migration
+// applied. Each call gets its own database, closed when the test ends.
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+
+	db, err := database.ConnectToDB(infrastructure.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		database.Close(db)
+	})
+
+	err = database.MigrateUp(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// CreateOrganization inserts an organization named name
+func CreateOrganization(t testing.TB, db *gorm.DB, name string) domain.Organization {
+	t.Helper()
+
+	organization := domain.Organization{
+		Name: name,
+	}
+	err := db.Create(&organization).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return organization
+}
+
+// CreateUser inserts a user of the organization with the given email
+func CreateUser(t testing.TB, db *gorm.DB, organizationId string, email string) domain.User {
+	t.Helper()
+
+	user := domain.User{
+		OrganizationID: organizationId,
+		Name:           "test_user",
+		Email:          email,
+		Password:       "password",
+	}
+	err := db.Create(&user).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Queries slower than this are logged as warnings, unless configured otherwise
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger writes failed and slow queries to the log entry of the request
+// context, so they carry the request id of the query
+type gormLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(slowThreshold time.Duration) logger.Interface {
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowQueryThreshold
+	}
+
+	return &gormLogger{
+		level:         logger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (gormLogger *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *gormLogger
+	newLogger.level = level
+	return &newLogger
+}
+
+func (gormLogger *gormLogger) Info(ctx context.Context, message string, data ...any) {
+	if gormLogger.level >= logger.Info {
+		infrastructure.LoggerFrom(ctx).Infof(message, data...)
+	}
+}
+
+func (gormLogger *gormLogger) Warn(ctx context.Context, message string, data ...any) {
+	if gormLogger.level >= logger.Warn {
+		infrastructure.LoggerFrom(ctx).Warnf(message, data...)
+	}
+}
+
+func (gormLogger *gormLogger) Error(ctx context.Context, message string, data ...any) {
+	if gormLogger.level >= logger.Error {
+		infrastructure.LoggerFrom(ctx).Errorf(message, data...)
+	}
+}
+
+func (gormLogger *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if gormLogger.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	isFailed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	isSlow := gormLogger.slowThreshold > 0 && elapsed > gormLogger.slowThreshold
+
+	switch {
+	case isFailed && gormLogger.level >= logger.Error:
+		sql, rows := fc()
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"SQL":        sql,
+			"ROWS":       rows,
+			"LATENCY_MS": float64(elapsed.Microseconds()) / 1000,
+			"ERROR":      err.Error(),
+		}).Error("Failed Database Query")
+	case isSlow && gormLogger.level >= logger.Warn:
+		sql, rows := fc()
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"SQL":        sql,
+			"ROWS":       rows,
+			"LATENCY_MS": float64(elapsed.Microseconds()) / 1000,
+		}).Warn("Slow Database Query")
+	}
+}
+
+// ParamsFilter keeps query values such as password hashes out of the logs
+func (gormLogger *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
@@ -1,17 +1,283 @@
 package database
 
-import "github.com/reyhanmichiels/AquaFarmManagement/domain"
-
-func Migrate() {
-	DB.Migrator().DropTable(
-		&domain.Farm{},
-		&domain.Pond{},
-		&domain.ApiCall{},
-	)
-
-	DB.AutoMigrate(
-		&domain.Farm{},
-		&domain.Pond{},
-		&domain.ApiCall{},
-	)
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single numbered schema change. Up must be reversible by Down.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Model for applied migration bookkeeping
+type SchemaMigration struct {
+	Version   int       `gorm:"primary key; autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255); not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// advisory lock key shared by every process running migrations
+const migrationLockKey = 2023112001
+
+// MigrateUp applies every pending migration in version order.
+func MigrateUp(db *gorm.DB) error {
+	return migrateUp(db, migrations)
+}
+
+// MigrateDown reverts the latest applied migrations, at most steps of them.
+func MigrateDown(db *gorm.DB, steps int) error {
+	return migrateDown(db, migrations, steps)
+}
+
+// GetMigrationStatus reports every known migration and whether it is applied.
+func GetMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	return getMigrationStatus(db, migrations)
+}
+
+// CheckMigrations fails when a known migration is not applied yet
+func CheckMigrations(ctx context.Context, db *gorm.DB) error {
+	return checkMigrations(db.WithContext(ctx), migrations)
+}
+
+func migrateUp(db *gorm.DB, list []Migration) error {
+	list, err := sortMigrations(list)
+	if err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, migration := range list {
+		migration := migration
+		err := migrationTransaction(db, func(tx *gorm.DB) error {
+			if err := lockMigrations(tx); err != nil {
+				return err
+			}
+
+			// another process may have applied it while we waited for the lock
+			var count int64
+			if err := tx.Model(&SchemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count != 0 {
+				return nil
+			}
+
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s up: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func migrateDown(db *gorm.DB, list []Migration, steps int) error {
+	list, err := sortMigrations(list)
+	if err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	byVersion := make(map[int]Migration, len(list))
+	for _, migration := range list {
+		byVersion[migration.Version] = migration
+	}
+
+	for i := 0; i < steps; i++ {
+		var latest SchemaMigration
+		err := db.Order("version desc").First(&latest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		migration, ok := byVersion[latest.Version]
+		if !ok {
+			return fmt.Errorf("migration %04d is applied but unknown to this build", latest.Version)
+		}
+
+		err = migrationTransaction(db, func(tx *gorm.DB) error {
+			if err := lockMigrations(tx); err != nil {
+				return err
+			}
+
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s down: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func getMigrationStatus(db *gorm.DB, list []Migration) ([]MigrationStatus, error) {
+	list, err := sortMigrations(list)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var applied []SchemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, v := range applied {
+		appliedAt[v.Version] = v.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(list))
+	for _, migration := range list {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func checkMigrations(db *gorm.DB, list []Migration) error {
+	var versions []int
+	if err := db.Model(&SchemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	pending := 0
+	for _, migration := range list {
+		if !applied[migration.Version] {
+			pending++
+		}
+	}
+	if pending != 0 {
+		return fmt.Errorf("%d pending migration(s)", pending)
+	}
+
+	return nil
+}
+
+func sortMigrations(list []Migration) ([]Migration, error) {
+	sorted := make([]Migration, len(list))
+	copy(sorted, list)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %04d", sorted[i].Version)
+		}
+	}
+
+	return sorted, nil
+}
+
+// migrationTransaction runs fc in a transaction. SQLite alters a table by
+// rebuilding it, and dropping the old table would cascade into the rows
+// referencing it, so foreign keys are off during the migration and checked
+// before it commits. The pragma is a no-op inside a transaction, hence the
+// dedicated connection around it.
+func migrationTransaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if db.Dialector.Name() != DriverSQLite {
+		return db.Transaction(fc)
+	}
+
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			if err := fc(tx); err != nil {
+				return err
+			}
+
+			return checkForeignKeys(tx)
+		})
+	})
+}
+
+// checkForeignKeys fails when a row references a missing parent
+func checkForeignKeys(tx *gorm.DB) error {
+	var violations []struct {
+		Table  string
+		Parent string
+	}
+	if err := tx.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+		return err
+	}
+
+	if len(violations) != 0 {
+		return fmt.Errorf("%d foreign key violation(s), first in %s referencing %s", len(violations), violations[0].Table, violations[0].Parent)
+	}
+
+	return nil
+}
+
+// lockMigrations serializes concurrent migrators (e.g. several replicas booting
+// at once) for the lifetime of the transaction. SQLite allows a single writer
+// anyway.
+func lockMigrations(tx *gorm.DB) error {
+	if tx.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error
 }
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	infrastructure.CreateLogger(infrastructure.DefaultConfig().Log)
+
+	db, err := ConnectToDB(infrastructure.DatabaseConfig{Driver: DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		Close(db)
+	})
+
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("should apply every migration on sqlite", func(t *testing.T) {
+		// prepare database
+		db := newSQLiteDB(t)
+
+		// migrate up
+		err := MigrateUp(db)
+
+		//test migrate up
+		assert.Nil(t, err, "error should be nil")
+		assert.Nil(t, CheckMigrations(db.Statement.Context, db), "no migration should be pending")
+		for _, table := range []string{"organizations", "users", "farms", "ponds", "farm_members", "cycles", "feedings", "water_readings", "water_thresholds", "water_alerts", "api_calls", "api_call_rollups"} {
+			assert.True(t, db.Migrator().HasTable(table), "table %s should exist", table)
+		}
+	})
+
+	t.Run("should scope farm names to their organization on sqlite", func(t *testing.T) {
+		// prepare database
+		db := newSQLiteDB(t)
+		err := MigrateUp(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// insert farms with the same name in two organizations
+		statements := []string{
+			"INSERT INTO organizations (id, name) VALUES ('org-1', 'first'), ('org-2', 'second')",
+			"INSERT INTO farms (id, name, organization_id) VALUES ('farm-1', 'north', 'org-1'), ('farm-2', 'north', 'org-2')",
+		}
+		for _, statement := range statements {
+			err = db.Exec(statement).Error
+			assert.Nil(t, err, "error should be nil")
+		}
+
+		//test uniqueness within an organization
+		err = db.Exec("INSERT INTO farms (id, name, organization_id) VALUES ('farm-3', 'north', 'org-1')").Error
+		assert.NotNil(t, err, "error should not be nil")
+	})
+
+	t.Run("should move legacy rows into a default organization on sqlite", func(t *testing.T) {
+		// prepare database before organizations existed
+		db := newSQLiteDB(t)
+		err := migrateUp(db, migrations[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statements := []string{
+			"INSERT INTO users (id, name, email, password) VALUES ('user-1', 'user', 'user@mail.com', 'hash')",
+			"INSERT INTO farms (id, name) VALUES ('farm-1', 'north')",
+		}
+		for _, statement := range statements {
+			err = db.Exec(statement).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// migrate up
+		err = MigrateUp(db)
+
+		//test default organization
+		assert.Nil(t, err, "error should be nil")
+
+		var organizations []string
+		err = db.Raw("SELECT DISTINCT organization_id FROM users UNION SELECT organization_id FROM farms").Scan(&organizations).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Len(t, organizations, 1, "users and farms should share one organization")
+	})
+
+	t.Run("should key farm members by farm and user on sqlite", func(t *testing.T) {
+		// prepare database
+		db := newSQLiteDB(t)
+		err := MigrateUp(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// read primary key columns
+		var keys []string
+		err = db.Raw("SELECT name FROM pragma_table_info('farm_members') WHERE pk > 0 ORDER BY pk").Scan(&keys).Error
+
+		//test primary key
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, []string{"farm_id", "user_id"}, keys, "primary key should be equal")
+	})
+
+	t.Run("should keep the rows of remaining tables on every step down on sqlite", func(t *testing.T) {
+		// prepare database
+		db := newSQLiteDB(t)
+		err := MigrateUp(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// insert one row in every table
+		for _, statement := range seedStatements {
+			err = db.Exec(statement).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for range migrations {
+			// migrate one step down
+			err = MigrateDown(db, 1)
+			assert.Nil(t, err, "error should be nil")
+
+			//test remaining rows
+			for _, table := range seededTables {
+				if !db.Migrator().HasTable(table) {
+					continue
+				}
+
+				var count int64
+				err = db.Table(table).Count(&count).Error
+				assert.Nil(t, err, "error should be nil")
+				assert.Equal(t, int64(1), count, "row of %s should be kept", table)
+			}
+		}
+
+		//test foreign keys are enforced again
+		var foreignKeys int
+		err = db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, 1, foreignKeys, "foreign keys should be on")
+	})
+
+	t.Run("should drop duplicate farm members before keying them on sqlite", func(t *testing.T) {
+		// prepare database before farm members had a key
+		db := newSQLiteDB(t)
+		err := migrateUp(db, migrations[:11])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statements := []string{
+			"INSERT INTO organizations (id, name) VALUES ('org-1', 'first')",
+			"INSERT INTO users (id, name, email, password, organization_id) VALUES ('user-1', 'user', 'user@mail.com', 'hash', 'org-1')",
+			"INSERT INTO farms (id, name, organization_id) VALUES ('farm-1', 'north', 'org-1')",
+			"INSERT INTO farm_members (farm_id, user_id, role) VALUES ('farm-1', 'user-1', 'manager'), ('farm-1', 'user-1', 'manager')",
+		}
+		for _, statement := range statements {
+			err = db.Exec(statement).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// migrate up
+		err = MigrateUp(db)
+
+		//test remaining membership
+		assert.Nil(t, err, "error should be nil")
+
+		var count int64
+		err = db.Table("farm_members").Count(&count).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, int64(1), count, "one membership should be kept")
+	})
+
+	t.Run("should make one live user per organization its admin on sqlite", func(t *testing.T) {
+		// prepare database before users had roles
+		db := newSQLiteDB(t)
+		err := migrateUp(db, migrations[:13])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statements := []string{
+			"INSERT INTO organizations (id, name) VALUES ('org-1', 'first')",
+			"INSERT INTO users (id, name, email, password, organization_id, created_at, deleted_at) VALUES ('user-0', 'deleted', 'deleted@mail.com', 'hash', 'org-1', '2023-01-01 00:00:00', '2023-01-02 00:00:00')",
+			"INSERT INTO users (id, name, email, password, organization_id, created_at) VALUES ('user-2', 'second', 'second@mail.com', 'hash', 'org-1', '2023-01-03 00:00:00'), ('user-1', 'first', 'first@mail.com', 'hash', 'org-1', '2023-01-03 00:00:00')",
+		}
+		for _, statement := range statements {
+			err = db.Exec(statement).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// migrate up
+		err = MigrateUp(db)
+
+		//test admins
+		assert.Nil(t, err, "error should be nil")
+
+		var admins []string
+		err = db.Raw("SELECT id FROM users WHERE role = 'admin'").Scan(&admins).Error
+		assert.Nil(t, err, "error should be nil")
+		assert.Equal(t, []string{"user-1"}, admins, "admins should be equal")
+	})
+
+	t.Run("should revert every migration on sqlite", func(t *testing.T) {
+		// prepare database
+		db := newSQLiteDB(t)
+		err := MigrateUp(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// migrate down
+		err = MigrateDown(db, len(migrations))
+
+		//test migrate down
+		assert.Nil(t, err, "error should be nil")
+		for _, table := range []string{"organizations", "users", "farms", "ponds", "api_calls"} {
+			assert.False(t, db.Migrator().HasTable(table), "table %s should not exist", table)
+		}
+	})
+}
+
+// One row per table of the latest schema, parents first
+var seedStatements = []string{
+	"INSERT INTO organizations (id, name) VALUES ('org-1', 'first')",
+	"INSERT INTO users (id, name, email, password, organization_id) VALUES ('user-1', 'user', 'user@mail.com', 'hash', 'org-1')",
+	"INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at) VALUES ('token-1', 'user-1', 'hash', '2030-01-01 00:00:00')",
+	"INSERT INTO farms (id, name, organization_id) VALUES ('farm-1', 'north', 'org-1')",
+	"INSERT INTO ponds (id, farm_id, name) VALUES ('pond-1', 'farm-1', 'first')",
+	"INSERT INTO farm_members (farm_id, user_id, role) VALUES ('farm-1', 'user-1', 'manager')",
+	"INSERT INTO cycles (id, pond_id, species, stocked_at, stock_count, average_weight, status) VALUES ('cycle-1', 'pond-1', 'shrimp', '2023-01-01 00:00:00', 100, 1, 'active')",
+	"INSERT INTO feedings (id, cycle_id, fed_at, feed_type, quantity, session) VALUES ('feeding-1', 'cycle-1', '2023-01-02 00:00:00', 'pellet', 1, 'morning')",
+	"INSERT INTO water_readings (id, pond_id, measured_at) VALUES ('reading-1', 'pond-1', '2023-01-02 00:00:00')",
+	"INSERT INTO water_thresholds (id, farm_id, parameter) VALUES ('threshold-1', 'farm-1', 'ph')",
+	"INSERT INTO water_alerts (id, reading_id, farm_id, pond_id, parameter, value) VALUES ('alert-1', 'reading-1', 'farm-1', 'pond-1', 'ph', 9)",
+	"INSERT INTO api_calls (endpoint, method, ip_adress) VALUES ('/api/farms', 'GET', '127.0.0.1')",
+	"INSERT INTO api_call_rollups (granularity, bucket_start, endpoint, route, method, ip_adress, status_code, count) VALUES ('hour', '2023-01-01 00:00:00', '/api/farms', '/api/farms', 'GET', '127.0.0.1', 200, 1)",
+}
+
+var seededTables = []string{"organizations", "users", "refresh_tokens", "farms", "ponds", "farm_members", "cycles", "feedings", "water_readings", "water_thresholds", "water_alerts", "api_calls", "api_call_rollups"}
@@ -0,0 +1,808 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"gorm.io/gorm"
+)
+
+// Registered schema migrations. Never edit an applied migration: add a new one.
+// Each migration declares frozen snapshots of the tables it touches so it keeps
+// producing the same schema while the domain models evolve.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_farms_ponds_api_calls",
+		Up:      createFarmsPondsApiCallsUp,
+		Down:    createFarmsPondsApiCallsDown,
+	},
+	{
+		Version: 2,
+		Name:    "create_users_refresh_tokens",
+		Up:      createUsersRefreshTokensUp,
+		Down:    createUsersRefreshTokensDown,
+	},
+	{
+		Version: 3,
+		Name:    "create_farm_members",
+		Up:      createFarmMembersUp,
+		Down:    createFarmMembersDown,
+	},
+	{
+		Version: 4,
+		Name:    "create_organizations",
+		Up:      createOrganizationsUp,
+		Down:    createOrganizationsDown,
+	},
+	{
+		Version: 5,
+		Name:    "add_pond_attributes",
+		Up:      addPondAttributesUp,
+		Down:    addPondAttributesDown,
+	},
+	{
+		Version: 6,
+		Name:    "create_cycles",
+		Up:      createCyclesUp,
+		Down:    createCyclesDown,
+	},
+	{
+		Version: 7,
+		Name:    "create_feedings",
+		Up:      createFeedingsUp,
+		Down:    createFeedingsDown,
+	},
+	{
+		Version: 8,
+		Name:    "create_water_quality",
+		Up:      createWaterQualityUp,
+		Down:    createWaterQualityDown,
+	},
+	{
+		Version: 9,
+		Name:    "add_api_call_outcome",
+		Up:      addApiCallOutcomeUp,
+		Down:    addApiCallOutcomeDown,
+	},
+	{
+		Version: 10,
+		Name:    "add_api_call_route",
+		Up:      addApiCallRouteUp,
+		Down:    addApiCallRouteDown,
+	},
+	{
+		Version: 11,
+		Name:    "create_api_call_rollups",
+		Up:      createApiCallRollupsUp,
+		Down:    createApiCallRollupsDown,
+	},
+	{
+		Version: 12,
+		Name:    "add_farm_members_primary_key",
+		Up:      addFarmMembersPrimaryKeyUp,
+		Down:    addFarmMembersPrimaryKeyDown,
+	},
+	{
+		Version: 13,
+		Name:    "add_farm_pond_versions",
+		Up:      addFarmPondVersionsUp,
+		Down:    addFarmPondVersionsDown,
+	},
+	{
+		Version: 14,
+		Name:    "add_user_roles",
+		Up:      addUserRolesUp,
+		Down:    addUserRolesDown,
+	},
+}
+
+type farmV1 struct {
+	ID        string   `gorm:"type:uuid; not null; primary key"`
+	Ponds     []pondV1 `gorm:"foreignKey:FarmID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name      string   `gorm:"type:varchar(100); not null; unique"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (farmV1) TableName() string { return "farms" }
+
+type pondV1 struct {
+	ID        string `gorm:"type:uuid;not null; primary key"`
+	FarmID    string `gorm:"type:uuid;not null"`
+	Name      string `gorm:"type:varchar(100); not null; unique"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (pondV1) TableName() string { return "ponds" }
+
+type apiCallV1 struct {
+	Endpoint  string `gorm:"type:varchar(100); not null;"`
+	Method    string `gorm:"type:varchar(20); not null"`
+	IpAdress  string `gorm:"type:varchar(100); not null;"`
+	CreatedAt time.Time
+}
+
+func (apiCallV1) TableName() string { return "api_calls" }
+
+// Baseline schema. Databases created by the former AutoMigrate boot flow
+// already have these tables, so existing ones are left untouched.
+func createFarmsPondsApiCallsUp(tx *gorm.DB) error {
+	for _, table := range []any{&farmV1{}, &pondV1{}, &apiCallV1{}} {
+		if tx.Migrator().HasTable(table) {
+			continue
+		}
+
+		if err := tx.Migrator().CreateTable(table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createFarmsPondsApiCallsDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&apiCallV1{}, &pondV1{}, &farmV1{})
+}
+
+type userV2 struct {
+	ID        string `gorm:"type:uuid; not null; primary key"`
+	Name      string `gorm:"type:varchar(100); not null"`
+	Email     string `gorm:"type:varchar(255); not null; unique"`
+	Password  string `gorm:"type:varchar(255); not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (userV2) TableName() string { return "users" }
+
+type refreshTokenV2 struct {
+	ID        string    `gorm:"type:uuid; not null; primary key"`
+	UserID    string    `gorm:"type:uuid; not null; index"`
+	User      userV2    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string    `gorm:"type:varchar(64); not null; unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (refreshTokenV2) TableName() string { return "refresh_tokens" }
+
+func createUsersRefreshTokensUp(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&userV2{}, &refreshTokenV2{})
+}
+
+func createUsersRefreshTokensDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&refreshTokenV2{}, &userV2{})
+}
+
+type farmMemberV3 struct {
+	FarmID    string `gorm:"type:uuid; not null; primary key"`
+	Farm      farmV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    string `gorm:"type:uuid; not null; primary key; index"`
+	User      userV2 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role      string `gorm:"type:varchar(20); not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (farmMemberV3) TableName() string { return "farm_members" }
+
+// Farms created before memberships existed have no members and stay hidden
+// until one is added directly in the database.
+func createFarmMembersUp(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&farmMemberV3{})
+}
+
+func createFarmMembersDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&farmMemberV3{})
+}
+
+type organizationV4 struct {
+	ID        string `gorm:"type:uuid; not null; primary key"`
+	Name      string `gorm:"type:varchar(100); not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (organizationV4) TableName() string { return "organizations" }
+
+type userV4 struct {
+	OrganizationID *string         `gorm:"type:uuid; index"`
+	Organization   *organizationV4 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+}
+
+func (userV4) TableName() string { return "users" }
+
+type farmV4 struct {
+	OrganizationID *string         `gorm:"type:uuid; index"`
+	Organization   *organizationV4 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+}
+
+func (farmV4) TableName() string { return "farms" }
+
+// Moves users and farms under organizations and scopes name uniqueness to
+// them. Rows created before tenants existed all join one default organization,
+// which keeps them visible to each other as they were.
+func createOrganizationsUp(tx *gorm.DB) error {
+	err := tx.Migrator().CreateTable(&organizationV4{})
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []any{&userV4{}, &farmV4{}} {
+		err = tx.Migrator().AddColumn(table, "OrganizationID")
+		if err != nil {
+			return err
+		}
+	}
+
+	var legacyRows int64
+	err = tx.Raw("SELECT (SELECT COUNT(*) FROM users) + (SELECT COUNT(*) FROM farms)").Scan(&legacyRows).Error
+	if err != nil {
+		return err
+	}
+
+	if legacyRows > 0 {
+		organization := organizationV4{
+			ID:   uuid.NewString(),
+			Name: "Default organization",
+		}
+		err = tx.Create(&organization).Error
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"users", "farms"} {
+			err = tx.Exec("UPDATE "+table+" SET organization_id = ?", organization.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if tx.Dialector.Name() == DriverSQLite {
+		err = alterOrganizationColumnsSQLite(tx)
+	} else {
+		err = execStatements(tx, []string{
+			"ALTER TABLE users ALTER COLUMN organization_id SET NOT NULL",
+			"ALTER TABLE farms ALTER COLUMN organization_id SET NOT NULL",
+			"ALTER TABLE farms DROP CONSTRAINT IF EXISTS farms_name_key",
+			"ALTER TABLE ponds DROP CONSTRAINT IF EXISTS ponds_name_key",
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []any{&userV4{}, &farmV4{}} {
+		err = tx.Migrator().CreateConstraint(table, "Organization")
+		if err != nil {
+			return err
+		}
+	}
+
+	// after the constraints, as SQLite rebuilds the table to add one and
+	// drops its indexes on the way
+	return execStatements(tx, []string{
+		"CREATE UNIQUE INDEX idx_farms_organization_name ON farms (organization_id, name) WHERE deleted_at IS NULL",
+		"CREATE UNIQUE INDEX idx_ponds_farm_name ON ponds (farm_id, name) WHERE deleted_at IS NULL",
+	})
+}
+
+func createOrganizationsDown(tx *gorm.DB) error {
+	err := execStatements(tx, []string{
+		"DROP INDEX IF EXISTS idx_ponds_farm_name",
+		"DROP INDEX IF EXISTS idx_farms_organization_name",
+	})
+	if err != nil {
+		return err
+	}
+
+	if tx.Dialector.Name() == DriverSQLite {
+		err = restoreNameColumnsSQLite(tx)
+	} else {
+		err = execStatements(tx, []string{
+			"ALTER TABLE ponds ADD CONSTRAINT ponds_name_key UNIQUE (name)",
+			"ALTER TABLE farms ADD CONSTRAINT farms_name_key UNIQUE (name)",
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []any{&farmV4{}, &userV4{}} {
+		err = tx.Migrator().DropColumn(table, "OrganizationID")
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Migrator().DropTable(&organizationV4{})
+}
+
+// Columns as they end up after the migration, for SQLite to rebuild the tables with
+type userOrganizationV4 struct {
+	OrganizationID string `gorm:"type:uuid; not null"`
+}
+
+func (userOrganizationV4) TableName() string { return "users" }
+
+type farmOrganizationV4 struct {
+	OrganizationID string `gorm:"type:uuid; not null"`
+	Name           string `gorm:"type:varchar(100); not null"`
+}
+
+func (farmOrganizationV4) TableName() string { return "farms" }
+
+type pondNameV4 struct {
+	Name string `gorm:"type:varchar(100); not null"`
+}
+
+func (pondNameV4) TableName() string { return "ponds" }
+
+// SQLite alters no column in place, so the migrator rebuilds the tables with
+// the organization required and names no longer unique on their own
+func alterOrganizationColumnsSQLite(tx *gorm.DB) error {
+	columns := []struct {
+		table  any
+		column string
+	}{
+		{&userOrganizationV4{}, "OrganizationID"},
+		{&farmOrganizationV4{}, "OrganizationID"},
+		{&farmOrganizationV4{}, "Name"},
+		{&pondNameV4{}, "Name"},
+	}
+	for _, column := range columns {
+		err := tx.Migrator().AlterColumn(column.table, column.column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebuilds farms and ponds with unique names again, and drops the foreign
+// keys the rebuilt tables would otherwise keep on the dropped columns
+func restoreNameColumnsSQLite(tx *gorm.DB) error {
+	for _, table := range []any{&pondV1{}, &farmV1{}} {
+		err := tx.Migrator().AlterColumn(table, "Name")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range []any{&farmV4{}, &userV4{}} {
+		err := tx.Migrator().DropConstraint(table, "Organization")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func execStatements(tx *gorm.DB, statements []string) error {
+	for _, statement := range statements {
+		err := tx.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type pondV5 struct {
+	Type         string  `gorm:"type:varchar(20); not null; default:''"`
+	Shape        string  `gorm:"type:varchar(20); not null; default:''"`
+	SurfaceArea  float64 `gorm:"type:numeric(14,4); not null; default:0"`
+	AverageDepth float64 `gorm:"type:numeric(8,4); not null; default:0"`
+	Volume       float64 `gorm:"type:numeric(16,4); not null; default:0"`
+}
+
+func (pondV5) TableName() string { return "ponds" }
+
+var pondV5Columns = []string{"Type", "Shape", "SurfaceArea", "AverageDepth", "Volume"}
+
+func addPondAttributesUp(tx *gorm.DB) error {
+	for _, column := range pondV5Columns {
+		err := tx.Migrator().AddColumn(&pondV5{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addPondAttributesDown(tx *gorm.DB) error {
+	for _, column := range pondV5Columns {
+		err := tx.Migrator().DropColumn(&pondV5{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type pondV6 struct {
+	ID string `gorm:"type:uuid; not null; primary key"`
+}
+
+func (pondV6) TableName() string { return "ponds" }
+
+type cycleV6 struct {
+	ID             string    `gorm:"type:uuid; not null; primary key"`
+	PondID         string    `gorm:"type:uuid; not null; index"`
+	Pond           pondV6    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Species        string    `gorm:"type:varchar(100); not null"`
+	StockedAt      time.Time `gorm:"not null"`
+	StockCount     int64     `gorm:"not null"`
+	AverageWeight  float64   `gorm:"type:numeric(10,4); not null"`
+	Status         string    `gorm:"type:varchar(20); not null"`
+	HarvestedAt    *time.Time
+	HarvestCount   int64   `gorm:"not null; default:0"`
+	HarvestBiomass float64 `gorm:"type:numeric(14,4); not null; default:0"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (cycleV6) TableName() string { return "cycles" }
+
+// The partial unique index backs the one active cycle per pond rule against
+// concurrent stockings.
+func createCyclesUp(tx *gorm.DB) error {
+	err := tx.Migrator().CreateTable(&cycleV6{})
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("CREATE UNIQUE INDEX idx_cycles_active_pond ON cycles (pond_id) WHERE status = 'active'").Error
+}
+
+func createCyclesDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&cycleV6{})
+}
+
+type cycleV7 struct {
+	ID string `gorm:"type:uuid; not null; primary key"`
+}
+
+func (cycleV7) TableName() string { return "cycles" }
+
+type feedingV7 struct {
+	ID        string    `gorm:"type:uuid; not null; primary key"`
+	CycleID   string    `gorm:"type:uuid; not null; index"`
+	Cycle     cycleV7   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FedAt     time.Time `gorm:"not null"`
+	FeedType  string    `gorm:"type:varchar(100); not null"`
+	Quantity  float64   `gorm:"type:numeric(10,4); not null"`
+	Session   string    `gorm:"type:varchar(20); not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (feedingV7) TableName() string { return "feedings" }
+
+func createFeedingsUp(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&feedingV7{})
+}
+
+func createFeedingsDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&feedingV7{})
+}
+
+type farmV8 struct {
+	ID string `gorm:"type:uuid; not null; primary key"`
+}
+
+func (farmV8) TableName() string { return "farms" }
+
+type pondV8 struct {
+	ID string `gorm:"type:uuid; not null; primary key"`
+}
+
+func (pondV8) TableName() string { return "ponds" }
+
+type waterReadingV8 struct {
+	ID              string    `gorm:"type:uuid; not null; primary key"`
+	PondID          string    `gorm:"type:uuid; not null; index"`
+	Pond            pondV8    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MeasuredAt      time.Time `gorm:"not null"`
+	DissolvedOxygen *float64  `gorm:"type:numeric(8,4)"`
+	PH              *float64  `gorm:"type:numeric(8,4)"`
+	Temperature     *float64  `gorm:"type:numeric(8,4)"`
+	Ammonia         *float64  `gorm:"type:numeric(8,4)"`
+	Nitrite         *float64  `gorm:"type:numeric(8,4)"`
+	Salinity        *float64  `gorm:"type:numeric(8,4)"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (waterReadingV8) TableName() string { return "water_readings" }
+
+type waterThresholdV8 struct {
+	ID        string   `gorm:"type:uuid; not null; primary key"`
+	FarmID    string   `gorm:"type:uuid; not null; index"`
+	Farm      farmV8   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PondID    *string  `gorm:"type:uuid; index"`
+	Pond      *pondV8  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Parameter string   `gorm:"type:varchar(30); not null"`
+	Min       *float64 `gorm:"type:numeric(8,4)"`
+	Max       *float64 `gorm:"type:numeric(8,4)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (waterThresholdV8) TableName() string { return "water_thresholds" }
+
+type waterAlertV8 struct {
+	ID        string         `gorm:"type:uuid; not null; primary key"`
+	ReadingID string         `gorm:"type:uuid; not null; index"`
+	Reading   waterReadingV8 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FarmID    string         `gorm:"type:uuid; not null; index"`
+	PondID    string         `gorm:"type:uuid; not null; index"`
+	Parameter string         `gorm:"type:varchar(30); not null"`
+	Value     float64        `gorm:"type:numeric(8,4); not null"`
+	Min       *float64       `gorm:"type:numeric(8,4)"`
+	Max       *float64       `gorm:"type:numeric(8,4)"`
+	CreatedAt time.Time
+}
+
+func (waterAlertV8) TableName() string { return "water_alerts" }
+
+// One threshold per parameter for a farm and one override per parameter for a
+// pond. A null pond_id is never equal in a unique index, hence the split.
+var waterThresholdV8Indexes = []string{
+	"CREATE UNIQUE INDEX idx_water_thresholds_farm_parameter ON water_thresholds (farm_id, parameter) WHERE pond_id IS NULL",
+	"CREATE UNIQUE INDEX idx_water_thresholds_pond_parameter ON water_thresholds (pond_id, parameter) WHERE pond_id IS NOT NULL",
+}
+
+func createWaterQualityUp(tx *gorm.DB) error {
+	err := tx.Migrator().CreateTable(&waterReadingV8{}, &waterThresholdV8{}, &waterAlertV8{})
+	if err != nil {
+		return err
+	}
+
+	for _, index := range waterThresholdV8Indexes {
+		err = tx.Exec(index).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createWaterQualityDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&waterAlertV8{}, &waterThresholdV8{}, &waterReadingV8{})
+}
+
+// Calls recorded before this migration keep a zero status code
+type apiCallV9 struct {
+	StatusCode   int     `gorm:"not null; default:0"`
+	Latency      float64 `gorm:"type:numeric(12,3); not null; default:0"`
+	ResponseSize int     `gorm:"not null; default:0"`
+	UserAgent    string  `gorm:"type:varchar(255); not null; default:''"`
+	UserID       *string `gorm:"type:uuid"`
+}
+
+func (apiCallV9) TableName() string { return "api_calls" }
+
+var apiCallV9Columns = []string{"StatusCode", "Latency", "ResponseSize", "UserAgent", "UserID"}
+
+func addApiCallOutcomeUp(tx *gorm.DB) error {
+	for _, column := range apiCallV9Columns {
+		err := tx.Migrator().AddColumn(&apiCallV9{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addApiCallOutcomeDown(tx *gorm.DB) error {
+	for _, column := range apiCallV9Columns {
+		err := tx.Migrator().DropColumn(&apiCallV9{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type apiCallV10 struct {
+	Route string `gorm:"type:varchar(255); not null; default:''"`
+}
+
+func (apiCallV10) TableName() string { return "api_calls" }
+
+func addApiCallRouteUp(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&apiCallV10{}, "Route")
+}
+
+func addApiCallRouteDown(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&apiCallV10{}, "Route")
+}
+
+type apiCallRollupV11 struct {
+	Granularity string    `gorm:"type:varchar(10); not null"`
+	BucketStart time.Time `gorm:"not null"`
+	Endpoint    string    `gorm:"type:varchar(100); not null"`
+	Route       string    `gorm:"type:varchar(255); not null"`
+	Method      string    `gorm:"type:varchar(20); not null"`
+	IpAdress    string    `gorm:"type:varchar(100); not null"`
+	StatusCode  int       `gorm:"not null"`
+	Count       int       `gorm:"not null"`
+}
+
+func (apiCallRollupV11) TableName() string { return "api_call_rollups" }
+
+// Rolling up adds to the row of an existing bucket, found through this key
+var apiCallRollupV11Indexes = []string{
+	"CREATE UNIQUE INDEX idx_api_call_rollups_key ON api_call_rollups (granularity, bucket_start, endpoint, route, method, ip_adress, status_code)",
+	"CREATE INDEX idx_api_calls_created_at ON api_calls (created_at)",
+}
+
+func createApiCallRollupsUp(tx *gorm.DB) error {
+	err := tx.Migrator().CreateTable(&apiCallRollupV11{})
+	if err != nil {
+		return err
+	}
+
+	for _, index := range apiCallRollupV11Indexes {
+		err = tx.Exec(index).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createApiCallRollupsDown(tx *gorm.DB) error {
+	err := tx.Exec("DROP INDEX IF EXISTS idx_api_calls_created_at").Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropTable(&apiCallRollupV11{})
+}
+
+// farm_members keyed by farm and user, as migration 3 meant to before gorm
+// ignored its "primary key" tags, so updates and deletes of a membership only
+// touch its own row
+type farmMemberV12 struct {
+	FarmID    string `gorm:"type:uuid; not null; primaryKey"`
+	Farm      farmV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    string `gorm:"type:uuid; not null; primaryKey; index"`
+	User      userV2 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role      string `gorm:"type:varchar(20); not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (farmMemberV12) TableName() string { return "farm_members" }
+
+// Duplicate memberships are dropped before the key is added
+var farmMembersV12Duplicates = map[string]string{
+	DriverPostgres: "DELETE FROM farm_members a USING farm_members b WHERE a.farm_id = b.farm_id AND a.user_id = b.user_id AND a.ctid > b.ctid",
+	DriverSQLite:   "DELETE FROM farm_members WHERE rowid NOT IN (SELECT min(rowid) FROM farm_members GROUP BY farm_id, user_id)",
+}
+
+func addFarmMembersPrimaryKeyUp(tx *gorm.DB) error {
+	err := tx.Exec(farmMembersV12Duplicates[tx.Dialector.Name()]).Error
+	if err != nil {
+		return err
+	}
+
+	if tx.Dialector.Name() == DriverSQLite {
+		// SQLite can't add a primary key to an existing table
+		return rebuildFarmMembersSQLite(tx, &farmMemberV12{})
+	}
+
+	return tx.Exec("ALTER TABLE farm_members ADD PRIMARY KEY (farm_id, user_id)").Error
+}
+
+func addFarmMembersPrimaryKeyDown(tx *gorm.DB) error {
+	if tx.Dialector.Name() == DriverSQLite {
+		return rebuildFarmMembersSQLite(tx, &farmMemberV3{})
+	}
+
+	return tx.Exec("ALTER TABLE farm_members DROP CONSTRAINT farm_members_pkey").Error
+}
+
+// Recreates farm_members from model and copies the memberships over. Index
+// names are global in SQLite, so the old ones go before the table is renamed.
+func rebuildFarmMembersSQLite(tx *gorm.DB, model any) error {
+	statements := []string{
+		"DROP INDEX IF EXISTS idx_farm_members_user_id",
+		"ALTER TABLE farm_members RENAME TO farm_members_old",
+	}
+	for _, statement := range statements {
+		err := tx.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	err := tx.Migrator().CreateTable(model)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec("INSERT INTO farm_members (farm_id, user_id, role, created_at, updated_at) SELECT farm_id, user_id, role, created_at, updated_at FROM farm_members_old").Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("DROP TABLE farm_members_old").Error
+}
+
+// Farms and ponds existing before this migration start at version 1
+type farmV13 struct {
+	Version int64 `gorm:"not null; default:1"`
+}
+
+func (farmV13) TableName() string { return "farms" }
+
+type pondV13 struct {
+	Version int64 `gorm:"not null; default:1"`
+}
+
+func (pondV13) TableName() string { return "ponds" }
+
+func addFarmPondVersionsUp(tx *gorm.DB) error {
+	for _, table := range []any{&farmV13{}, &pondV13{}} {
+		err := tx.Migrator().AddColumn(table, "Version")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addFarmPondVersionsDown(tx *gorm.DB) error {
+	for _, table := range []any{&pondV13{}, &farmV13{}} {
+		err := tx.Migrator().DropColumn(table, "Version")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type userV14 struct {
+	Role string `gorm:"type:varchar(20); not null; default:member"`
+}
+
+func (userV14) TableName() string { return "users" }
+
+// Existing users become members, the first user of each organization, who
+// registered it, becomes its admin. Exactly one per organization: ties on the
+// creation time go to the lowest id and deleted accounts are passed over.
+func addUserRolesUp(tx *gorm.DB) error {
+	err := tx.Migrator().AddColumn(&userV14{}, "Role")
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("UPDATE users SET role = 'admin' WHERE id IN (SELECT (SELECT earliest.id FROM users earliest WHERE earliest.organization_id = organizations.id AND earliest.deleted_at IS NULL ORDER BY earliest.created_at, earliest.id LIMIT 1) FROM organizations)").Error
+}
+
+func addUserRolesDown(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&userV14{}, "Role")
+}
@@ -2,32 +2,25 @@ package database
 
 import (
 	"fmt"
-	"log"
-	"os"
 
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
-
-func ConnectToDB() {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASS"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
+func connectToPostgres(config infrastructure.DatabaseConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+		config.Host,
+		config.User,
+		config.Password,
+		config.Name,
+		config.Port,
+		config.SSLMode,
+		config.TimeZone,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         NewGormLogger(config.SlowQueryThreshold),
+		TranslateError: true,
 	})
-	if err != nil {
-		log.Println("can't connect to database")
-		log.Fatal(err)
-	}
-
-	DB = db
 }
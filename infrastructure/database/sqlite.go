@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"gorm.io/gorm"
+)
+
+// SQLite enforces foreign keys only when asked to, and waits for a lock
+// instead of failing at once
+const sqlitePragmas = "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+// SQLite stores timestamps as text compared as strings, so they are all
+// written in UTC to keep them in order
+func connectToSQLite(config infrastructure.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(config.Path+sqlitePragmas), &gorm.Config{
+		Logger:         NewGormLogger(config.SlowQueryThreshold),
+		TranslateError: true,
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// one writer at a time, and a single in-memory database instead of one
+	// per connection
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+)
+
+// Statuses of a component and of a whole report
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// Checks slower than this count as failed
+const DefaultTimeout = 2 * time.Second
+
+type IHealthChecker interface {
+	Live() Report
+	Ready(ctx context.Context) Report
+}
+
+// Check is one dependency of the app. A failing required check makes the app
+// not ready, a failing optional one only degrades it.
+type Check struct {
+	Name     string
+	Required bool
+	Run      func(ctx context.Context) error
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+type ComponentStatus struct {
+	Status   string `json:"status"`
+	Required bool   `json:"required"`
+	// logged only, the report is public
+	Error string `json:"-"`
+}
+
+type HealthChecker struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+func NewHealthChecker(timeout time.Duration, checks ...Check) IHealthChecker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &HealthChecker{
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Live reports the process is up without touching any dependency, so a
+// database outage never gets the app restarted
+func (healthChecker *HealthChecker) Live() Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready runs every check concurrently, each within the timeout
+func (healthChecker *HealthChecker) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, healthChecker.timeout)
+	defer cancel()
+
+	components := make([]ComponentStatus, len(healthChecker.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range healthChecker.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			components[i] = ComponentStatus{Status: StatusUp, Required: check.Required}
+			err := check.Run(ctx)
+			if err != nil {
+				components[i].Status = StatusDown
+				components[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]ComponentStatus, len(components)),
+	}
+	for i, component := range components {
+		report.Components[healthChecker.checks[i].Name] = component
+
+		if component.Status == StatusUp {
+			continue
+		}
+
+		infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+			"COMPONENT": healthChecker.checks[i].Name,
+			"ERROR":     component.Error,
+		}).Warn("Health check failed")
+
+		if component.Required {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func check(name string, required bool, err error) Check {
+	return Check{Name: name, Required: required, Run: func(ctx context.Context) error {
+		return err
+	}}
+}
+
+func TestReady(t *testing.T) {
+	t.Run("should be up when every check passes", func(t *testing.T) {
+		// prepare checker
+		healthChecker := NewHealthChecker(0, check("database", true, nil), check("recorder", false, nil))
+
+		// run checks
+		report := healthChecker.Ready(context.Background())
+
+		//test report
+		assert.Equal(t, StatusUp, report.Status, "status should be equal")
+		assert.Equal(t, ComponentStatus{Status: StatusUp, Required: true}, report.Components["database"], "database should be equal")
+		assert.Equal(t, ComponentStatus{Status: StatusUp}, report.Components["recorder"], "recorder should be equal")
+	})
+
+	t.Run("should be degraded when an optional check fails", func(t *testing.T) {
+		// prepare checker
+		healthChecker := NewHealthChecker(0, check("database", true, nil), check("recorder", false, errors.New("latest flush failed")))
+
+		// run checks
+		report := healthChecker.Ready(context.Background())
+
+		//test report
+		assert.Equal(t, StatusDegraded, report.Status, "status should be equal")
+		assert.Equal(t, "latest flush failed", report.Components["recorder"].Error, "error should be equal")
+	})
+
+	t.Run("should be down when a required check fails", func(t *testing.T) {
+		// prepare checker
+		healthChecker := NewHealthChecker(0, check("database", true, errors.New("connection refused")), check("recorder", false, errors.New("closed")))
+
+		// run checks
+		report := healthChecker.Ready(context.Background())
+
+		//test report
+		assert.Equal(t, StatusDown, report.Status, "status should be equal")
+		assert.Equal(t, StatusDown, report.Components["database"].Status, "database status should be equal")
+	})
+
+	t.Run("should keep check errors out of the response", func(t *testing.T) {
+		// prepare checker
+		healthChecker := NewHealthChecker(0, check("database", true, errors.New("dial tcp 10.0.0.5:5432: connection refused")))
+
+		// run checks
+		body, err := json.Marshal(healthChecker.Ready(context.Background()))
+
+		//test response
+		assert.Nil(t, err, "error should be nil")
+		assert.JSONEq(t, `{"status":"down","components":{"database":{"status":"down","required":true}}}`, string(body), "body should be equal")
+	})
+
+	t.Run("should fail a check past the timeout", func(t *testing.T) {
+		// prepare checker
+		healthChecker := NewHealthChecker(time.Millisecond, Check{Name: "database", Required: true, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+
+		// run checks
+		report := healthChecker.Ready(context.Background())
+
+		//test report
+		assert.Equal(t, StatusDown, report.Status, "status should be equal")
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["database"].Error, "error should be equal")
+	})
+}
@@ -1,13 +1,50 @@
 package infrastructure
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
 
 var Logger *logrus.Logger
 
-func CreateLogger() {
+// CreateLogger sets up the global logger, falling back to info for an
+// unknown level
+func CreateLogger(config LogConfig) {
+	level, err := logrus.ParseLevel(config.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+
 	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(level)
+	if config.Format == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
 
 	Logger = logger
 }
+
+type loggerKey struct{}
+
+// WithLogger stores the log entry of a request in its context
+func WithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// LoggerFrom returns the log entry of the request, so every line it logs
+// carries its request id. Outside a request it falls back to the global logger.
+func LoggerFrom(ctx context.Context) *logrus.Entry {
+	entry, ok := ctx.Value(loggerKey{}).(*logrus.Entry)
+	if ok {
+		return entry
+	}
+
+	if Logger == nil {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return logrus.NewEntry(Logger)
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const namespace = "aquafarm"
+
+// Route label of requests that matched no route, so unknown paths can't grow
+// the number of series
+const unmatchedRoute = "unmatched"
+
+// Default for an unset or invalid config value
+const DefaultCountTimeout = 2 * time.Second
+
+type Config struct {
+	// longest time counting the domain gauges of one scrape may take
+	CountTimeout time.Duration
+}
+
+type IMetrics interface {
+	ObserveRequest(route string, method string, status int, duration time.Duration)
+	Handler() http.Handler
+}
+
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+func NewMetrics(db *gorm.DB, config Config) (IMetrics, error) {
+	if config.CountTimeout <= 0 {
+		config.CountTimeout = DefaultCountTimeout
+	}
+
+	requestLabels := []string{"route", "method", "status"}
+	metrics := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Handled HTTP requests by route template, method and status.",
+		}, requestLabels),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of handled HTTP requests by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, requestLabels),
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	err = registerAll(metrics.registry,
+		metrics.requests,
+		metrics.requestDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()),
+		newDomainCollector(db, config.CountTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func (metrics *Metrics) ObserveRequest(route string, method string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	labels := prometheus.Labels{
+		"route":  route,
+		"method": method,
+		"status": strconv.Itoa(status),
+	}
+	metrics.requests.With(labels).Inc()
+	metrics.requestDuration.With(labels).Observe(duration.Seconds())
+}
+
+func (metrics *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{})
+}
+
+func registerAll(registry *prometheus.Registry, toRegister ...prometheus.Collector) error {
+	for _, collector := range toRegister {
+		err := registry.Register(collector)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// domainCollector counts farms, ponds and active cycles on every scrape, all
+// within the count timeout. A failing count is logged and left out of the
+// scrape.
+type domainCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+	gauges  []domainGauge
+}
+
+type domainGauge struct {
+	desc  *prometheus.Desc
+	count func(db *gorm.DB, count *int64) error
+}
+
+func newDomainCollector(db *gorm.DB, timeout time.Duration) *domainCollector {
+	return &domainCollector{
+		db:      db,
+		timeout: timeout,
+		gauges: []domainGauge{
+			{
+				desc: prometheus.NewDesc(namespace+"_farms", "Farms not deleted.", nil, nil),
+				count: func(db *gorm.DB, count *int64) error {
+					return db.Model(&domain.Farm{}).Count(count).Error
+				},
+			},
+			{
+				desc: prometheus.NewDesc(namespace+"_ponds", "Ponds not deleted.", nil, nil),
+				count: func(db *gorm.DB, count *int64) error {
+					return db.Model(&domain.Pond{}).Count(count).Error
+				},
+			},
+			{
+				desc: prometheus.NewDesc(namespace+"_active_cycles", "Stocking cycles not harvested yet.", nil, nil),
+				count: func(db *gorm.DB, count *int64) error {
+					return db.Model(&domain.Cycle{}).Where("status = ?", domain.CycleStatusActive).Count(count).Error
+				},
+			},
+		},
+	}
+}
+
+func (domainCollector *domainCollector) Describe(descs chan<- *prometheus.Desc) {
+	for _, gauge := range domainCollector.gauges {
+		descs <- gauge.desc
+	}
+}
+
+func (domainCollector *domainCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainCollector.timeout)
+	defer cancel()
+
+	for _, gauge := range domainCollector.gauges {
+		var count int64
+		err := gauge.count(domainCollector.db.WithContext(ctx), &count)
+		if err != nil {
+			infrastructure.LoggerFrom(ctx).WithFields(logrus.Fields{
+				"METRIC": gauge.desc.String(),
+				"ERROR":  err.Error(),
+			}).Error("Failed to collect metric")
+			continue
+		}
+
+		metrics <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, float64(count))
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dry run database, counts succeed with zero without a server
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func scrape(t *testing.T, appMetrics IMetrics) string {
+	response := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appMetrics.Handler().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, "status code should be equal")
+
+	return response.Body.String()
+}
+
+func TestObserveRequest(t *testing.T) {
+	t.Run("should label requests by route template", func(t *testing.T) {
+		// prepare metrics
+		appMetrics, err := NewMetrics(newDryRunDB(t), Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// observe requests
+		appMetrics.ObserveRequest("/api/farms/:farmId", "GET", http.StatusOK, 20*time.Millisecond)
+		appMetrics.ObserveRequest("/api/farms/:farmId", "GET", http.StatusOK, 30*time.Millisecond)
+		appMetrics.ObserveRequest("", "GET", http.StatusNotFound, time.Millisecond)
+
+		//test scrape
+		body := scrape(t, appMetrics)
+		assert.Contains(t, body, `aquafarm_http_requests_total{method="GET",route="/api/farms/:farmId",status="200"} 2`, "route counter should be equal")
+		assert.Contains(t, body, `aquafarm_http_requests_total{method="GET",route="unmatched",status="404"} 1`, "unmatched counter should be equal")
+		assert.Contains(t, body, `aquafarm_http_request_duration_seconds_count{method="GET",route="/api/farms/:farmId",status="200"} 2`, "histogram count should be equal")
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("should expose pool and domain metrics", func(t *testing.T) {
+		// prepare metrics
+		appMetrics, err := NewMetrics(newDryRunDB(t), Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//test scrape
+		body := scrape(t, appMetrics)
+		assert.Contains(t, body, `go_sql_open_connections{db_name="postgres"}`, "pool stats should be exposed")
+		assert.Contains(t, body, "aquafarm_farms 0", "farms should be exposed")
+		assert.Contains(t, body, "aquafarm_ponds 0", "ponds should be exposed")
+		assert.Contains(t, body, "aquafarm_active_cycles 0", "active cycles should be exposed")
+	})
+
+	t.Run("should count domain gauges within the count timeout", func(t *testing.T) {
+		// prepare metrics, recording the deadline of every count
+		db := newDryRunDB(t)
+		var deadlines []time.Time
+		err := db.Callback().Query().Before("gorm:query").Register("test:deadline", func(tx *gorm.DB) {
+			deadline, ok := tx.Statement.Context.Deadline()
+			assert.True(t, ok, "count should have a deadline")
+			deadlines = append(deadlines, deadline)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		appMetrics, err := NewMetrics(db, Config{CountTimeout: time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// scrape
+		scrapedAt := time.Now()
+		scrape(t, appMetrics)
+
+		//test deadlines
+		if assert.Len(t, deadlines, 3, "every gauge should be counted") {
+			assert.WithinDuration(t, scrapedAt.Add(time.Minute), deadlines[0], 5*time.Second, "deadline should be the count timeout")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/auth"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+const authUserKey = "auth_user"
+
+// Authenticate rejects requests without a valid "Authorization: Bearer" access
+// token and stores the authenticated user in the gin context
+func Authenticate(tokenManager auth.ITokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.Error(apperror.Unauthorized(apperror.CodeUnauthorized, "failed to authenticate", errors.New("missing bearer token")))
+			c.Abort()
+			return
+		}
+
+		authUser, err := tokenManager.ParseAccessToken(token)
+		if err != nil {
+			c.Error(apperror.Unauthorized(apperror.CodeUnauthorized, "failed to authenticate", errors.New("invalid or expired access token")))
+			c.Abort()
+			return
+		}
+
+		SetAuthUser(c, authUser)
+		c.Next()
+	}
+}
+
+// RequireOperator lets through only authenticated users whose email is one of
+// operators, for data spanning every organization
+func RequireOperator(operators []string) gin.HandlerFunc {
+	emails := make(map[string]bool, len(operators))
+	for _, operator := range operators {
+		email := strings.ToLower(strings.TrimSpace(operator))
+		if email != "" {
+			emails[email] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		if !emails[strings.ToLower(GetAuthUser(c).Email)] {
+			c.Error(apperror.Forbidden(apperror.CodeForbidden, "failed to authorize", errors.New("user is not an operator")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAuthUser returns the user stored by Authenticate
+func GetAuthUser(c *gin.Context) domain.AuthUser {
+	authUser, _ := c.Get(authUserKey)
+	user, _ := authUser.(domain.AuthUser)
+	return user
+}
+
+// SetAuthUser stores the authenticated user, used by Authenticate and tests
+func SetAuthUser(c *gin.Context, user domain.AuthUser) {
+	c.Set(authUserKey, user)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/util"
+	"github.com/reyhanmichiels/AquaFarmManagement/util/apperror"
+)
+
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindValidation:   http.StatusBadRequest,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindPrecondition: http.StatusPreconditionFailed,
+	apperror.KindInternal:     http.StatusInternalServerError,
+	apperror.KindUnavailable:  http.StatusServiceUnavailable,
+	apperror.KindTimeout:      http.StatusGatewayTimeout,
+}
+
+// HandleError turns the last error attached by a handler with c.Error into
+// the fail response envelope, as a timeout once the request deadline passed
+func HandleError(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	appErr := apperror.FromContext(c.Request.Context(), apperror.From(c.Errors.Last().Err))
+
+	cause := appErr.Err
+	if cause == nil {
+		cause = errors.New(appErr.Message)
+	}
+
+	util.FailResponse(c, StatusCode(appErr.Kind), appErr.Code, appErr.Message, cause)
+}
+
+func StatusCode(kind apperror.Kind) int {
+	status, ok := statusByKind[kind]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	return status
+}
@@ -1,21 +1,43 @@
 package middleware
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
 	"github.com/sirupsen/logrus"
 )
 
+// LogEvent logs every request when it comes in and once it is handled, with
+// the request log entry so both lines share the request id
 func LogEvent(c *gin.Context) {
-	ip := c.ClientIP()
-	endpoint := c.Request.URL.Path
-	method := c.Request.Method
+	start := time.Now()
+	logger := infrastructure.LoggerFrom(c.Request.Context()).WithFields(logrus.Fields{
+		"METHOD":   c.Request.Method,
+		"ENDPOINT": c.Request.URL.Path,
+		"IP":       c.ClientIP(),
+	})
 
-	infrastructure.Logger.WithFields(logrus.Fields{
-		"METHOD":   method,
-		"ENDPOINT": endpoint,
-		"IP":       ip,
-	}).Info("Incoming HTTP Request")
+	logger.Info("Incoming HTTP Request")
 
 	c.Next()
+
+	fields := logrus.Fields{
+		"STATUS":     c.Writer.Status(),
+		"LATENCY_MS": float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if len(c.Errors) > 0 {
+		fields["ERROR"] = c.Errors.Last().Error()
+	}
+
+	completed := logger.WithFields(fields)
+	switch {
+	case c.Writer.Status() >= http.StatusInternalServerError:
+		completed.Error("Completed HTTP Request")
+	case c.Writer.Status() >= http.StatusBadRequest:
+		completed.Warn("Completed HTTP Request")
+	default:
+		completed.Info("Completed HTTP Request")
+	}
 }
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/metrics"
+)
+
+// ObserveRequest counts every handled request and its latency under the
+// matched route template
+func ObserveRequest(appMetrics metrics.IMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		appMetrics.ObserveRequest(c.FullPath(), c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
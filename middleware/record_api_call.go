@@ -1,18 +1,57 @@
 package middleware
 
 import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/recorder"
 	"github.com/reyhanmichiels/AquaFarmManagement/domain"
-	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/database"
 )
 
-func RecordApiCallMiddleware(c *gin.Context) {
-	apiCall := domain.ApiCall{
-		IpAdress: c.ClientIP(),
-		Endpoint: c.Request.URL.Path,
-		Method:   c.Request.Method,
+const (
+	maxEndpointLength  = 100
+	maxUserAgentLength = 255
+)
+
+// RecordApiCall hands every request to the recorder once the handler is done,
+// without waiting for the database. A full recorder drops the record instead
+// of slowing the request.
+func RecordApiCall(apiCallRecorder recorder.IApiCallRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		apiCall := domain.ApiCall{
+			IpAdress:     c.ClientIP(),
+			Endpoint:     truncate(c.Request.URL.Path, maxEndpointLength),
+			Route:        c.FullPath(),
+			Method:       c.Request.Method,
+			StatusCode:   c.Writer.Status(),
+			Latency:      float64(time.Since(start).Microseconds()) / 1000,
+			ResponseSize: max(c.Writer.Size(), 0),
+			UserAgent:    truncate(c.Request.UserAgent(), maxUserAgentLength),
+			CreatedAt:    start,
+		}
+
+		authUser := GetAuthUser(c)
+		if authUser.ID != "" {
+			apiCall.UserID = &authUser.ID
+		}
+
+		apiCallRecorder.Record(apiCall)
+	}
+}
+
+// truncate keeps the first maxLength characters of value, with invalid UTF-8
+// replaced, as the database rejects invalid text and counts characters
+func truncate(value string, maxLength int) string {
+	value = strings.ToValidUTF8(value, string(utf8.RuneError))
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
 	}
 
-	database.DB.Create(&apiCall)
-	c.Next()
+	return string([]rune(value)[:maxLength])
 }
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "requestId"
+)
+
+// incoming ids are echoed in headers and logs, so only plain tokens are kept
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID keeps the X-Request-ID of the caller or generates one, echoes it
+// in the response and stores a log entry carrying it in the request context
+func RequestID(c *gin.Context) {
+	requestId := c.GetHeader(RequestIDHeader)
+	if !validRequestID.MatchString(requestId) {
+		requestId = uuid.NewString()
+	}
+
+	c.Set(requestIDKey, requestId)
+	c.Header(RequestIDHeader, requestId)
+
+	entry := infrastructure.LoggerFrom(c.Request.Context()).WithField("REQUEST_ID", requestId)
+	c.Request = c.Request.WithContext(infrastructure.WithLogger(c.Request.Context(), entry))
+
+	c.Next()
+}
+
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
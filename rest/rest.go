@@ -1,59 +1,275 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	api_call_handler "github.com/reyhanmichiels/AquaFarmManagement/app/api_call/handler"
+	"github.com/reyhanmichiels/AquaFarmManagement/app/api_call/recorder"
+	cycle_handler "github.com/reyhanmichiels/AquaFarmManagement/app/cycle/handler"
 	farm_handler "github.com/reyhanmichiels/AquaFarmManagement/app/farm/handler"
+	feeding_handler "github.com/reyhanmichiels/AquaFarmManagement/app/feeding/handler"
 	pond_handler "github.com/reyhanmichiels/AquaFarmManagement/app/pond/handler"
+	trash_handler "github.com/reyhanmichiels/AquaFarmManagement/app/trash/handler"
+	user_handler "github.com/reyhanmichiels/AquaFarmManagement/app/user/handler"
+	water_quality_handler "github.com/reyhanmichiels/AquaFarmManagement/app/water_quality/handler"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/auth"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/health"
+	"github.com/reyhanmichiels/AquaFarmManagement/infrastructure/metrics"
 	"github.com/reyhanmichiels/AquaFarmManagement/middleware"
 )
 
+// Defaults for unset or invalid config values
+const (
+	DefaultAddr              = ":8080"
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultMaxHeaderBytes    = 1 << 20
+)
+
+type Config struct {
+	// address the server listens on, such as ":8080"
+	Addr string
+	// longest time to read a whole request, body included
+	ReadTimeout time.Duration
+	// longest time to read the request headers
+	ReadHeaderTimeout time.Duration
+	// longest time from the end of the request headers to the end of the response
+	WriteTimeout time.Duration
+	// how long a keep-alive connection waits for the next request
+	IdleTimeout time.Duration
+	// largest request header accepted, in bytes
+	MaxHeaderBytes int
+	// deadline of the request context, zero leaves requests without one
+	RequestTimeout time.Duration
+	// emails of the users allowed to read data of every organization
+	Operators []string
+	// address /metrics is served on apart from the api, empty doesn't serve it
+	MetricsAddr string
+}
+
 type Rest struct {
-	engine *gin.Engine
+	engine          *gin.Engine
+	server          *http.Server
+	metricsServer   *http.Server
+	authenticate    gin.HandlerFunc
+	requireOperator gin.HandlerFunc
+	recordApiCall   gin.HandlerFunc
+	observeRequest  gin.HandlerFunc
+	requestTimeout  gin.HandlerFunc
+	metrics         metrics.IMetrics
 }
 
-func NewRest(engine *gin.Engine) Rest {
+func NewRest(engine *gin.Engine, tokenManager auth.ITokenManager, apiCallRecorder recorder.IApiCallRecorder, appMetrics metrics.IMetrics, config Config) Rest {
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = DefaultReadTimeout
+	}
+	if config.ReadHeaderTimeout <= 0 {
+		config.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = DefaultWriteTimeout
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.MaxHeaderBytes <= 0 {
+		config.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+
+	var metricsServer *http.Server
+	if config.MetricsAddr != "" {
+		metricsServer = &http.Server{
+			Addr:              config.MetricsAddr,
+			ReadTimeout:       config.ReadTimeout,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+			MaxHeaderBytes:    config.MaxHeaderBytes,
+		}
+	}
+
 	return Rest{
 		engine: engine,
+		server: &http.Server{
+			Addr:              config.Addr,
+			Handler:           engine,
+			ReadTimeout:       config.ReadTimeout,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+			MaxHeaderBytes:    config.MaxHeaderBytes,
+		},
+		metricsServer:   metricsServer,
+		authenticate:    middleware.Authenticate(tokenManager),
+		requireOperator: middleware.RequireOperator(config.Operators),
+		recordApiCall:   middleware.RecordApiCall(apiCallRecorder),
+		observeRequest:  middleware.ObserveRequest(appMetrics),
+		requestTimeout:  middleware.RequestTimeout(config.RequestTimeout),
+		metrics:         appMetrics,
 	}
 }
 
-func (rest *Rest) HealthCheckRoute() {
-	rest.engine.GET("/api/health-check", func(ctx *gin.Context) {
-		ctx.JSON(http.StatusOK, gin.H{
-			"status": "successfully run health check",
-		})
+func (rest *Rest) HealthRoute(healthChecker health.IHealthChecker) {
+	live := func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, healthChecker.Live())
+	}
+
+	rest.engine.GET("/api/health/live", live)
+	rest.engine.GET("/api/health-check", live)
+	rest.engine.GET("/api/health/ready", func(ctx *gin.Context) {
+		report := healthChecker.Ready(ctx.Request.Context())
+
+		status := http.StatusOK
+		if report.Status == health.StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		ctx.JSON(status, report)
 	})
 }
 
+// MetricsRoute serves /metrics on the metrics address only, so the api
+// address never exposes it
+func (rest *Rest) MetricsRoute() {
+	if rest.metricsServer == nil {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", rest.metrics.Handler())
+	rest.metricsServer.Handler = mux
+}
+
+func (rest *Rest) AuthRoute(userHandler *user_handler.UserHandler) {
+	rest.engine.POST("/api/auth/register", userHandler.Register)
+	rest.engine.POST("/api/auth/login", userHandler.Login)
+	rest.engine.POST("/api/auth/refresh", userHandler.Refresh)
+	rest.engine.POST("/api/auth/logout", userHandler.Logout)
+}
+
+func (rest *Rest) OrganizationRoute(userHandler *user_handler.UserHandler) {
+	organization := rest.engine.Group("/api/organization", rest.authenticate)
+	organization.POST("/users", userHandler.CreateOrganizationUser)
+}
+
 func (rest *Rest) FarmRoute(farmHandler *farm_handler.FarmHandler) {
-	rest.engine.GET("/api/farms", farmHandler.Get)
-	rest.engine.POST("/api/farms", farmHandler.Create)
-	rest.engine.GET("/api/farms/:farmId", farmHandler.GetFarmById)
-	rest.engine.PUT("/api/farms/:farmId", farmHandler.Update)
-	rest.engine.DELETE("/api/farms/:farmId", farmHandler.Delete)
+	farm := rest.engine.Group("/api/farms", rest.authenticate)
+	farm.GET("", farmHandler.Get)
+	farm.POST("", farmHandler.Create)
+	farm.GET("/:farmId", farmHandler.GetFarmById)
+	farm.PUT("/:farmId", farmHandler.Update)
+	farm.DELETE("/:farmId", farmHandler.Delete)
+	farm.GET("/:farmId/members", farmHandler.GetMembers)
+	farm.POST("/:farmId/members", farmHandler.AddMember)
+	farm.PUT("/:farmId/members/:userId", farmHandler.UpdateMember)
+	farm.DELETE("/:farmId/members/:userId", farmHandler.RemoveMember)
 }
 
 func (rest *Rest) PondRoute(pondHanler *pond_handler.PondHandler) {
-	rest.engine.GET("/api/ponds", pondHanler.Get)
-	rest.engine.POST("/api/ponds", pondHanler.Create)
-	rest.engine.GET("/api/ponds/:pondId", pondHanler.GetPondById)
-	rest.engine.PUT("/api/ponds/:pondId", pondHanler.Update)
-	rest.engine.DELETE("/api/ponds/:pondId", pondHanler.Delete)
+	pond := rest.engine.Group("/api/ponds", rest.authenticate)
+	pond.GET("", pondHanler.Get)
+	pond.POST("", pondHanler.Create)
+	pond.GET("/:pondId", pondHanler.GetPondById)
+	pond.PUT("/:pondId", pondHanler.Update)
+	pond.DELETE("/:pondId", pondHanler.Delete)
 }
 
-func (rest *Rest) ApiCallRoute(apiCallHandler *api_call_handler.ApiCallHandler) {
-	rest.engine.GET("/api/api-calls", apiCallHandler.Get)
+func (rest *Rest) TrashRoute(trashHandler *trash_handler.TrashHandler) {
+	trash := rest.engine.Group("/api/trash", rest.authenticate)
+	trash.GET("/farms", trashHandler.GetFarms)
+	trash.POST("/farms/:farmId/restore", trashHandler.RestoreFarm)
+	trash.DELETE("/farms/:farmId", trashHandler.PurgeFarm)
+	trash.GET("/ponds", trashHandler.GetPonds)
+	trash.POST("/ponds/:pondId/restore", trashHandler.RestorePond)
+	trash.DELETE("/ponds/:pondId", trashHandler.PurgePond)
+}
+
+func (rest *Rest) CycleRoute(cycleHandler *cycle_handler.CycleHandler) {
+	cycle := rest.engine.Group("/api/ponds/:pondId/cycles", rest.authenticate)
+	cycle.GET("", cycleHandler.GetByPond)
+	cycle.POST("", cycleHandler.Start)
+	cycle.POST("/:cycleId/close", cycleHandler.Close)
+}
 
+func (rest *Rest) FeedingRoute(feedingHandler *feeding_handler.FeedingHandler) {
+	feeding := rest.engine.Group("/api/ponds/:pondId/feedings", rest.authenticate)
+	feeding.GET("", feedingHandler.GetByPond)
+	feeding.POST("", feedingHandler.Create)
+	feeding.GET("/summary", feedingHandler.GetSummary)
+}
+
+func (rest *Rest) WaterQualityRoute(waterQualityHandler *water_quality_handler.WaterQualityHandler) {
+	pond := rest.engine.Group("/api/ponds/:pondId", rest.authenticate)
+	pond.GET("/readings", waterQualityHandler.GetReadings)
+	pond.POST("/readings", waterQualityHandler.CreateReading)
+	pond.GET("/thresholds", waterQualityHandler.GetPondThresholds)
+	pond.PUT("/thresholds/:parameter", waterQualityHandler.SetPondThreshold)
+	pond.DELETE("/thresholds/:parameter", waterQualityHandler.DeletePondThreshold)
+
+	farm := rest.engine.Group("/api/farms/:farmId/thresholds", rest.authenticate)
+	farm.GET("", waterQualityHandler.GetFarmThresholds)
+	farm.PUT("/:parameter", waterQualityHandler.SetFarmThreshold)
+	farm.DELETE("/:parameter", waterQualityHandler.DeleteFarmThreshold)
+
+	rest.engine.GET("/api/alerts", rest.authenticate, waterQualityHandler.GetAlerts)
+}
+
+func (rest *Rest) ApiCallRoute(apiCallHandler *api_call_handler.ApiCallHandler) {
+	apiCall := rest.engine.Group("/api/api-calls", rest.authenticate, rest.requireOperator)
+	apiCall.GET("", apiCallHandler.Get)
+	apiCall.GET("/recorder", apiCallHandler.GetRecorderStats)
 }
 
 func (rest *Rest) UseGlobalMiddleware() {
+	rest.engine.Use(middleware.RequestID)
 	rest.engine.Use(middleware.LogEvent)
-	rest.engine.Use(middleware.RecordApiCallMiddleware)
+	rest.engine.Use(rest.observeRequest)
+	rest.engine.Use(rest.recordApiCall)
+	rest.engine.Use(rest.requestTimeout)
+	rest.engine.Use(middleware.HandleError)
+}
+
+// Serve listens on the api and metrics addresses until one of the servers
+// fails or they are shut down, the latter returning nil
+func (rest *Rest) Serve() error {
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- listenAndServe(rest.server)
+	}()
+	if rest.metricsServer != nil {
+		go func() {
+			serveErr <- listenAndServe(rest.metricsServer)
+		}()
+	}
+
+	return <-serveErr
 }
 
-func (rest *Rest) Serve() {
-	rest.engine.Run()
+func listenAndServe(server *http.Server) error {
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish or until ctx is done
+func (rest *Rest) Shutdown(ctx context.Context) error {
+	err := rest.server.Shutdown(ctx)
+	if rest.metricsServer != nil {
+		err = errors.Join(err, rest.metricsServer.Shutdown(ctx))
+	}
+
+	return err
 }
@@ -1,6 +1,9 @@
 package util
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+)
 
 func SuccessResponse(c *gin.Context, code int, message string, data interface{}) {
 
@@ -12,18 +15,24 @@ func SuccessResponse(c *gin.Context, code int, message string, data interface{})
 
 }
 
-func FailResponse(c *gin.Context, code int, message string, err error) {
+func SuccessPaginatedResponse(c *gin.Context, code int, message string, data interface{}, pagination domain.Pagination) {
 
 	c.JSON(code, gin.H{
-		"status":  "error",
+		"status":  "success",
 		"message": message,
-		"error":   err.Error(),
+		"data":    data,
+		"meta":    pagination,
 	})
 
 }
 
-type ErrorObject struct {
-	Code    int
-	Message string
-	Err     error
+func FailResponse(c *gin.Context, code int, errorCode string, message string, err error) {
+
+	c.JSON(code, gin.H{
+		"status":  "error",
+		"code":    errorCode,
+		"message": message,
+		"error":   err.Error(),
+	})
+
 }
@@ -0,0 +1,144 @@
+package apperror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Kind classifies an error independently of the transport. The HTTP layer maps
+// each kind to a status code.
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindPrecondition Kind = "precondition"
+	KindInternal     Kind = "internal"
+	KindUnavailable  Kind = "unavailable"
+	KindTimeout      Kind = "timeout"
+)
+
+// Sentinels matching every *Error of the same kind with errors.Is
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrPrecondition = errors.New("precondition failed")
+	ErrInternal     = errors.New("internal error")
+	ErrUnavailable  = errors.New("service unavailable")
+	ErrTimeout      = errors.New("timeout")
+)
+
+var sentinels = map[Kind]error{
+	KindValidation:   ErrValidation,
+	KindUnauthorized: ErrUnauthorized,
+	KindForbidden:    ErrForbidden,
+	KindNotFound:     ErrNotFound,
+	KindConflict:     ErrConflict,
+	KindPrecondition: ErrPrecondition,
+	KindInternal:     ErrInternal,
+	KindUnavailable:  ErrUnavailable,
+	KindTimeout:      ErrTimeout,
+}
+
+type Error struct {
+	Kind Kind
+	// Code is a stable machine-readable identifier, see codes.go
+	Code string
+	// Message describes the failed operation, e.g. "failed to create farm"
+	Message string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %s", e.Message, e.Err.Error())
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return sentinels[e.Kind] == target
+}
+
+func Validation(code string, message string, err error) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Err: err}
+}
+
+func Unauthorized(code string, message string, err error) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message, Err: err}
+}
+
+func Forbidden(code string, message string, err error) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message, Err: err}
+}
+
+func NotFound(code string, message string, err error) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message, Err: err}
+}
+
+func Conflict(code string, message string, err error) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message, Err: err}
+}
+
+func PreconditionFailed(code string, message string, err error) *Error {
+	return &Error{Kind: KindPrecondition, Code: code, Message: message, Err: err}
+}
+
+func Internal(message string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: message, Err: err}
+}
+
+func Unavailable(message string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: CodeUnavailable, Message: message, Err: err}
+}
+
+func Timeout(message string, err error) *Error {
+	return &Error{Kind: KindTimeout, Code: CodeTimeout, Message: message, Err: err}
+}
+
+// From returns err as *Error, treating anything untyped as internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return Internal("internal server error", err)
+}
+
+// FromContext reclassifies an error of a request whose context ended or whose
+// database could not be reached. Usecases report a failed lookup as internal,
+// only a missing record is not found, and a passed deadline wins over any kind
+// since whatever failed was cut short by it.
+func FromContext(ctx context.Context, err *Error) *Error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err.Err, context.DeadlineExceeded):
+		return Timeout(err.Message, context.DeadlineExceeded)
+	case errors.Is(ctx.Err(), context.Canceled) || errors.Is(err.Err, context.Canceled):
+		return Unavailable(err.Message, context.Canceled)
+	case err.Kind == KindInternal && isConnectionError(err.Err):
+		return Unavailable(err.Message, err.Err)
+	}
+
+	return err
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
@@ -0,0 +1,47 @@
+package apperror
+
+// Machine-readable error codes returned in the "code" field of error
+// responses. Clients depend on them: never rename, only add.
+const (
+	CodeInternal       = "internal_error"
+	CodeInvalidRequest = "invalid_request"
+	CodeInvalidQuery   = "invalid_query"
+	CodeUnavailable    = "service_unavailable"
+	CodeTimeout        = "timeout"
+
+	CodeUnauthorized        = "unauthorized"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeInvalidRefreshToken = "invalid_refresh_token"
+	CodeEmailTaken          = "email_taken"
+	CodeForbidden           = "forbidden"
+	CodeVersionMismatch     = "version_mismatch"
+
+	CodeFarmNotFound  = "farm_not_found"
+	CodeFarmNameTaken = "farm_name_taken"
+
+	CodeUserNotFound   = "user_not_found"
+	CodeMemberNotFound = "member_not_found"
+	CodeMemberExists   = "member_exists"
+	CodeLastManager    = "last_manager"
+
+	CodePondNotFound  = "pond_not_found"
+	CodePondNameTaken = "pond_name_taken"
+
+	CodeCycleNotFound      = "cycle_not_found"
+	CodeActiveCycleExists  = "active_cycle_exists"
+	CodeCycleClosed        = "cycle_closed"
+	CodeInvalidHarvestDate = "invalid_harvest_date"
+	CodeNoActiveCycle      = "no_active_cycle"
+
+	CodeFeedingNotFound    = "feeding_not_found"
+	CodeInvalidFeedingDate = "invalid_feeding_date"
+
+	CodeReadingNotFound   = "reading_not_found"
+	CodeEmptyReading      = "empty_reading"
+	CodeInvalidParameter  = "invalid_parameter"
+	CodeThresholdNotFound = "threshold_not_found"
+	CodeInvalidThreshold  = "invalid_threshold"
+	CodeAlertNotFound     = "alert_not_found"
+
+	CodeApiCallNotFound = "api_call_not_found"
+)
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IsSQLite reports whether db runs on SQLite rather than PostgreSQL
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+// sqlite strftime formats truncating to each unit, written like the driver
+// writes UTC timestamps so they compare and scan as stored ones
+var sqliteTruncateFormats = map[string]string{
+	"minute": "%Y-%m-%d %H:%M:00+00:00",
+	"hour":   "%Y-%m-%d %H:00:00+00:00",
+	"day":    "%Y-%m-%d 00:00:00+00:00",
+}
+
+// TruncateTime returns an expression truncating the timestamp column to the
+// start of its minute, hour or day. unit must be one of those, it is not escaped.
+func TruncateTime(db *gorm.DB, unit string, column string) string {
+	if IsSQLite(db) {
+		return fmt.Sprintf("strftime('%s', %s)", sqliteTruncateFormats[unit], column)
+	}
+
+	return fmt.Sprintf("date_trunc('%s', %s)", unit, column)
+}
+
+// ScannedTime scans a timestamp computed in a query. PostgreSQL returns those
+// as time.Time while SQLite returns the text of the timestamp.
+type ScannedTime struct {
+	time.Time
+}
+
+func (scannedTime *ScannedTime) Scan(value any) error {
+	switch value := value.(type) {
+	case time.Time:
+		scannedTime.Time = value
+		return nil
+	case string:
+		return scannedTime.parse(value)
+	case []byte:
+		return scannedTime.parse(string(value))
+	case nil:
+		scannedTime.Time = time.Time{}
+		return nil
+	}
+
+	return fmt.Errorf("can't scan %T into a time", value)
+}
+
+func (scannedTime ScannedTime) Value() (driver.Value, error) {
+	return scannedTime.Time, nil
+}
+
+func (scannedTime *ScannedTime) parse(value string) error {
+	parsed, err := time.Parse("2006-01-02 15:04:05.999999999-07:00", value)
+	if err != nil {
+		return err
+	}
+
+	scannedTime.Time = parsed
+	return nil
+}
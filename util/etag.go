@@ -0,0 +1,37 @@
+package util
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchETag reports whether an If-Match header lists etag or is "*", weak
+// tags never match
+func MatchETag(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotModified sets the ETag header and answers 304 when the If-None-Match
+// header lists etag, weak or not, or is "*"
+func NotModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
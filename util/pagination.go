@@ -0,0 +1,211 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reyhanmichiels/AquaFarmManagement/domain"
+	"gorm.io/gorm"
+)
+
+// Cursorable is implemented by models that can be paged with a keyset cursor
+type Cursorable interface {
+	CursorFor(field string) domain.Cursor
+}
+
+// ParseSort parses "field" (ascending) or "-field" (descending). An empty
+// value sorts by created_at ascending.
+func ParseSort(raw string, allowed []string) (domain.Sort, error) {
+	if raw == "" {
+		return domain.Sort{Field: "created_at"}, nil
+	}
+
+	sort := domain.Sort{Field: strings.TrimPrefix(raw, "-"), Desc: strings.HasPrefix(raw, "-")}
+	for _, field := range allowed {
+		if field == sort.Field {
+			return sort, nil
+		}
+	}
+
+	return domain.Sort{}, fmt.Errorf("sort must be one of %s, optionally prefixed with -", strings.Join(allowed, ", "))
+}
+
+func EncodeCursor(cursor domain.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(raw string) (domain.Cursor, error) {
+	var cursor domain.Cursor
+
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+
+	err = json.Unmarshal(decoded, &cursor)
+	if err != nil || cursor.ID == "" {
+		return cursor, errors.New("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// ValidatePageQuery reports a malformed sort or cursor before hitting the database
+func ValidatePageQuery(query domain.PageQuery, allowed []string) error {
+	sort, err := ParseSort(query.Sort, allowed)
+	if err != nil {
+		return err
+	}
+
+	if query.Cursor == "" {
+		return nil
+	}
+
+	cursor, err := DecodeCursor(query.Cursor)
+	if err != nil {
+		return err
+	}
+
+	_, err = cursorValue(sort.Field, cursor.Value)
+	return err
+}
+
+// FilterList applies the shared name prefix and date range filters on table
+func FilterList(db *gorm.DB, table string, filter domain.ListFilter) *gorm.DB {
+	if filter.Name != "" {
+		db = db.Where(fmt.Sprintf("LOWER(%s.name) LIKE LOWER(?) ESCAPE '\\'", table), escapeLike(filter.Name)+"%")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		db = db.Where(fmt.Sprintf("%s.created_at >= ?", table), filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		db = db.Where(fmt.Sprintf("%s.created_at <= ?", table), filter.CreatedTo)
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		db = db.Where(fmt.Sprintf("%s.updated_at >= ?", table), filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		db = db.Where(fmt.Sprintf("%s.updated_at <= ?", table), filter.UpdatedTo)
+	}
+
+	return db
+}
+
+// Paginate counts the rows matched by db, then loads one page of them into
+// dest ordered by the requested sort with id as tie breaker. A cursor switches
+// from offset to keyset pagination.
+func Paginate[T Cursorable](db *gorm.DB, table string, query domain.PageQuery, allowed []string, dest *[]T) (domain.Pagination, error) {
+	sort, err := ParseSort(query.Sort, allowed)
+	if err != nil {
+		return domain.Pagination{}, err
+	}
+
+	pagination := domain.Pagination{
+		PageSize: query.PageSize,
+	}
+	if pagination.PageSize == 0 {
+		pagination.PageSize = domain.DefaultPageSize
+	}
+	pagination.PageSize = min(pagination.PageSize, domain.MaxPageSize)
+
+	db = db.Session(&gorm.Session{})
+	err = db.Count(&pagination.Total).Error
+	if err != nil {
+		return domain.Pagination{}, err
+	}
+
+	direction, comparison := "asc", ">"
+	if sort.Desc {
+		direction, comparison = "desc", "<"
+	}
+	column := fmt.Sprintf("%s.%s", table, sort.Field)
+	id := fmt.Sprintf("%s.id", table)
+	db = db.Order(fmt.Sprintf("%s %s, %s %s", column, direction, id, direction))
+
+	if query.Cursor != "" {
+		cursor, err := DecodeCursor(query.Cursor)
+		if err != nil {
+			return domain.Pagination{}, err
+		}
+
+		value, err := cursorValue(sort.Field, cursor.Value)
+		if err != nil {
+			return domain.Pagination{}, err
+		}
+
+		condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", column, comparison, column, id, comparison)
+		db = db.Where(condition, value, value, cursor.ID)
+	} else {
+		pagination.Page = max(query.Page, 1)
+		pagination.TotalPages = int((pagination.Total + int64(pagination.PageSize) - 1) / int64(pagination.PageSize))
+		db = db.Offset((pagination.Page - 1) * pagination.PageSize)
+	}
+
+	// fetch one extra row to know whether another page follows
+	err = db.Limit(pagination.PageSize + 1).Find(dest).Error
+	if err != nil {
+		return domain.Pagination{}, err
+	}
+
+	if len(*dest) > pagination.PageSize {
+		*dest = (*dest)[:pagination.PageSize]
+		last := (*dest)[len(*dest)-1]
+		pagination.NextCursor = EncodeCursor(last.CursorFor(sort.Field))
+	}
+
+	return pagination, nil
+}
+
+// SetPaginationLinks fills next and prev with the current request URL pointed
+// at the adjacent pages
+func SetPaginationLinks(c *gin.Context, pagination *domain.Pagination) {
+	link := func(key string, value string) string {
+		values := c.Request.URL.Query()
+		values.Del("page")
+		values.Del("cursor")
+		values.Set(key, value)
+
+		link := url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}
+		return link.String()
+	}
+
+	if pagination.Page == 0 {
+		if pagination.NextCursor != "" {
+			pagination.Next = link("cursor", pagination.NextCursor)
+		}
+		return
+	}
+
+	if pagination.Page < pagination.TotalPages {
+		pagination.Next = link("page", strconv.Itoa(pagination.Page+1))
+	}
+	if pagination.Page > 1 {
+		pagination.Prev = link("page", strconv.Itoa(pagination.Page-1))
+	}
+}
+
+func cursorValue(field string, value string) (any, error) {
+	if field == "name" {
+		return value, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return parsed, nil
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,41 @@
+package util
+
+import "math"
+
+// square meters per unit of area accepted by the api
+var areaUnits = map[string]float64{
+	"m2":   1,
+	"ha":   10000,
+	"ft2":  0.09290304,
+	"acre": 4046.8564224,
+}
+
+// meters per unit of length accepted by the api
+var lengthUnits = map[string]float64{
+	"m":  1,
+	"cm": 0.01,
+	"ft": 0.3048,
+}
+
+// ToSquareMeters converts an area, an empty unit means square meters
+func ToSquareMeters(value float64, unit string) float64 {
+	if unit == "" {
+		return value
+	}
+
+	return round(value * areaUnits[unit])
+}
+
+// ToMeters converts a length, an empty unit means meters
+func ToMeters(value float64, unit string) float64 {
+	if unit == "" {
+		return value
+	}
+
+	return round(value * lengthUnits[unit])
+}
+
+// keep four decimals, enough for pond sizes and free of float noise
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}